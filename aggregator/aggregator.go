@@ -16,14 +16,23 @@ package aggregator
 
 import (
 	"context"
+	"reflect"
 	"time"
 
 	"github.com/chenmingyong0423/go-mongox/v2/operation"
 
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
 	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/collation"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
 	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/driveropt"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 )
 
 //go:generate mockgen -source=aggregator.go -destination=../mock/aggregator.mock.go -package=mocks
@@ -37,9 +46,16 @@ var _ IAggregator[any] = (*Aggregator[any])(nil)
 type Aggregator[T any] struct {
 	collection *mongo.Collection
 	pipeline   any
+	collation  *collation.Collation
+	driverOpts driveropt.Options
+
+	scopes        []query.Scope
+	defaultScopes []query.Scope
+	unscoped      bool
 
 	dbCallbacks *callback.Callback
 	fields      []*field.Filed
+	cipher      *encryption.Cipher
 
 	modelHook   any
 	beforeHooks []beforeHookFn
@@ -54,6 +70,12 @@ func NewAggregator[T any](collection *mongo.Collection, dbCallbacks *callback.Ca
 	}
 }
 
+// Cipher is used to decrypt the fields tagged with `mongox:"encrypt"` in the aggregation results
+func (a *Aggregator[T]) Cipher(cipher *encryption.Cipher) *Aggregator[T] {
+	a.cipher = cipher
+	return a
+}
+
 func (a *Aggregator[T]) ModelHook(modelHook any) *Aggregator[T] {
 	a.modelHook = modelHook
 	return a
@@ -73,24 +95,110 @@ func (a *Aggregator[T]) Pipeline(pipeline any) *Aggregator[T] {
 	return a
 }
 
+// Scopes is used to add the conditions of scopes to the $match stage prepended to the pipeline,
+// or inserted after its first stage if it must lead the pipeline, e.g. $geoNear or $search
+func (a *Aggregator[T]) Scopes(scopes ...query.Scope) *Aggregator[T] {
+	a.scopes = append(a.scopes, scopes...)
+	return a
+}
+
+// DefaultScopes is used to set the scopes added to the prepended $match stage unless Unscoped is called
+func (a *Aggregator[T]) DefaultScopes(scopes ...query.Scope) *Aggregator[T] {
+	a.defaultScopes = scopes
+	return a
+}
+
+// Unscoped is used to skip the default scopes
+func (a *Aggregator[T]) Unscoped() *Aggregator[T] {
+	a.unscoped = true
+	return a
+}
+
+// Collation is used to set the collation of the aggregation
+func (a *Aggregator[T]) Collation(collation *collation.Collation) *Aggregator[T] {
+	a.collation = collation
+	return a
+}
+
+// Hint is used to set the index of the aggregation, an index name or a specification such as sort.Asc("name")
+func (a *Aggregator[T]) Hint(hint any) *Aggregator[T] {
+	if b, ok := hint.(interface{ Build() bson.D }); ok {
+		hint = b.Build()
+	}
+	a.driverOpts.Hint = hint
+	return a
+}
+
+// MaxTime is used to bound the duration of the aggregation by a context timeout
+func (a *Aggregator[T]) MaxTime(maxTime time.Duration) *Aggregator[T] {
+	a.driverOpts.MaxTime = maxTime
+	return a
+}
+
+// Comment is used to set the comment of the aggregation
+func (a *Aggregator[T]) Comment(comment any) *Aggregator[T] {
+	a.driverOpts.Comment = comment
+	return a
+}
+
+// Let is used to set the variables of the aggregation, which can be used as $$var in the pipeline
+func (a *Aggregator[T]) Let(let any) *Aggregator[T] {
+	a.driverOpts.Let = let
+	return a
+}
+
+// WriteConcern is used to set the write concern of the aggregation, used by the $out and $merge stages
+func (a *Aggregator[T]) WriteConcern(wc *writeconcern.WriteConcern) *Aggregator[T] {
+	a.driverOpts.SetWriteConcern(wc)
+	return a
+}
+
+// BatchSize is used to set the number of documents of each batch returned by the server
+func (a *Aggregator[T]) BatchSize(batchSize int32) *Aggregator[T] {
+	a.driverOpts.BatchSize = &batchSize
+	return a
+}
+
+// AllowDiskUse is used to allow the stages of the aggregation to write temporary data to disk
+func (a *Aggregator[T]) AllowDiskUse(allowDiskUse bool) *Aggregator[T] {
+	a.driverOpts.AllowDiskUse = &allowDiskUse
+	return a
+}
+
+// ReadPreference is used to set the read preference of the aggregation
+func (a *Aggregator[T]) ReadPreference(rp *readpref.ReadPref) *Aggregator[T] {
+	a.driverOpts.SetReadPreference(rp)
+	return a
+}
+
+// ReadConcern is used to set the read concern of the aggregation
+func (a *Aggregator[T]) ReadConcern(rc *readconcern.ReadConcern) *Aggregator[T] {
+	a.driverOpts.SetReadConcern(rc)
+	return a
+}
+
 func (a *Aggregator[T]) Aggregate(ctx context.Context, opts ...options.Lister[options.AggregateOptions]) ([]*T, error) {
+	opts = a.aggregateOptions(opts)
+	pipeline := a.scopedPipeline()
+	ctx, cancel := a.driverOpts.Context(ctx)
+	defer cancel()
 	currentTime := time.Now()
-	globalOpContext := operation.NewOpContext(a.collection, operation.WithPipeline(a.pipeline), operation.WithMongoOptions(opts), operation.WithModelHook(a.modelHook), operation.WithStartTime(currentTime), operation.WithFields(a.fields))
-	opContext := NewOpContext(a.collection, a.pipeline, WithMongoOptions(opts), WithModelHook(a.modelHook), WithStartTime(currentTime), WithFields(a.fields))
+	globalOpContext := operation.NewOpContext(a.collection, operation.WithPipeline(pipeline), operation.WithMongoOptions(opts), operation.WithModelHook(a.modelHook), operation.WithStartTime(currentTime), operation.WithFields(a.fields))
+	opContext := NewOpContext(a.collection, pipeline, WithMongoOptions(opts), WithModelHook(a.modelHook), WithStartTime(currentTime), WithFields(a.fields))
 
 	err := a.preActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeInsert)
 	if err != nil {
 		return nil, err
 	}
 
-	cursor, err := a.collection.Aggregate(ctx, a.pipeline, opts...)
+	cursor, err := a.driverOpts.Collection(a.collection).Aggregate(ctx, pipeline, opts...)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
 	result := make([]*T, 0)
-	err = cursor.All(ctx, &result)
+	err = a.decodeCursor(ctx, cursor, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -108,22 +216,26 @@ func (a *Aggregator[T]) Aggregate(ctx context.Context, opts ...options.Lister[op
 // AggregateWithParse is used to parse the result of the aggregation
 // result must be a pointer to a slice
 func (a *Aggregator[T]) AggregateWithParse(ctx context.Context, result any, opts ...options.Lister[options.AggregateOptions]) error {
+	opts = a.aggregateOptions(opts)
+	pipeline := a.scopedPipeline()
+	ctx, cancel := a.driverOpts.Context(ctx)
+	defer cancel()
 
 	currentTime := time.Now()
-	globalOpContext := operation.NewOpContext(a.collection, operation.WithPipeline(a.pipeline), operation.WithMongoOptions(opts), operation.WithModelHook(a.modelHook), operation.WithStartTime(currentTime), operation.WithFields(a.fields))
-	opContext := NewOpContext(a.collection, a.pipeline, WithMongoOptions(opts), WithModelHook(a.modelHook), WithStartTime(currentTime), WithFields(a.fields))
+	globalOpContext := operation.NewOpContext(a.collection, operation.WithPipeline(pipeline), operation.WithMongoOptions(opts), operation.WithModelHook(a.modelHook), operation.WithStartTime(currentTime), operation.WithFields(a.fields))
+	opContext := NewOpContext(a.collection, pipeline, WithMongoOptions(opts), WithModelHook(a.modelHook), WithStartTime(currentTime), WithFields(a.fields))
 
 	err := a.preActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeInsert)
 	if err != nil {
 		return err
 	}
 
-	cursor, err := a.collection.Aggregate(ctx, a.pipeline, opts...)
+	cursor, err := a.driverOpts.Collection(a.collection).Aggregate(ctx, pipeline, opts...)
 	if err != nil {
 		return err
 	}
 	defer cursor.Close(ctx)
-	err = cursor.All(ctx, result)
+	err = a.decodeCursor(ctx, cursor, result)
 	if err != nil {
 		return err
 	}
@@ -138,6 +250,95 @@ func (a *Aggregator[T]) AggregateWithParse(ctx context.Context, result any, opts
 	return nil
 }
 
+// aggregateOptions appends the options set on the aggregator to opts
+func (a *Aggregator[T]) aggregateOptions(opts []options.Lister[options.AggregateOptions]) []options.Lister[options.AggregateOptions] {
+	if a.collation != nil {
+		opts = append(opts, options.Aggregate().SetCollation(a.collation.Options()))
+	}
+	return a.driverOpts.Aggregate(opts)
+}
+
+// leadingStages are the stages which must be the first of a pipeline, the $match of the scopes is inserted after them
+var leadingStages = map[string]bool{
+	"$geoNear":           true,
+	"$search":            true,
+	"$searchMeta":        true,
+	"$vectorSearch":      true,
+	"$collStats":         true,
+	"$indexStats":        true,
+	"$changeStream":      true,
+	"$listSearchIndexes": true,
+	"$planCacheStats":    true,
+}
+
+// scopedPipeline returns the pipeline with a $match stage of the conditions of the scopes prepended,
+// or inserted after the first stage if it must lead the pipeline, e.g. $geoNear
+func (a *Aggregator[T]) scopedPipeline() any {
+	scopes := a.scopes
+	if !a.unscoped {
+		scopes = append(scopes[:len(scopes):len(scopes)], a.defaultScopes...)
+	}
+	match := query.ApplyScopes(scopes...)
+	if len(match) == 0 {
+		return a.pipeline
+	}
+	stage := bson.D{{Key: "$match", Value: match}}
+	switch pipeline := a.pipeline.(type) {
+	case nil:
+		return mongo.Pipeline{stage}
+	case mongo.Pipeline:
+		return insertStage(pipeline, stage)
+	case []bson.D:
+		return insertStage(pipeline, stage)
+	case bson.A:
+		return insertStage(pipeline, any(stage))
+	}
+	v := reflect.ValueOf(a.pipeline)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		// let the driver report the invalid pipeline
+		return a.pipeline
+	}
+	stages := make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		stages = append(stages, v.Index(i).Interface())
+	}
+	return insertStage(stages, any(stage))
+}
+
+// insertStage returns a copy of pipeline with stage inserted first, or second if the first stage is a leading stage
+func insertStage[S ~[]E, E any](pipeline S, stage E) S {
+	i := 0
+	if len(pipeline) > 0 && leadingStages[stageName(pipeline[0])] {
+		i = 1
+	}
+	result := make(S, 0, len(pipeline)+1)
+	result = append(result, pipeline[:i]...)
+	result = append(result, stage)
+	return append(result, pipeline[i:]...)
+}
+
+// stageName returns the operator of stage, a bson.D or a map with a single key
+func stageName(stage any) string {
+	if d, ok := stage.(bson.D); ok {
+		if len(d) == 0 {
+			return ""
+		}
+		return d[0].Key
+	}
+	v := reflect.ValueOf(stage)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String || v.Len() != 1 {
+		return ""
+	}
+	return v.MapKeys()[0].String()
+}
+
+func (a *Aggregator[T]) decodeCursor(ctx context.Context, cursor *mongo.Cursor, result any) error {
+	if a.cipher == nil {
+		return cursor.All(ctx, result)
+	}
+	return a.cipher.DecodeCursor(ctx, cursor, a.fields, result)
+}
+
 func (a *Aggregator[T]) preActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *OpContext, opType operation.OpType) error {
 	err := a.dbCallbacks.Execute(ctx, globalOpContext, opType)
 	if err != nil {
@@ -20,11 +20,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/collation"
 	mocks "github.com/chenmingyong0423/go-mongox/v2/mock"
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.uber.org/mock/gomock"
 )
 
@@ -183,3 +187,92 @@ func TestAggregator_AggregateWithParse(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregator_Collation(t *testing.T) {
+	errStop := errors.New("stop")
+	var aggregateOptions options.AggregateOptions
+	a := NewAggregator[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil)
+	a.Collation(collation.CaseInsensitive("en")).RegisterBeforeHooks(func(ctx context.Context, opContext *OpContext, opts ...any) error {
+		for _, lister := range opContext.MongoOptions.([]options.Lister[options.AggregateOptions]) {
+			for _, set := range lister.List() {
+				assert.NoError(t, set(&aggregateOptions))
+			}
+		}
+		return errStop
+	})
+
+	_, err := a.Aggregate(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, &options.Collation{Locale: "en", Strength: 2}, aggregateOptions.Collation)
+}
+
+func TestAggregator_scopedPipeline(t *testing.T) {
+	active := func(b *query.Builder) *query.Builder { return b.Eq("status", "active") }
+	notDeleted := func(b *query.Builder) *query.Builder { return b.Exists("deleted_at", false) }
+	match := func(conds ...bson.E) bson.D { return bson.D{{Key: "$match", Value: bson.D(conds)}} }
+	group := bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$region"}}}}
+	geoNear := bson.D{{Key: "$geoNear", Value: bson.D{{Key: "near", Value: bson.A{0, 0}}, {Key: "distanceField", Value: "distance"}}}}
+	search := bson.D{{Key: "$search", Value: bson.D{{Key: "text", Value: bson.D{{Key: "query", Value: "mongox"}, {Key: "path", Value: "name"}}}}}}
+	vectorSearch := bson.M{"$vectorSearch": bson.M{"index": "vector", "path": "embedding", "queryVector": bson.A{0.1}, "limit": 10}}
+	collStats := bson.M{"$collStats": bson.M{"count": bson.M{}}}
+
+	testCases := []struct {
+		name       string
+		aggregator *Aggregator[any]
+		want       any
+	}{
+		{
+			name:       "no scope",
+			aggregator: NewAggregator[any](nil, nil, nil).Pipeline(mongo.Pipeline{group}),
+			want:       mongo.Pipeline{group},
+		},
+		{
+			name:       "scopes and default scopes",
+			aggregator: NewAggregator[any](nil, nil, nil).DefaultScopes(notDeleted).Scopes(active).Pipeline(mongo.Pipeline{group}),
+			want: mongo.Pipeline{
+				match(bson.E{Key: "status", Value: bson.D{{Key: "$eq", Value: "active"}}}, bson.E{Key: "deleted_at", Value: bson.D{{Key: "$exists", Value: false}}}),
+				group,
+			},
+		},
+		{
+			name:       "unscoped",
+			aggregator: NewAggregator[any](nil, nil, nil).DefaultScopes(notDeleted).Unscoped().Pipeline(bson.A{group}),
+			want:       bson.A{group},
+		},
+		{
+			name:       "nil pipeline",
+			aggregator: NewAggregator[any](nil, nil, nil).DefaultScopes(notDeleted),
+			want:       mongo.Pipeline{match(bson.E{Key: "deleted_at", Value: bson.D{{Key: "$exists", Value: false}}})},
+		},
+		{
+			name:       "slice of maps",
+			aggregator: NewAggregator[any](nil, nil, nil).Scopes(active).Pipeline([]bson.M{{"$limit": 1}}),
+			want:       []any{match(bson.E{Key: "status", Value: bson.D{{Key: "$eq", Value: "active"}}}), bson.M{"$limit": 1}},
+		},
+		{
+			name:       "geoNear",
+			aggregator: NewAggregator[any](nil, nil, nil).Scopes(active).Pipeline(mongo.Pipeline{geoNear, group}),
+			want:       mongo.Pipeline{geoNear, match(bson.E{Key: "status", Value: bson.D{{Key: "$eq", Value: "active"}}}), group},
+		},
+		{
+			name:       "search",
+			aggregator: NewAggregator[any](nil, nil, nil).Scopes(active).Pipeline([]bson.D{search}),
+			want:       []bson.D{search, match(bson.E{Key: "status", Value: bson.D{{Key: "$eq", Value: "active"}}})},
+		},
+		{
+			name:       "vectorSearch",
+			aggregator: NewAggregator[any](nil, nil, nil).Scopes(active).Pipeline(bson.A{vectorSearch, group}),
+			want:       bson.A{vectorSearch, match(bson.E{Key: "status", Value: bson.D{{Key: "$eq", Value: "active"}}}), group},
+		},
+		{
+			name:       "collStats",
+			aggregator: NewAggregator[any](nil, nil, nil).Scopes(active).Pipeline([]bson.M{collStats}),
+			want:       []any{collStats, match(bson.E{Key: "status", Value: bson.D{{Key: "$eq", Value: "active"}}})},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.aggregator.scopedPipeline())
+		})
+	}
+}
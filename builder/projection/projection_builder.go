@@ -0,0 +1,167 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package projection
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var ErrMixedProjection = errors.New("mongox: a projection can't mix inclusions and exclusions, except for _id")
+
+func NewBuilder() *Builder {
+	return &Builder{data: bson.D{}}
+}
+
+type Builder struct {
+	data bson.D
+}
+
+// Include includes the fields of keys, e.g. {"name": 1}
+func (b *Builder) Include(keys ...string) *Builder {
+	for _, key := range keys {
+		b.data = append(b.data, bson.E{Key: key, Value: 1})
+	}
+	return b
+}
+
+// Exclude excludes the fields of keys, e.g. {"password": 0}
+func (b *Builder) Exclude(keys ...string) *Builder {
+	for _, key := range keys {
+		b.data = append(b.data, bson.E{Key: key, Value: 0})
+	}
+	return b
+}
+
+// Positional includes the first element of the array key matching the query, e.g. {"grades.$": 1}
+func (b *Builder) Positional(key string) *Builder {
+	b.data = append(b.data, bson.E{Key: key + ".$", Value: 1})
+	return b
+}
+
+// ElemMatch includes the first element of the array key matching cond, e.g. {"grades": {"$elemMatch": cond}}
+func (b *Builder) ElemMatch(key string, cond any) *Builder {
+	b.data = append(b.data, bson.E{Key: key, Value: bson.D{{Key: ElemMatchOp, Value: cond}}})
+	return b
+}
+
+// Slice limits the number of elements of the array key, e.g. {"comments": {"$slice": -5}}
+func (b *Builder) Slice(key string, number int) *Builder {
+	b.data = append(b.data, bson.E{Key: key, Value: bson.D{{Key: SliceOp, Value: number}}})
+	return b
+}
+
+// SliceRanger returns limit elements of the array key after skipping skip, e.g. {"comments": {"$slice": [20, 10]}}
+func (b *Builder) SliceRanger(key string, skip, limit int) *Builder {
+	b.data = append(b.data, bson.E{Key: key, Value: bson.D{{Key: SliceOp, Value: []int{skip, limit}}}})
+	return b
+}
+
+// Meta sets key to the metadata keyword of the document, e.g. {"score": {"$meta": "textScore"}}
+func (b *Builder) Meta(key, keyword string) *Builder {
+	b.data = append(b.data, bson.E{Key: key, Value: bson.D{{Key: MetaOp, Value: keyword}}})
+	return b
+}
+
+// TextScore sets key to the score of the $text query
+func (b *Builder) TextScore(key string) *Builder {
+	return b.Meta(key, MetaTextScore)
+}
+
+// SearchScore sets key to the score of the $search stage of Atlas Search
+func (b *Builder) SearchScore(key string) *Builder {
+	return b.Meta(key, MetaSearchScore)
+}
+
+// Expr sets key to an aggregation expression, e.g. the result of the builders of builder/aggregation
+func (b *Builder) Expr(key string, expression any) *Builder {
+	b.data = append(b.data, bson.E{Key: key, Value: expression})
+	return b
+}
+
+func (b *Builder) Build() bson.D {
+	return b.data
+}
+
+// Err returns ErrMixedProjection if the projection mixes inclusions and exclusions, see Validate
+func (b *Builder) Err() error {
+	return Validate(b.data)
+}
+
+// Validate returns ErrMixedProjection if projection, a bson.D or a bson.M, mixes inclusions and exclusions.
+// _id can be excluded from any projection, $slice, $elemMatch and $meta are allowed in both kinds of projections,
+// other values such as aggregation expressions and literals are inclusions. Embedded projections are checked by their paths.
+func Validate(projection any) error {
+	var included, excluded string
+	return validate(projection, "", &included, &excluded)
+}
+
+func validate(projection any, prefix string, included, excluded *string) error {
+	for _, e := range elements(projection) {
+		path := e.Key
+		if prefix != "" {
+			path = prefix + "." + e.Key
+		}
+		if path == "_id" {
+			continue
+		}
+		switch v := e.Value.(type) {
+		case bool:
+			if v {
+				*included = path
+			} else {
+				*excluded = path
+			}
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			if fmt.Sprint(v) == "0" {
+				*excluded = path
+			} else {
+				*included = path
+			}
+		default:
+			nested := elements(v)
+			switch {
+			case len(nested) == 1 && (nested[0].Key == SliceOp || nested[0].Key == ElemMatchOp || nested[0].Key == MetaOp):
+			case len(nested) > 0 && !strings.HasPrefix(nested[0].Key, "$"):
+				if err := validate(v, path, included, excluded); err != nil {
+					return err
+				}
+			default:
+				*included = path
+			}
+		}
+		if *included != "" && *excluded != "" {
+			return fmt.Errorf("%w: %s is included and %s is excluded", ErrMixedProjection, *included, *excluded)
+		}
+	}
+	return nil
+}
+
+func elements(projection any) bson.D {
+	switch p := projection.(type) {
+	case bson.D:
+		return p
+	case bson.M:
+		d := make(bson.D, 0, len(p))
+		for k, v := range p {
+			d = append(d, bson.E{Key: k, Value: v})
+		}
+		return d
+	}
+	return nil
+}
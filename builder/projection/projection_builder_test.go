@@ -0,0 +1,125 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package projection
+
+import (
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/aggregation"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestBuilder(t *testing.T) {
+	testCases := []struct {
+		name    string
+		builder *Builder
+		want    bson.D
+		wantErr error
+	}{
+		{
+			name:    "include",
+			builder: NewBuilder().Include("name", "age").Exclude("_id"),
+			want:    bson.D{{Key: "name", Value: 1}, {Key: "age", Value: 1}, {Key: "_id", Value: 0}},
+		},
+		{
+			name:    "exclude",
+			builder: NewBuilder().Exclude("password", "token"),
+			want:    bson.D{{Key: "password", Value: 0}, {Key: "token", Value: 0}},
+		},
+		{
+			name:    "mixed",
+			builder: NewBuilder().Include("name").Exclude("password"),
+			want:    bson.D{{Key: "name", Value: 1}, {Key: "password", Value: 0}},
+			wantErr: ErrMixedProjection,
+		},
+		{
+			name:    "positional",
+			builder: NewBuilder().Positional("grades"),
+			want:    bson.D{{Key: "grades.$", Value: 1}},
+		},
+		{
+			name:    "elemMatch with exclusion",
+			builder: NewBuilder().ElemMatch("grades", bson.D{{Key: "score", Value: bson.D{{Key: "$gt", Value: 90}}}}).Exclude("password"),
+			want: bson.D{
+				{Key: "grades", Value: bson.D{{Key: "$elemMatch", Value: bson.D{{Key: "score", Value: bson.D{{Key: "$gt", Value: 90}}}}}}},
+				{Key: "password", Value: 0},
+			},
+		},
+		{
+			name:    "slice",
+			builder: NewBuilder().Slice("comments", -5).SliceRanger("tags", 20, 10).Include("title"),
+			want: bson.D{
+				{Key: "comments", Value: bson.D{{Key: "$slice", Value: -5}}},
+				{Key: "tags", Value: bson.D{{Key: "$slice", Value: []int{20, 10}}}},
+				{Key: "title", Value: 1},
+			},
+		},
+		{
+			name:    "meta",
+			builder: NewBuilder().TextScore("score").SearchScore("search").Meta("key", MetaIndexKey),
+			want: bson.D{
+				{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}},
+				{Key: "search", Value: bson.D{{Key: "$meta", Value: "searchScore"}}},
+				{Key: "key", Value: bson.D{{Key: "$meta", Value: "indexKey"}}},
+			},
+		},
+		{
+			name:    "expression",
+			builder: NewBuilder().Include("name").Expr("total", aggregation.SumWithoutKey([]any{"$price", "$tax"})),
+			want: bson.D{
+				{Key: "name", Value: 1},
+				{Key: "total", Value: bson.D{{Key: "$sum", Value: []any{"$price", "$tax"}}}},
+			},
+		},
+		{
+			name:    "expression with exclusion",
+			builder: NewBuilder().Exclude("password").Expr("total", aggregation.SumWithoutKey([]any{"$price", "$tax"})),
+			want: bson.D{
+				{Key: "password", Value: 0},
+				{Key: "total", Value: bson.D{{Key: "$sum", Value: []any{"$price", "$tax"}}}},
+			},
+			wantErr: ErrMixedProjection,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.builder.Build())
+			assert.ErrorIs(t, tc.builder.Err(), tc.wantErr)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		projection any
+		wantErr    error
+	}{
+		{name: "nil", projection: nil},
+		{name: "bool", projection: bson.D{{Key: "name", Value: true}, {Key: "_id", Value: false}}},
+		{name: "mixed bool", projection: bson.D{{Key: "name", Value: true}, {Key: "age", Value: false}}, wantErr: ErrMixedProjection},
+		{name: "bson.M", projection: bson.M{"name": 1, "age": 0}, wantErr: ErrMixedProjection},
+		{name: "embedded", projection: bson.D{{Key: "address", Value: bson.D{{Key: "city", Value: 0}}}, {Key: "age", Value: 0}}},
+		{name: "mixed embedded", projection: bson.D{{Key: "address", Value: bson.M{"city": 1}}, {Key: "age", Value: 0.0}}, wantErr: ErrMixedProjection},
+		{name: "literal", projection: bson.D{{Key: "kind", Value: "user"}, {Key: "age", Value: int64(0)}}, wantErr: ErrMixedProjection},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, Validate(tc.projection), tc.wantErr)
+		})
+	}
+}
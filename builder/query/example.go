@@ -0,0 +1,84 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"reflect"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FromExample builds an equality filter from the non-zero fields of example, which must be a pointer to a struct.
+//
+//   - the keys are the mongo field names parsed by field.ParseFields
+//   - nested structs are flattened into dot paths, e.g. {"address.city": "x"}
+//   - non-nil pointers are always used, so they can match zero values
+//   - the zero values of the fields listed in includeZero, by their (dot) paths, are used too
+//   - fields tagged with autoCreateTime or autoUpdateTime are ignored
+func FromExample[T any](example *T, includeZero ...string) bson.D {
+	filter := bson.D{}
+	if example == nil {
+		return filter
+	}
+	includes := make(map[string]struct{}, len(includeZero))
+	for _, path := range includeZero {
+		includes[path] = struct{}{}
+	}
+	appendExample(&filter, reflect.ValueOf(example).Elem(), field.ParseFields(example), "", includes)
+	return filter
+}
+
+func appendExample(filter *bson.D, v reflect.Value, fields []*field.Filed, prefix string, includes map[string]struct{}) {
+	for idx, fd := range fields {
+		if idx >= v.NumField() || !v.Type().Field(idx).IsExported() {
+			continue
+		}
+		value := v.Field(idx)
+		if fd.InlinedFields != nil {
+			appendExample(filter, value, fd.InlinedFields, prefix, includes)
+			continue
+		}
+		if fd.MongoField == "-" || fd.AutoCreateTime != 0 || fd.AutoUpdateTime != 0 {
+			continue
+		}
+		path := fd.MongoField
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		_, included := includes[path]
+
+		explicit := false
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				if included {
+					*filter = append(*filter, bson.E{Key: path, Value: nil})
+				}
+				continue
+			}
+			value = value.Elem()
+			explicit = true
+		}
+		if utils.IsDocument(value.Type()) && !included {
+			appendExample(filter, value, field.ParseFields(reflect.New(value.Type()).Interface()), path, includes)
+			continue
+		}
+		if included || explicit || !value.IsZero() {
+			*filter = append(*filter, bson.E{Key: path, Value: value.Interface()})
+		}
+	}
+}
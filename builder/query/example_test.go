@@ -0,0 +1,125 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ExampleBase struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	CreatedAt time.Time     `bson:"created_at"`
+	UpdatedAt int64         `bson:"updated_at" mongox:"autoUpdateTime:milli"`
+}
+
+type exampleAddress struct {
+	City    string `bson:"city"`
+	ZipCode string `bson:"zip_code"`
+}
+
+type exampleUser struct {
+	ExampleBase `bson:",inline"`
+	Name        string          `bson:"name"`
+	Status      int             `bson:"status"`
+	Age         *int            `bson:"age"`
+	Address     exampleAddress  `bson:"address"`
+	Company     *exampleAddress `bson:"company"`
+	Tags        []string        `bson:"tags"`
+	LoginAt     time.Time       `bson:"login_at"`
+	Ignored     string          `bson:"-"`
+}
+
+func TestFromExample(t *testing.T) {
+	id := bson.NewObjectID()
+	zero := 0
+	loginAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		example     *exampleUser
+		includeZero []string
+		want        bson.D
+	}{
+		{
+			name:    "nil example",
+			example: nil,
+			want:    bson.D{},
+		},
+		{
+			name:    "zero example",
+			example: &exampleUser{},
+			want:    bson.D{},
+		},
+		{
+			name:    "non-zero fields",
+			example: &exampleUser{Name: "chenmingyong", Status: 2, Tags: []string{"go"}, LoginAt: loginAt, Ignored: "ignored"},
+			want: bson.D{
+				{Key: "name", Value: "chenmingyong"},
+				{Key: "status", Value: 2},
+				{Key: "tags", Value: []string{"go"}},
+				{Key: "login_at", Value: loginAt},
+			},
+		},
+		{
+			name: "inlined fields and ignored time fields",
+			example: &exampleUser{ExampleBase: ExampleBase{
+				ID:        id,
+				CreatedAt: loginAt,
+				UpdatedAt: 1,
+			}},
+			want: bson.D{{Key: "_id", Value: id}},
+		},
+		{
+			name:    "nested structs",
+			example: &exampleUser{Address: exampleAddress{City: "shenzhen"}, Company: &exampleAddress{ZipCode: "518000"}},
+			want: bson.D{
+				{Key: "address.city", Value: "shenzhen"},
+				{Key: "company.zip_code", Value: "518000"},
+			},
+		},
+		{
+			name:    "non-nil pointer to zero value",
+			example: &exampleUser{Age: &zero},
+			want:    bson.D{{Key: "age", Value: 0}},
+		},
+		{
+			name:        "include zero values",
+			example:     &exampleUser{Name: "chenmingyong"},
+			includeZero: []string{"status", "address.city", "company", "age"},
+			want: bson.D{
+				{Key: "name", Value: "chenmingyong"},
+				{Key: "status", Value: 0},
+				{Key: "age", Value: nil},
+				{Key: "address.city", Value: ""},
+				{Key: "company", Value: nil},
+			},
+		},
+		{
+			name:        "include nested struct",
+			example:     &exampleUser{},
+			includeZero: []string{"address"},
+			want:        bson.D{{Key: "address", Value: exampleAddress{}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, FromExample(tc.example, tc.includeZero...))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Field is a typed field reference, the values passed to its predicates must have the type of the field.
+// The predicates return the same bson.D as the corresponding functions of this package,
+// so they can be composed with And, Or and Nor, e.g.
+//
+//	query.Or(query.F[int]("age").Gt(18), query.F[string]("name").In("a", "b"))
+type Field[T any] struct {
+	key string
+}
+
+// F returns a typed reference to the field key whose values are of type T
+func F[T any](key string) Field[T] {
+	return Field[T]{key: key}
+}
+
+// Key returns the name of the field
+func (f Field[T]) Key() string {
+	return f.key
+}
+
+func (f Field[T]) Eq(value T) bson.D {
+	return Eq(f.key, value)
+}
+
+func (f Field[T]) Ne(value T) bson.D {
+	return Ne(f.key, value)
+}
+
+func (f Field[T]) Gt(value T) bson.D {
+	return Gt(f.key, value)
+}
+
+func (f Field[T]) Gte(value T) bson.D {
+	return Gte(f.key, value)
+}
+
+func (f Field[T]) Lt(value T) bson.D {
+	return Lt(f.key, value)
+}
+
+func (f Field[T]) Lte(value T) bson.D {
+	return Lte(f.key, value)
+}
+
+func (f Field[T]) In(values ...T) bson.D {
+	return In(f.key, values...)
+}
+
+func (f Field[T]) NIn(values ...T) bson.D {
+	return NIn(f.key, values...)
+}
+
+func (f Field[T]) Exists(value bool) bson.D {
+	return Exists(f.key, value)
+}
+
+func (f Field[T]) Type(value bson.Type) bson.D {
+	return Type(f.key, value)
+}
+
+// All matches arrays containing all the elements of values, T is expected to be a slice type, e.g.
+//
+//	query.F[[]string]("tags").All([]string{"go", "mongo"})
+func (f Field[T]) All(values T) bson.D {
+	return bson.D{bson.E{Key: f.key, Value: bson.D{{Key: AllOp, Value: values}}}}
+}
+
+func (f Field[T]) Size(value int) bson.D {
+	return Size(f.key, value)
+}
+
+func (f Field[T]) ElemMatch(cond any) bson.D {
+	return ElemMatch(f.key, cond)
+}
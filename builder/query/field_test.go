@@ -0,0 +1,68 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestField(t *testing.T) {
+	age := F[int]("age")
+	name := F[string]("name")
+	tags := F[[]string]("tags")
+
+	testCases := []struct {
+		name string
+		got  bson.D
+		want bson.D
+	}{
+		{name: "eq", got: age.Eq(18), want: Eq("age", 18)},
+		{name: "ne", got: age.Ne(18), want: Ne("age", 18)},
+		{name: "gt", got: age.Gt(18), want: Gt("age", 18)},
+		{name: "gte", got: age.Gte(18), want: Gte("age", 18)},
+		{name: "lt", got: age.Lt(18), want: Lt("age", 18)},
+		{name: "lte", got: age.Lte(18), want: Lte("age", 18)},
+		{name: "in", got: name.In("a", "b"), want: In("name", "a", "b")},
+		{name: "nin", got: name.NIn("a", "b"), want: NIn("name", "a", "b")},
+		{name: "exists", got: name.Exists(true), want: Exists("name", true)},
+		{name: "type", got: name.Type(bson.TypeString), want: Type("name", bson.TypeString)},
+		{
+			name: "all",
+			got:  tags.All([]string{"go", "mongo"}),
+			want: NewBuilder().AllString("tags", "go", "mongo").Build(),
+		},
+		{name: "size", got: tags.Size(2), want: Size("tags", 2)},
+		{name: "elemMatch", got: tags.ElemMatch(bson.D{{Key: "$eq", Value: "go"}}), want: ElemMatch("tags", bson.D{{Key: "$eq", Value: "go"}})},
+		{
+			name: "compose with and",
+			got:  And(age.Gte(18), name.Eq("chenmingyong")),
+			want: bson.D{{Key: "$and", Value: []any{Gte("age", 18), Eq("name", "chenmingyong")}}},
+		},
+		{
+			name: "compose with or",
+			got:  Or(age.Lt(18), age.Gt(60)),
+			want: Or(Lt("age", 18), Gt("age", 60)),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got)
+		})
+	}
+	assert.Equal(t, "age", age.Key())
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Merge returns the conjunction of filters, which can be bson.D, bson.M, builders such as *query.Builder
+// or examples (pointers to structs, see FromExample).
+// The conditions of nested $and are flattened and merged into a single document when their keys don't collide,
+// otherwise they are combined with $and. Empty filters are ignored.
+func Merge(filters ...any) bson.D {
+	var clauses []any
+	for _, filter := range filters {
+		clauses = appendClauses(clauses, filter, AndOp)
+	}
+	if merged, ok := mergeClauses(clauses); ok {
+		return merged
+	}
+	return bson.D{{Key: AndOp, Value: clauses}}
+}
+
+// MergeOr returns the disjunction of filters, see Merge, flattening the nested $or. Empty filters are ignored.
+func MergeOr(filters ...any) bson.D {
+	var clauses []any
+	for _, filter := range filters {
+		clauses = appendClauses(clauses, filter, OrOp)
+	}
+	if len(clauses) <= 1 {
+		merged, _ := mergeClauses(clauses)
+		return merged
+	}
+	return bson.D{{Key: OrOp, Value: clauses}}
+}
+
+// appendClauses appends the clauses of filter to clauses, the items of filter if it's a single op condition
+func appendClauses(clauses []any, filter any, op string) []any {
+	d, ok := toFilter(filter)
+	if !ok {
+		return append(clauses, filter)
+	}
+	if len(d) == 0 {
+		return clauses
+	}
+	if len(d) == 1 && d[0].Key == op {
+		if items := reflect.ValueOf(d[0].Value); items.Kind() == reflect.Slice || items.Kind() == reflect.Array {
+			for i := 0; i < items.Len(); i++ {
+				clauses = appendClauses(clauses, items.Index(i).Interface(), op)
+			}
+			return clauses
+		}
+	}
+	return append(clauses, d)
+}
+
+// mergeClauses merges the clauses into a single document if they are all documents with distinct keys
+func mergeClauses(clauses []any) (bson.D, bool) {
+	merged := bson.D{}
+	keys := make(map[string]struct{})
+	for _, clause := range clauses {
+		d, ok := clause.(bson.D)
+		if !ok {
+			return nil, false
+		}
+		for _, e := range d {
+			if _, ok := keys[e.Key]; ok {
+				return nil, false
+			}
+			keys[e.Key] = struct{}{}
+		}
+		merged = append(merged, d...)
+	}
+	return merged, true
+}
+
+// toFilter converts filter into a bson.D, it reports false for the types it can't convert
+func toFilter(filter any) (bson.D, bool) {
+	switch f := filter.(type) {
+	case nil:
+		return bson.D{}, true
+	case bson.D:
+		return f, true
+	case bson.M:
+		return mapFilter(f), true
+	case map[string]any:
+		return mapFilter(f), true
+	case interface{ Build() bson.D }:
+		return f.Build(), true
+	}
+	v := reflect.ValueOf(filter)
+	if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Struct {
+		d := bson.D{}
+		if !v.IsNil() {
+			appendExample(&d, v.Elem(), field.ParseFields(filter), "", nil)
+		}
+		return d, true
+	}
+	return nil, false
+}
+
+// mapFilter returns the entries of m sorted by key, so that the merged filters are deterministic
+func mapFilter(m map[string]any) bson.D {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	d := make(bson.D, 0, len(m))
+	for _, k := range keys {
+		d = append(d, bson.E{Key: k, Value: m[k]})
+	}
+	return d
+}
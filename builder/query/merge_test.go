@@ -0,0 +1,96 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMerge(t *testing.T) {
+	raw := bson.Raw{}
+	testCases := []struct {
+		name    string
+		filters []any
+		want    bson.D
+	}{
+		{name: "no filter", want: bson.D{}},
+		{name: "empty filters", filters: []any{nil, bson.D{}, bson.M{}}, want: bson.D{}},
+		{name: "single filter", filters: []any{bson.D{{Key: "name", Value: "chenmingyong"}}}, want: bson.D{{Key: "name", Value: "chenmingyong"}}},
+		{
+			name:    "distinct keys",
+			filters: []any{bson.D{{Key: "name", Value: "chenmingyong"}}, bson.M{"status": 1, "age": Gt("age", 18)[0].Value}, NewBuilder().Exists("email", true)},
+			want: bson.D{
+				{Key: "name", Value: "chenmingyong"},
+				{Key: "age", Value: bson.D{{Key: GtOp, Value: 18}}},
+				{Key: "status", Value: 1},
+				{Key: "email", Value: bson.D{{Key: ExistsOp, Value: true}}},
+			},
+		},
+		{
+			name:    "colliding keys",
+			filters: []any{Gt("age", 18), Lt("age", 30)},
+			want:    bson.D{{Key: AndOp, Value: []any{Gt("age", 18), Lt("age", 30)}}},
+		},
+		{
+			name:    "nested $and",
+			filters: []any{And(Eq("name", "a"), And(Gt("age", 18), bson.D{})), Eq("status", 1)},
+			want:    bson.D{{Key: "name", Value: bson.D{{Key: EqOp, Value: "a"}}}, {Key: "age", Value: bson.D{{Key: GtOp, Value: 18}}}, {Key: "status", Value: bson.D{{Key: EqOp, Value: 1}}}},
+		},
+		{
+			name:    "example",
+			filters: []any{&exampleUser{Name: "chenmingyong"}, Eq("status", 1)},
+			want:    bson.D{{Key: "name", Value: "chenmingyong"}, {Key: "status", Value: bson.D{{Key: EqOp, Value: 1}}}},
+		},
+		{
+			name:    "unsupported filter",
+			filters: []any{Eq("status", 1), raw},
+			want:    bson.D{{Key: AndOp, Value: []any{Eq("status", 1), raw}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Merge(tc.filters...))
+		})
+	}
+}
+
+func TestMergeOr(t *testing.T) {
+	testCases := []struct {
+		name    string
+		filters []any
+		want    bson.D
+	}{
+		{name: "no filter", want: bson.D{}},
+		{name: "empty filter", filters: []any{bson.D{}, Eq("name", "a")}, want: Eq("name", "a")},
+		{
+			name:    "two filters",
+			filters: []any{Eq("name", "a"), bson.M{"name": "b"}},
+			want:    bson.D{{Key: OrOp, Value: []any{Eq("name", "a"), bson.D{{Key: "name", Value: "b"}}}}},
+		},
+		{
+			name:    "nested $or",
+			filters: []any{Or(Eq("name", "a"), Eq("name", "b")), Eq("name", "c")},
+			want:    bson.D{{Key: OrOp, Value: []any{Eq("name", "a"), Eq("name", "b"), Eq("name", "c")}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, MergeOr(tc.filters...))
+		})
+	}
+}
@@ -0,0 +1,622 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ParseError is the error of Parse, Pos is the byte offset of the error in the expression
+type ParseError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("mongox: parse error at position %d: %s", e.Pos, e.Msg)
+}
+
+// ParseOption configures Parse
+type ParseOption func(p *parser)
+
+// WithParams binds the parameters of the expression, e.g. {"min": 18} for "age > :min"
+func WithParams(params map[string]any) ParseOption {
+	return func(p *parser) {
+		p.params = params
+	}
+}
+
+// WithFields makes Parse reject the fields that aren't in fields, e.g. the result of field.ParseFields.
+// The nested fields of the structs are checked too, _id is always accepted.
+func WithFields(fields []*field.Filed) ParseOption {
+	return func(p *parser) {
+		p.fields = fields
+		p.checkFields = true
+	}
+}
+
+// ParseFor is like Parse but rejects the fields that aren't fields of T, see WithFields
+func ParseFor[T any](expr string, opts ...ParseOption) (bson.D, error) {
+	return Parse(expr, append(opts, WithFields(field.ParseFields(new(T))))...)
+}
+
+// Parse parses a filter expression into the bson.D built by the functions of the package, e.g.
+//
+//	age >= 18 AND (status IN ['a', 'b'] OR name ~ /^jo/i) AND deleted_at NOT EXISTS
+//
+// is Merge(Gte("age", 18), Or(In("status", "a", "b"), RegexOptions("name", "^jo", "i")), Exists("deleted_at", false)).
+//
+// The conditions are a field followed by =, !=, <>, >, >=, <, <= and a value, [NOT] IN and a list of values,
+// ~ and a regular expression /pattern/options or a string, or [NOT] EXISTS.
+// They are combined with NOT, AND and OR, in decreasing order of precedence, and parentheses.
+// The values are strings quoted with ' or ", numbers, true, false, null, lists [a, b],
+// ObjectId('hex'), Date('RFC 3339 time or date') and parameters :name bound by WithParams.
+// The keywords are case-insensitive, the fields are dotted paths which can be quoted with `.
+func Parse(expr string, opts ...ParseOption) (bson.D, error) {
+	p := &parser{lexer: lexer{input: expr}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return filter, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenField
+	tokenString
+	tokenNumber
+	tokenRegex
+	tokenParam
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	// value of the strings, the numbers and the regular expressions
+	value any
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// is reports whether t is the keyword kw
+func (t token) is(kw string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, kw)
+}
+
+var punctuation = map[byte]tokenKind{'(': tokenLParen, ')': tokenRParen, '[': tokenLBracket, ']': tokenRBracket, ',': tokenComma}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && unicode.IsSpace(rune(l.input[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if start == len(l.input) {
+		return token{kind: tokenEOF, pos: start}, nil
+	}
+	c := l.input[start]
+	switch {
+	case punctuation[c] != tokenEOF:
+		l.pos++
+		return token{kind: punctuation[c], text: string(c), pos: start}, nil
+	case strings.ContainsRune("=!<>~", rune(c)):
+		for _, op := range []string{"!=", "<>", "<=", ">=", "=", "<", ">", "~"} {
+			if strings.HasPrefix(l.input[start:], op) {
+				l.pos += len(op)
+				return token{kind: tokenOp, text: op, pos: start}, nil
+			}
+		}
+		return token{}, &ParseError{Pos: start, Msg: fmt.Sprintf("unexpected %q", c)}
+	case c == '\'' || c == '"':
+		return l.quoted(c, tokenString)
+	case c == '`':
+		return l.quoted(c, tokenField)
+	case c == '/':
+		return l.regex()
+	case c == ':':
+		l.pos++
+		name := l.word()
+		if name == "" {
+			return token{}, &ParseError{Pos: start, Msg: "missing parameter name after :"}
+		}
+		return token{kind: tokenParam, text: l.input[start:l.pos], pos: start, value: name}, nil
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return l.number()
+	case isWordByte(c):
+		return token{kind: tokenIdent, text: l.word(), pos: start}, nil
+	}
+	return token{}, &ParseError{Pos: start, Msg: fmt.Sprintf("unexpected %q", c)}
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '.' || c == '$' || c >= 0x80 || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+func (l *lexer) word() string {
+	start := l.pos
+	for l.pos < len(l.input) && isWordByte(l.input[l.pos]) {
+		l.pos++
+	}
+	return l.input[start:l.pos]
+}
+
+// quoted scans a string quoted with q, \ escapes the next character
+func (l *lexer) quoted(q byte, kind tokenKind) (token, error) {
+	start := l.pos
+	var sb strings.Builder
+	for l.pos++; l.pos < len(l.input); l.pos++ {
+		c := l.input[l.pos]
+		switch {
+		case c == '\\' && l.pos+1 < len(l.input):
+			l.pos++
+			sb.WriteByte(l.input[l.pos])
+		case c == q:
+			l.pos++
+			return token{kind: kind, text: l.input[start:l.pos], pos: start, value: sb.String()}, nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return token{}, &ParseError{Pos: start, Msg: "unterminated " + string(q)}
+}
+
+// regex scans /pattern/options, \/ is a slash of the pattern
+func (l *lexer) regex() (token, error) {
+	start := l.pos
+	var sb strings.Builder
+	for l.pos++; l.pos < len(l.input); l.pos++ {
+		c := l.input[l.pos]
+		if c == '\\' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '/' {
+			l.pos++
+			sb.WriteByte('/')
+			continue
+		}
+		if c == '/' {
+			l.pos++
+			optionsPos := l.pos
+			options := l.word()
+			if strings.Trim(options, "imsx") != "" {
+				return token{}, &ParseError{Pos: optionsPos, Msg: fmt.Sprintf("invalid regular expression options %q", options)}
+			}
+			return token{kind: tokenRegex, text: l.input[start:l.pos], pos: start, value: bson.Regex{Pattern: sb.String(), Options: options}}, nil
+		}
+		sb.WriteByte(c)
+	}
+	return token{}, &ParseError{Pos: start, Msg: "unterminated regular expression"}
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.input) && strings.ContainsRune("0123456789.eE+-", rune(l.input[l.pos])) {
+		if c := l.input[l.pos]; (c == '+' || c == '-') && l.input[l.pos-1] != 'e' && l.input[l.pos-1] != 'E' {
+			break
+		}
+		l.pos++
+	}
+	text := l.input[start:l.pos]
+	if i, err := strconv.Atoi(text); err == nil {
+		return token{kind: tokenNumber, text: text, pos: start, value: i}, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return token{}, &ParseError{Pos: start, Msg: fmt.Sprintf("invalid number %q", text)}
+	}
+	return token{kind: tokenNumber, text: text, pos: start, value: f}, nil
+}
+
+type parser struct {
+	lexer
+	tok token
+
+	params      map[string]any
+	fields      []*field.Filed
+	checkFields bool
+}
+
+func (p *parser) next() (err error) {
+	p.tok, err = p.lexer.next()
+	return err
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &ParseError{Pos: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) expect(kind tokenKind, what string) error {
+	if p.tok.kind != kind {
+		return p.errorf("expected %s, got %s", what, p.tok)
+	}
+	return p.next()
+}
+
+// parseOr parses the disjunction of conjunctions
+func (p *parser) parseOr() (bson.D, error) {
+	filter, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.is("OR") {
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		filter = MergeOr(filter, right)
+	}
+	return filter, nil
+}
+
+// parseAnd parses the conjunction of negations
+func (p *parser) parseAnd() (bson.D, error) {
+	filter, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.is("AND") {
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		filter = Merge(filter, right)
+	}
+	return filter, nil
+}
+
+// parseNot parses a condition, a parenthesized expression or their negation
+func (p *parser) parseNot() (bson.D, error) {
+	switch {
+	case p.tok.is("NOT"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		filter, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return Nor(filter), nil
+	case p.tok.kind == tokenLParen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return filter, p.expect(tokenRParen, ")")
+	}
+	return p.parseCondition()
+}
+
+func (p *parser) parseCondition() (bson.D, error) {
+	key, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+
+	negated := p.tok.is("NOT")
+	if negated {
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case p.tok.is("EXISTS"):
+		return Exists(key, !negated), p.next()
+	case p.tok.is("IN"):
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		if negated {
+			return bson.D{{Key: key, Value: bson.D{{Key: NinOp, Value: values}}}}, nil
+		}
+		return bson.D{{Key: key, Value: bson.D{{Key: InOp, Value: values}}}}, nil
+	case negated:
+		return nil, p.errorf("expected IN or EXISTS after NOT, got %s", p.tok)
+	case p.tok.kind != tokenOp:
+		return nil, p.errorf("expected an operator after %s, got %s", key, p.tok)
+	}
+
+	op := p.tok.text
+	if err = p.next(); err != nil {
+		return nil, err
+	}
+	if op == "~" {
+		return p.parseRegex(key)
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "=":
+		return Eq(key, value), nil
+	case "!=", "<>":
+		return Ne(key, value), nil
+	case ">":
+		return Gt(key, value), nil
+	case ">=":
+		return Gte(key, value), nil
+	case "<":
+		return Lt(key, value), nil
+	default:
+		return Lte(key, value), nil
+	}
+}
+
+func (p *parser) parseField() (string, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokenField:
+		tok.text = tok.value.(string)
+	case tok.kind != tokenIdent:
+		return "", p.errorf("expected a field, got %s", tok)
+	}
+	if tok.text == "" || strings.Contains(tok.text, "$") || strings.HasPrefix(tok.text, ".") || strings.HasSuffix(tok.text, ".") || strings.Contains(tok.text, "..") {
+		return "", p.errorf("invalid field %s", tok)
+	}
+	if p.checkFields && !knownField(p.fields, tok.text) {
+		return "", p.errorf("unknown field %s", tok)
+	}
+	return tok.text, p.next()
+}
+
+// parseRegex parses the regular expression of key ~, a /pattern/options or a string
+func (p *parser) parseRegex(key string) (bson.D, error) {
+	var re bson.Regex
+	switch p.tok.kind {
+	case tokenRegex:
+		re = p.tok.value.(bson.Regex)
+	case tokenString:
+		re.Pattern = p.tok.value.(string)
+	case tokenParam:
+		value, err := p.param()
+		if err != nil {
+			return nil, err
+		}
+		pattern, ok := value.(string)
+		if !ok {
+			return nil, p.errorf("parameter %s of ~ must be a string, got %T", p.tok.text, value)
+		}
+		re.Pattern = pattern
+	default:
+		return nil, p.errorf("expected a regular expression, got %s", p.tok)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if re.Options != "" {
+		return RegexOptions(key, re.Pattern, re.Options), nil
+	}
+	return Regex(key, re.Pattern), nil
+}
+
+// parseList parses the values of IN, a list or a parameter bound to a slice
+func (p *parser) parseList() (any, error) {
+	if p.tok.kind == tokenParam {
+		value, err := p.param()
+		if err != nil {
+			return nil, err
+		}
+		if v := reflect.ValueOf(value); v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, p.errorf("parameter %s of IN must be a slice, got %T", p.tok.text, value)
+		}
+		return value, p.next()
+	}
+	if p.tok.kind != tokenLBracket && p.tok.kind != tokenLParen {
+		return nil, p.errorf("expected a list, got %s", p.tok)
+	}
+	return p.parseValue()
+}
+
+func (p *parser) parseValue() (any, error) {
+	tok := p.tok
+	var value any
+	switch {
+	case tok.kind == tokenString || tok.kind == tokenNumber:
+		value = tok.value
+	case tok.kind == tokenParam:
+		v, err := p.param()
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	case tok.kind == tokenLBracket || tok.kind == tokenLParen:
+		return p.parseValues()
+	case tok.is("true"), tok.is("false"):
+		value = tok.is("true")
+	case tok.is("null"):
+		value = nil
+	case tok.is("ObjectId"), tok.is("Date"):
+		return p.parseCall()
+	default:
+		return nil, p.errorf("expected a value, got %s", tok)
+	}
+	return value, p.next()
+}
+
+// parseValues parses [a, b] or (a, b) into a []any, as built by In
+func (p *parser) parseValues() (any, error) {
+	closing := tokenRBracket
+	if p.tok.kind == tokenLParen {
+		closing = tokenRParen
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	values := make([]any, 0)
+	for p.tok.kind != closing {
+		if len(values) > 0 {
+			if err := p.expect(tokenComma, ", or the end of the list"); err != nil {
+				return nil, err
+			}
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, p.next()
+}
+
+// parseCall parses ObjectId('hex') and Date('time')
+func (p *parser) parseCall() (any, error) {
+	fn := p.tok
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenLParen, "("); err != nil {
+		return nil, err
+	}
+	arg := p.tok
+	if arg.kind != tokenString {
+		return nil, p.errorf("expected a string, got %s", arg)
+	}
+	s := arg.value.(string)
+	var value any
+	if fn.is("ObjectId") {
+		id, err := bson.ObjectIDFromHex(s)
+		if err != nil {
+			return nil, p.errorf("invalid ObjectId %s", arg)
+		}
+		value = id
+	} else {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", s); err != nil {
+				return nil, p.errorf("invalid Date %s", arg)
+			}
+		}
+		value = t
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	return value, p.expect(tokenRParen, ")")
+}
+
+func (p *parser) param() (any, error) {
+	name := p.tok.value.(string)
+	value, ok := p.params[name]
+	if !ok {
+		return nil, p.errorf("unbound parameter %s", p.tok.text)
+	}
+	return value, nil
+}
+
+// knownField reports whether path is a field of fields, following the nested structs, slices and maps
+func knownField(fields []*field.Filed, path string) bool {
+	segments := strings.Split(path, ".")
+	if segments[0] == "_id" && len(segments) == 1 {
+		return true
+	}
+	for i := 0; i < len(segments); i++ {
+		fd := findField(fields, segments[i])
+		if fd == nil {
+			return false
+		}
+		t := fd.FieldType
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t != reflect.TypeOf(bson.D{}) {
+			t = t.Elem()
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			// the index of an item, e.g. tags.0
+			if i+1 < len(segments) {
+				if _, err := strconv.Atoi(segments[i+1]); err == nil {
+					i++
+				}
+			}
+		}
+		if i == len(segments)-1 {
+			return true
+		}
+		switch {
+		case t.Kind() == reflect.Map || t.Kind() == reflect.Interface || t == reflect.TypeOf(bson.D{}):
+			return true
+		case t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}):
+			fields = field.ParseFields(reflect.New(t).Interface())
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func findField(fields []*field.Filed, name string) *field.Filed {
+	for _, fd := range fields {
+		if fd.InlinedFields != nil {
+			if inlined := findField(fd.InlinedFields, name); inlined != nil {
+				return inlined
+			}
+			continue
+		}
+		if fd.MongoField == name {
+			return fd
+		}
+	}
+	return nil
+}
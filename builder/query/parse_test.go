@@ -0,0 +1,151 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestParse(t *testing.T) {
+	id := bson.NewObjectID()
+	testCases := []struct {
+		name   string
+		expr   string
+		params map[string]any
+		want   bson.D
+	}{
+		{
+			name: "example",
+			expr: "age >= 18 AND (status IN ['a','b'] OR name ~ /^jo/i) AND deleted_at NOT EXISTS",
+			want: NewBuilder().Gte("age", 18).Or(In[any]("status", "a", "b"), RegexOptions("name", "^jo", "i")).Exists("deleted_at", false).Build(),
+		},
+		{
+			name: "comparisons",
+			expr: `a = 'x' and b != "y" and c <> 1.5 and d > -2 and e < 3 and f <= 1e3`,
+			want: Merge(Eq("a", "x"), Ne("b", "y"), Ne("c", 1.5), Gt("d", -2), Lt("e", 3), Lte("f", 1000.0)),
+		},
+		{
+			name: "literals",
+			expr: `active = true AND deleted = FALSE AND parent = null AND _id = ObjectId('` + id.Hex() + `') AND day = Date('2025-01-02')`,
+			want: Merge(Eq("active", true), Eq("deleted", false), Eq("parent", nil), Eq("_id", id), Eq("day", time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))),
+		},
+		{
+			name: "same field",
+			expr: "age >= 18 AND age < 30",
+			want: And(Gte("age", 18), Lt("age", 30)),
+		},
+		{
+			name: "precedence",
+			expr: "a = 1 OR b = 2 AND c = 3 OR NOT d = 4",
+			want: Or(Eq("a", 1), Merge(Eq("b", 2), Eq("c", 3)), Nor(Eq("d", 4))),
+		},
+		{
+			name: "not in, exists and regex string",
+			expr: "`order.status` NOT IN ('x') AND tags.0 EXISTS AND name ~ 'jo'",
+			want: Merge(NIn[any]("order.status", "x"), Exists("tags.0", true), Regex("name", "jo")),
+		},
+		{
+			name: "escapes",
+			expr: `name = 'it\'s' AND path ~ /^a\/b/`,
+			want: Merge(Eq("name", "it's"), Regex("path", "^a/b")),
+		},
+		{
+			name:   "params",
+			expr:   "age > :min AND status IN :statuses AND name ~ :prefix AND tags IN [:tag, 'b']",
+			params: map[string]any{"min": 18, "statuses": []string{"a", "b"}, "prefix": "^jo", "tag": "a"},
+			want:   Merge(Gt("age", 18), In("status", "a", "b"), Regex("name", "^jo"), In[any]("tags", "a", "b")),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.expr, WithParams(tc.params))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expr    string
+		wantPos int
+		wantMsg string
+	}{
+		{name: "empty", expr: "", wantPos: 0, wantMsg: "expected a field, got end of expression"},
+		{name: "missing value", expr: "age >= ", wantPos: 7, wantMsg: "expected a value, got end of expression"},
+		{name: "missing operator", expr: "age 18", wantPos: 4, wantMsg: `expected an operator after age, got "18"`},
+		{name: "unquoted string", expr: "status = active", wantPos: 9, wantMsg: `expected a value, got "active"`},
+		{name: "unclosed parenthesis", expr: "(a = 1 OR b = 2", wantPos: 15, wantMsg: "expected ), got end of expression"},
+		{name: "trailing tokens", expr: "a = 1 b = 2", wantPos: 6, wantMsg: `unexpected "b"`},
+		{name: "unterminated string", expr: "a = 'x", wantPos: 4, wantMsg: "unterminated '"},
+		{name: "unterminated regex", expr: "a ~ /x", wantPos: 4, wantMsg: "unterminated regular expression"},
+		{name: "regex options", expr: "a ~ /x/g", wantPos: 7, wantMsg: `invalid regular expression options "g"`},
+		{name: "operator field", expr: "$where = 1", wantPos: 0, wantMsg: `invalid field "$where"`},
+		{name: "not", expr: "a NOT = 1", wantPos: 6, wantMsg: `expected IN or EXISTS after NOT, got "="`},
+		{name: "list", expr: "a IN 'x'", wantPos: 5, wantMsg: `expected a list, got "'x'"`},
+		{name: "list separator", expr: "a IN [1 2]", wantPos: 8, wantMsg: `expected , or the end of the list, got "2"`},
+		{name: "unbound parameter", expr: "a > :min", wantPos: 4, wantMsg: "unbound parameter :min"},
+		{name: "object id", expr: "_id = ObjectId('x')", wantPos: 15, wantMsg: `invalid ObjectId "'x'"`},
+		{name: "bad character", expr: "a = 1 & b = 2", wantPos: 6, wantMsg: `unexpected '&'`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.expr)
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr), "%v", err)
+			assert.Equal(t, tc.wantPos, parseErr.Pos)
+			assert.Equal(t, tc.wantMsg, parseErr.Msg)
+		})
+	}
+}
+
+func TestParseFor(t *testing.T) {
+	type Address struct {
+		City string `bson:"city"`
+	}
+	type Base struct {
+		CreatedAt time.Time `bson:"created_at"`
+	}
+	type User struct {
+		Base    `bson:",inline"`
+		Name    string            `bson:"name"`
+		Address *Address          `bson:"address"`
+		Orders  []Address         `bson:"orders"`
+		Attrs   map[string]string `bson:"attrs"`
+	}
+
+	for _, expr := range []string{
+		"_id = 1",
+		"name = 'x' AND created_at > Date('2025-01-02T00:00:00Z')",
+		"address.city = 'x' AND orders.city = 'y' AND orders.0.city = 'z'",
+		"attrs.color = 'red' AND address EXISTS",
+	} {
+		_, err := ParseFor[User](expr)
+		assert.NoError(t, err, expr)
+	}
+
+	_, err := ParseFor[User]("name = 'x' OR address.zip = '1'")
+	assert.Equal(t, &ParseError{Pos: 14, Msg: `unknown field "address.zip"`}, err)
+	_, err = ParseFor[User]("name.first = 'x'")
+	assert.Equal(t, &ParseError{Pos: 0, Msg: `unknown field "name.first"`}, err)
+	assert.EqualError(t, err, `mongox: parse error at position 0: unknown field "name.first"`)
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ProjectionOf builds the projection including the fields of R, a struct, by the mongo field names parsed by field.ParseFields.
+// _id is excluded unless R has a field for it.
+// It returns nil, i.e. the whole documents, if R isn't a struct or has an inlined map whose keys are unknown.
+func ProjectionOf[R any]() bson.D {
+	t := reflect.TypeOf((*R)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	projection := bson.D{}
+	if !appendProjection(&projection, t, field.ParseFields(new(R))) {
+		return nil
+	}
+	for _, e := range projection {
+		if e.Key == "_id" {
+			return projection
+		}
+	}
+	return append(projection, bson.E{Key: "_id", Value: 0})
+}
+
+func appendProjection(projection *bson.D, t reflect.Type, fields []*field.Filed) bool {
+	for idx, fd := range fields {
+		structField := t.Field(idx)
+		if fd.InlinedFields != nil {
+			inlined := structField.Type
+			if inlined.Kind() == reflect.Ptr {
+				inlined = inlined.Elem()
+			}
+			if !appendProjection(projection, inlined, fd.InlinedFields) {
+				return false
+			}
+			continue
+		}
+		if structField.Type.Kind() == reflect.Map && strings.Contains(structField.Tag.Get("bson"), ",inline") {
+			return false
+		}
+		if !structField.IsExported() || fd.MongoField == "-" {
+			continue
+		}
+		*projection = append(*projection, bson.E{Key: fd.MongoField, Value: 1})
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type projectionBase struct {
+	CreatedAt int64 `bson:"created_at"`
+}
+
+type projectionSummary struct {
+	ID              bson.ObjectID `bson:"_id,omitempty"`
+	Name            string        `bson:"name"`
+	*projectionBase `bson:",inline"`
+	Ignored         string `bson:"-"`
+	private         string
+}
+
+type projectionName struct {
+	Name    string `bson:"name"`
+	Address struct {
+		City string `bson:"city"`
+	} `bson:"address"`
+}
+
+type projectionMap struct {
+	Name  string         `bson:"name"`
+	Extra map[string]any `bson:",inline"`
+}
+
+func TestProjectionOf(t *testing.T) {
+	_ = projectionSummary{}.private
+
+	assert.Equal(t, bson.D{{Key: "_id", Value: 1}, {Key: "name", Value: 1}, {Key: "created_at", Value: 1}}, ProjectionOf[projectionSummary]())
+	assert.Equal(t, bson.D{{Key: "name", Value: 1}, {Key: "address", Value: 1}, {Key: "_id", Value: 0}}, ProjectionOf[projectionName]())
+	assert.Nil(t, ProjectionOf[projectionMap]())
+	assert.Nil(t, ProjectionOf[bson.M]())
+	assert.Nil(t, ProjectionOf[*projectionName]())
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrUnsafeFilter is returned by Sanitize for the filters with operators that aren't allowed by the policy,
+// server-side JavaScript or unanchored regular expressions
+var ErrUnsafeFilter = errors.New("mongox: unsafe filter")
+
+// SanitizeMode is what Sanitize does with the unsafe conditions
+type SanitizeMode int
+
+const (
+	// RejectUnsafe returns ErrUnsafeFilter for the first unsafe condition
+	RejectUnsafe SanitizeMode = iota
+	// StripUnsafe removes the unsafe conditions, i.e. the fields whose conditions are unsafe
+	StripUnsafe
+)
+
+// SanitizePolicy is the policy of Sanitize, the zero value rejects all the operators
+type SanitizePolicy struct {
+	Mode SanitizeMode
+	// Operators maps the fields to the operators allowed in their conditions, e.g. {"age": {"$gt", "$lt"}}.
+	// The operators of the "*" field are allowed for all the fields.
+	Operators map[string][]string
+	// Logical allows the top-level $and, $or and $nor, whose clauses are sanitized like the filter
+	Logical bool
+}
+
+// forbiddenOps run JavaScript on the server, they are never allowed
+var forbiddenOps = map[string]struct{}{
+	WhereOp:        {},
+	"$function":    {},
+	"$accumulator": {},
+}
+
+// Sanitize returns the filter of untrusted input, e.g. a JSON body decoded into a map[string]any,
+// checked against policy: the $-prefixed keys are only allowed as the operators of the fields listed in policy.Operators,
+// $where, $function and $accumulator are never allowed, nor are the regular expressions not anchored with ^.
+// input is a bson.D, a bson.M, a map[string]any or a builder, the keys of the maps are sorted.
+func Sanitize(input any, policy *SanitizePolicy) (bson.D, error) {
+	if policy == nil {
+		policy = &SanitizePolicy{}
+	}
+	filter, ok := toFilter(input)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported filter type %T", ErrUnsafeFilter, input)
+	}
+	return policy.sanitize(filter)
+}
+
+func (p *SanitizePolicy) sanitize(filter bson.D) (bson.D, error) {
+	sanitized := make(bson.D, 0, len(filter))
+	for _, e := range filter {
+		var err error
+		switch {
+		case p.Logical && (e.Key == AndOp || e.Key == OrOp || e.Key == NorOp):
+			e.Value, err = p.sanitizeClauses(e.Key, e.Value)
+		case strings.HasPrefix(e.Key, "$"):
+			err = fmt.Errorf("%w: %s is not allowed", ErrUnsafeFilter, e.Key)
+		default:
+			err = p.checkValue(e.Key, e.Value)
+		}
+		if err != nil {
+			if p.Mode == StripUnsafe {
+				continue
+			}
+			return nil, err
+		}
+		sanitized = append(sanitized, e)
+	}
+	return sanitized, nil
+}
+
+// sanitizeClauses sanitizes the clauses of the logical operator op, the empty clauses are removed
+func (p *SanitizePolicy) sanitizeClauses(op string, value any) ([]any, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%w: %s must be an array", ErrUnsafeFilter, op)
+	}
+	clauses := make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		clause, ok := toFilter(v.Index(i).Interface())
+		if !ok {
+			return nil, fmt.Errorf("%w: the clauses of %s must be documents", ErrUnsafeFilter, op)
+		}
+		clause, err := p.sanitize(clause)
+		if err != nil {
+			return nil, err
+		}
+		if len(clause) != 0 {
+			clauses = append(clauses, clause)
+		}
+	}
+	if len(clauses) == 0 {
+		return nil, fmt.Errorf("%w: %s has no safe clause", ErrUnsafeFilter, op)
+	}
+	return clauses, nil
+}
+
+// checkValue checks the condition of field, including the nested documents and arrays
+func (p *SanitizePolicy) checkValue(field string, value any) error {
+	switch v := value.(type) {
+	case bson.Regex:
+		return checkRegex(field, v.Pattern)
+	case bson.D:
+		for _, e := range v {
+			if err := p.checkKey(field, e.Key, e.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case bson.M:
+		return p.checkMap(field, v)
+	case map[string]any:
+		return p.checkMap(field, v)
+	case []byte:
+		return nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			if err := p.checkValue(field, v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *SanitizePolicy) checkMap(field string, m map[string]any) error {
+	for _, e := range mapFilter(m) {
+		if err := p.checkKey(field, e.Key, e.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *SanitizePolicy) checkKey(field, key string, value any) error {
+	if strings.HasPrefix(key, "$") {
+		if _, ok := forbiddenOps[key]; ok || !p.allowed(field, key) {
+			return fmt.Errorf("%w: %s is not allowed for %s", ErrUnsafeFilter, key, field)
+		}
+		if pattern, ok := value.(string); ok && key == RegexOp {
+			return checkRegex(field, pattern)
+		}
+	}
+	return p.checkValue(field, value)
+}
+
+func (p *SanitizePolicy) allowed(field, op string) bool {
+	for _, f := range []string{field, "*"} {
+		for _, allowed := range p.Operators[f] {
+			if allowed == op {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkRegex rejects the unanchored patterns, which scan all the values of the field
+func checkRegex(field, pattern string) error {
+	if !strings.HasPrefix(pattern, "^") {
+		return fmt.Errorf("%w: the regular expression of %s must start with ^", ErrUnsafeFilter, field)
+	}
+	return nil
+}
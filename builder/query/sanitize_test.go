@@ -0,0 +1,174 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestSanitize(t *testing.T) {
+	policy := &SanitizePolicy{
+		Operators: map[string][]string{
+			"age":  {"$gt", "$lt"},
+			"name": {"$regex", "$options"},
+			"tags": {"$elemMatch"},
+			"*":    {"$eq", "$in"},
+		},
+		Logical: true,
+	}
+	strip := *policy
+	strip.Mode = StripUnsafe
+
+	testCases := []struct {
+		name   string
+		input  any
+		policy *SanitizePolicy
+
+		want      bson.D
+		wantStrip bson.D
+		wantErr   error
+	}{
+		{
+			name:      "values",
+			input:     map[string]any{"status": "active", "age": 18.0, "deleted": nil, "roles": []any{"admin"}, "profile": map[string]any{"city": "x"}},
+			want:      bson.D{{Key: "age", Value: 18.0}, {Key: "deleted", Value: nil}, {Key: "profile", Value: map[string]any{"city": "x"}}, {Key: "roles", Value: []any{"admin"}}, {Key: "status", Value: "active"}},
+			wantStrip: bson.D{{Key: "age", Value: 18.0}, {Key: "deleted", Value: nil}, {Key: "profile", Value: map[string]any{"city": "x"}}, {Key: "roles", Value: []any{"admin"}}, {Key: "status", Value: "active"}},
+		},
+		{
+			name:      "operator injection",
+			input:     map[string]any{"email": "burt@example.com", "password": map[string]any{"$ne": nil}},
+			wantStrip: bson.D{{Key: "email", Value: "burt@example.com"}},
+			wantErr:   ErrUnsafeFilter,
+		},
+		{
+			name:      "allowed operators",
+			input:     bson.M{"age": bson.M{"$gt": 18, "$lt": 30}, "status": bson.M{"$in": bson.A{"a", "b"}}},
+			want:      bson.D{{Key: "age", Value: bson.M{"$gt": 18, "$lt": 30}}, {Key: "status", Value: bson.M{"$in": bson.A{"a", "b"}}}},
+			wantStrip: bson.D{{Key: "age", Value: bson.M{"$gt": 18, "$lt": 30}}, {Key: "status", Value: bson.M{"$in": bson.A{"a", "b"}}}},
+		},
+		{
+			name:      "operator of another field",
+			input:     bson.D{{Key: "status", Value: bson.D{{Key: "$gt", Value: ""}}}, {Key: "age", Value: bson.D{{Key: "$gt", Value: 18}}}},
+			wantStrip: bson.D{{Key: "age", Value: bson.D{{Key: "$gt", Value: 18}}}},
+			wantErr:   ErrUnsafeFilter,
+		},
+		{
+			name:      "nested operator",
+			input:     bson.M{"tags": bson.M{"$elemMatch": bson.M{"$ne": "x"}}},
+			wantStrip: bson.D{},
+			wantErr:   ErrUnsafeFilter,
+		},
+		{
+			name:      "operator in an embedded document",
+			input:     bson.M{"profile": bson.M{"city": bson.M{"$ne": nil}}},
+			wantStrip: bson.D{},
+			wantErr:   ErrUnsafeFilter,
+		},
+		{
+			name:      "top-level $where",
+			input:     bson.M{"$where": "sleep(1000)", "status": "a"},
+			wantStrip: bson.D{{Key: "status", Value: "a"}},
+			wantErr:   ErrUnsafeFilter,
+		},
+		{
+			name:  "$function even if allowed",
+			input: bson.M{"age": bson.M{"$gt": bson.M{"$function": bson.M{"body": "function() { return 1 }"}}}},
+			policy: &SanitizePolicy{
+				Operators: map[string][]string{"age": {"$gt", "$function"}},
+			},
+			wantErr: ErrUnsafeFilter,
+		},
+		{
+			name:      "anchored regex",
+			input:     bson.M{"name": bson.M{"$regex": "^burt", "$options": "i"}},
+			want:      bson.D{{Key: "name", Value: bson.M{"$regex": "^burt", "$options": "i"}}},
+			wantStrip: bson.D{{Key: "name", Value: bson.M{"$regex": "^burt", "$options": "i"}}},
+		},
+		{
+			name:      "unanchored regex",
+			input:     bson.M{"name": bson.M{"$regex": "(a+)+$"}},
+			wantStrip: bson.D{},
+			wantErr:   ErrUnsafeFilter,
+		},
+		{
+			name:      "unanchored regex value",
+			input:     bson.D{{Key: "name", Value: bson.Regex{Pattern: ".*"}}, {Key: "age", Value: 1}},
+			wantStrip: bson.D{{Key: "age", Value: 1}},
+			wantErr:   ErrUnsafeFilter,
+		},
+		{
+			name:  "logical operators",
+			input: bson.M{"$or": []any{map[string]any{"status": "a"}, map[string]any{"password": map[string]any{"$ne": nil}}}},
+			wantStrip: bson.D{{Key: "$or", Value: []any{
+				bson.D{{Key: "status", Value: "a"}},
+			}}},
+			wantErr: ErrUnsafeFilter,
+		},
+		{
+			name:      "logical operator without safe clause",
+			input:     bson.M{"$and": bson.A{bson.M{"$where": "1"}}, "status": "a"},
+			wantStrip: bson.D{{Key: "status", Value: "a"}},
+			wantErr:   ErrUnsafeFilter,
+		},
+		{
+			name:    "logical operators not allowed",
+			input:   bson.M{"$or": bson.A{bson.M{"status": "a"}}},
+			policy:  &SanitizePolicy{},
+			wantErr: ErrUnsafeFilter,
+		},
+		{
+			name:    "unsupported type",
+			input:   "status",
+			wantErr: ErrUnsafeFilter,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.policy != nil {
+				_, err := Sanitize(tc.input, tc.policy)
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			got, err := Sanitize(tc.input, policy)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+
+			got, err = Sanitize(tc.input, &strip)
+			if tc.wantStrip == nil {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStrip, got)
+		})
+	}
+}
+
+func TestSanitize_NilPolicy(t *testing.T) {
+	got, err := Sanitize(map[string]any{"name": "burt"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "name", Value: "burt"}}, got)
+
+	_, err = Sanitize(map[string]any{"name": map[string]any{"$eq": "burt"}}, nil)
+	assert.ErrorIs(t, err, ErrUnsafeFilter)
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+// Scope adds reusable conditions to a builder, e.g.
+//
+//	func Active(b *query.Builder) *query.Builder { return b.Eq("status", "active").Ne("banned", true) }
+type Scope func(*Builder) *Builder
+
+// ApplyScopes returns the conjunction of the conditions of scopes, each scope is applied to a new builder
+// and the results are combined by Merge
+func ApplyScopes(scopes ...Scope) bson.D {
+	filters := make([]any, 0, len(scopes))
+	for _, scope := range scopes {
+		if scope != nil {
+			filters = append(filters, scope(NewBuilder()))
+		}
+	}
+	return Merge(filters...)
+}
@@ -0,0 +1,41 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestApplyScopes(t *testing.T) {
+	active := func(b *Builder) *Builder { return b.Eq("status", "active").Ne("banned", true) }
+	byRegion := func(region string) Scope {
+		return func(b *Builder) *Builder { return b.Eq("region", region) }
+	}
+
+	assert.Equal(t, bson.D{}, ApplyScopes())
+	assert.Equal(t, bson.D{}, ApplyScopes(nil))
+	assert.Equal(t, bson.D{
+		{Key: "status", Value: bson.D{{Key: EqOp, Value: "active"}}},
+		{Key: "banned", Value: bson.D{{Key: NeOp, Value: true}}},
+		{Key: "region", Value: bson.D{{Key: EqOp, Value: "eu"}}},
+	}, ApplyScopes(active, byRegion("eu")))
+	assert.Equal(t, bson.D{{Key: AndOp, Value: []any{
+		bson.D{{Key: "region", Value: bson.D{{Key: EqOp, Value: "eu"}}}},
+		bson.D{{Key: "region", Value: bson.D{{Key: EqOp, Value: "us"}}}},
+	}}}, ApplyScopes(byRegion("eu"), byRegion("us")))
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	MetaOp = "$meta"
+
+	MetaTextScore = "textScore"
+)
+
+func NewBuilder() *Builder {
+	return &Builder{data: bson.D{}}
+}
+
+// Asc returns a builder sorting by keys in ascending order, e.g. sort.Asc("name").Desc("created_at")
+func Asc(keys ...string) *Builder {
+	return NewBuilder().Asc(keys...)
+}
+
+// Desc returns a builder sorting by keys in descending order
+func Desc(keys ...string) *Builder {
+	return NewBuilder().Desc(keys...)
+}
+
+// Meta returns a builder sorting by the metadata keyword, see Builder.Meta
+func Meta(key, keyword string) *Builder {
+	return NewBuilder().Meta(key, keyword)
+}
+
+// Builder builds the ordered bson.D of a sort, a key sorted twice keeps its first position with the last direction
+type Builder struct {
+	data bson.D
+}
+
+// Asc sorts by keys in ascending order
+func (b *Builder) Asc(keys ...string) *Builder {
+	for _, key := range keys {
+		b.set(key, 1)
+	}
+	return b
+}
+
+// Desc sorts by keys in descending order
+func (b *Builder) Desc(keys ...string) *Builder {
+	for _, key := range keys {
+		b.set(key, -1)
+	}
+	return b
+}
+
+// Meta sorts by the metadata keyword, e.g. {"score": {"$meta": "textScore"}}
+func (b *Builder) Meta(key, keyword string) *Builder {
+	b.set(key, bson.D{{Key: MetaOp, Value: keyword}})
+	return b
+}
+
+// TextScore sorts by the score of the $text query, in descending order
+func (b *Builder) TextScore(key string) *Builder {
+	return b.Meta(key, MetaTextScore)
+}
+
+func (b *Builder) Build() bson.D {
+	return b.data
+}
+
+func (b *Builder) set(key string, value any) {
+	for idx := range b.data {
+		if b.data[idx].Key == key {
+			b.data[idx].Value = value
+			return
+		}
+	}
+	b.data = append(b.data, bson.E{Key: key, Value: value})
+}
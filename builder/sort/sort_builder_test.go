@@ -0,0 +1,62 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sort
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestBuilder(t *testing.T) {
+	testCases := []struct {
+		name    string
+		builder *Builder
+		want    bson.D
+	}{
+		{name: "empty", builder: NewBuilder(), want: bson.D{}},
+		{
+			name:    "asc and desc",
+			builder: Asc("name").Desc("created_at", "_id"),
+			want:    bson.D{{Key: "name", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			name:    "desc first",
+			builder: Desc("age").Asc("name"),
+			want:    bson.D{{Key: "age", Value: -1}, {Key: "name", Value: 1}},
+		},
+		{
+			name:    "meta",
+			builder: Meta("score", "textScore").Asc("name"),
+			want:    bson.D{{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}}, {Key: "name", Value: 1}},
+		},
+		{
+			name:    "text score",
+			builder: NewBuilder().TextScore("score"),
+			want:    bson.D{{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}}},
+		},
+		{
+			name:    "key sorted twice",
+			builder: Asc("name", "age").Desc("name"),
+			want:    bson.D{{Key: "name", Value: -1}, {Key: "age", Value: 1}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.builder.Build())
+		})
+	}
+}
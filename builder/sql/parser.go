@@ -0,0 +1,772 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOp
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	// quoted identifiers are never keywords
+	quoted bool
+	value  any
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of statement"
+	}
+	return strconv.Quote(t.text)
+}
+
+// is reports whether t is one of the keywords kws
+func (t token) is(kws ...string) bool {
+	if t.kind != tokenIdent || t.quoted {
+		return false
+	}
+	for _, kw := range kws {
+		if strings.EqualFold(t.text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t token) isPunct(p string) bool {
+	return t.kind == tokenPunct && t.text == p
+}
+
+// keywords can't be used as identifiers unless they are quoted
+var keywords = map[string]struct{}{
+	"SELECT": {}, "DISTINCT": {}, "FROM": {}, "WHERE": {}, "GROUP": {}, "BY": {}, "HAVING": {}, "ORDER": {},
+	"LIMIT": {}, "OFFSET": {}, "JOIN": {}, "INNER": {}, "LEFT": {}, "RIGHT": {}, "FULL": {}, "OUTER": {},
+	"CROSS": {}, "ON": {}, "AS": {}, "AND": {}, "OR": {}, "NOT": {}, "IN": {}, "LIKE": {}, "ILIKE": {},
+	"IS": {}, "NULL": {}, "BETWEEN": {}, "ASC": {}, "DESC": {}, "TRUE": {}, "FALSE": {}, "UNION": {},
+}
+
+func isKeyword(t token) bool {
+	_, ok := keywords[strings.ToUpper(t.text)]
+	return t.kind == tokenIdent && !t.quoted && ok
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	for pos := 0; ; {
+		for pos < len(input) && unicode.IsSpace(rune(input[pos])) {
+			pos++
+		}
+		if pos == len(input) {
+			return append(tokens, token{kind: tokenEOF, pos: pos}), nil
+		}
+		start, c := pos, input[pos]
+		switch {
+		case strings.ContainsRune("(),*;+-/%", rune(c)):
+			pos++
+			tokens = append(tokens, token{kind: tokenPunct, text: string(c), pos: start})
+		case strings.ContainsRune("=!<>", rune(c)):
+			op := ""
+			for _, o := range []string{"!=", "<>", "<=", ">=", "=", "<", ">"} {
+				if strings.HasPrefix(input[pos:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, syntaxError(start, "unexpected %q", c)
+			}
+			pos += len(op)
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: start})
+		case c == '\'' || c == '"' || c == '`':
+			// 'string', "identifier" and `identifier`, the quote is escaped by doubling it
+			var sb strings.Builder
+			for pos++; ; pos++ {
+				if pos == len(input) {
+					return nil, syntaxError(start, "unterminated %c", c)
+				}
+				if input[pos] == c {
+					if pos+1 < len(input) && input[pos+1] == c {
+						pos++
+					} else {
+						pos++
+						break
+					}
+				}
+				sb.WriteByte(input[pos])
+			}
+			if c == '\'' {
+				tokens = append(tokens, token{kind: tokenString, text: input[start:pos], pos: start, value: sb.String()})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: sb.String(), pos: start, quoted: true})
+			}
+		case c == '.' || (c >= '0' && c <= '9'):
+			for pos < len(input) && (input[pos] == '.' || (input[pos] >= '0' && input[pos] <= '9')) {
+				pos++
+			}
+			text := input[start:pos]
+			var value any
+			if i, err := strconv.Atoi(text); err == nil {
+				value = i
+			} else if f, err := strconv.ParseFloat(text, 64); err == nil {
+				value = f
+			} else {
+				return nil, syntaxError(start, "invalid number %q", text)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, pos: start, value: value})
+		case c == '_' || c >= 0x80 || unicode.IsLetter(rune(c)):
+			for pos < len(input) && (input[pos] == '_' || input[pos] == '.' || input[pos] >= 0x80 ||
+				unicode.IsLetter(rune(input[pos])) || unicode.IsDigit(rune(input[pos]))) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: input[start:pos], pos: start})
+		default:
+			return nil, syntaxError(start, "unexpected %q", c)
+		}
+	}
+}
+
+// statement is a parsed SELECT statement
+type statement struct {
+	all     bool
+	items   []selectItem
+	from    source
+	joins   []join
+	where   expr
+	groupBy []*column
+	having  expr
+	orderBy []orderItem
+	limit   *int64
+	offset  *int64
+}
+
+type source struct {
+	name  string
+	alias string
+}
+
+type join struct {
+	source
+	left bool
+	// on are the columns of the equality of the ON clause
+	on  [2]*column
+	pos int
+}
+
+type column struct {
+	path string
+	pos  int
+}
+
+// aggregate is an aggregate function, arg is nil for COUNT(*)
+type aggregate struct {
+	fn  string
+	arg *column
+	pos int
+}
+
+// operand is a column or an aggregate
+type operand struct {
+	col *column
+	agg *aggregate
+}
+
+func (o operand) pos() int {
+	if o.col != nil {
+		return o.col.pos
+	}
+	return o.agg.pos
+}
+
+type selectItem struct {
+	operand
+	alias string
+}
+
+type orderItem struct {
+	operand
+	desc bool
+}
+
+type expr any
+
+type logicalExpr struct {
+	and         bool
+	left, right expr
+}
+
+type notExpr struct {
+	x expr
+}
+
+type compareExpr struct {
+	operand
+	op    string
+	value any
+}
+
+type inExpr struct {
+	operand
+	values []any
+	not    bool
+}
+
+type likeExpr struct {
+	operand
+	pattern     string
+	insensitive bool
+	not         bool
+}
+
+type nullExpr struct {
+	operand
+	not bool
+}
+
+type betweenExpr struct {
+	operand
+	low, high any
+	not       bool
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) tok() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept advances if the current token is one of the keywords kws
+func (p *parser) accept(kws ...string) bool {
+	if p.tok().is(kws...) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kw string) error {
+	if !p.accept(kw) {
+		return syntaxError(p.tok().pos, "expected %s, got %s", kw, p.tok())
+	}
+	return nil
+}
+
+func (p *parser) expectPunct(punct string) error {
+	if !p.tok().isPunct(punct) {
+		return syntaxError(p.tok().pos, "expected %s, got %s", punct, p.tok())
+	}
+	p.advance()
+	return nil
+}
+
+func parse(input string) (*statement, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok().isPunct(";") {
+		p.advance()
+	}
+	switch t := p.tok(); {
+	case t.kind == tokenEOF:
+		return stmt, nil
+	case t.is("UNION"):
+		return nil, unsupported(t.pos, "UNION")
+	default:
+		return nil, syntaxError(t.pos, "unexpected %s", t)
+	}
+}
+
+func (p *parser) parseSelect() (*statement, error) {
+	if err := p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	if t := p.tok(); t.is("DISTINCT") {
+		return nil, unsupported(t.pos, "SELECT DISTINCT")
+	}
+	stmt := &statement{}
+	if p.tok().isPunct("*") {
+		p.advance()
+		stmt.all = true
+	} else {
+		for {
+			item, err := p.parseSelectItem()
+			if err != nil {
+				return nil, err
+			}
+			stmt.items = append(stmt.items, item)
+			if !p.tok().isPunct(",") {
+				break
+			}
+			p.advance()
+		}
+	}
+
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	from, err := p.parseSource()
+	if err != nil {
+		return nil, err
+	}
+	stmt.from = from
+	for {
+		j, ok, err := p.parseJoin()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		stmt.joins = append(stmt.joins, j)
+	}
+
+	if p.accept("WHERE") {
+		if stmt.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("GROUP") {
+		if err = p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			col, err := p.parseColumn()
+			if err != nil {
+				return nil, err
+			}
+			stmt.groupBy = append(stmt.groupBy, col)
+			if !p.tok().isPunct(",") {
+				break
+			}
+			p.advance()
+		}
+	}
+	if p.accept("HAVING") {
+		if stmt.having, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("ORDER") {
+		if err = p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			o, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			item := orderItem{operand: o}
+			if p.accept("DESC") {
+				item.desc = true
+			} else {
+				p.accept("ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, item)
+			if !p.tok().isPunct(",") {
+				break
+			}
+			p.advance()
+		}
+	}
+	if p.accept("LIMIT") {
+		if stmt.limit, err = p.parseCount("LIMIT"); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("OFFSET") {
+		if stmt.offset, err = p.parseCount("OFFSET"); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseSelectItem() (selectItem, error) {
+	if t := p.tok(); t.kind == tokenIdent && strings.HasSuffix(t.text, ".") && p.tokens[p.i+1].isPunct("*") {
+		return selectItem{}, unsupported(t.pos, "%s* in the select list", t.text)
+	}
+	o, err := p.parseOperand()
+	if err != nil {
+		return selectItem{}, err
+	}
+	item := selectItem{operand: o}
+	if p.accept("AS") || (p.tok().kind == tokenIdent && !isKeyword(p.tok())) {
+		t := p.advance()
+		if t.kind != tokenIdent || isKeyword(t) || strings.Contains(t.text, ".") {
+			return selectItem{}, syntaxError(t.pos, "expected an alias, got %s", t)
+		}
+		item.alias = t.text
+	}
+	return item, nil
+}
+
+func (p *parser) parseSource() (source, error) {
+	t := p.advance()
+	switch {
+	case t.isPunct("("):
+		return source{}, unsupported(t.pos, "subqueries")
+	case t.kind != tokenIdent || isKeyword(t) || strings.Contains(t.text, "."):
+		return source{}, syntaxError(t.pos, "expected a collection, got %s", t)
+	}
+	s := source{name: t.text, alias: t.text}
+	p.accept("AS")
+	if a := p.tok(); a.kind == tokenIdent && !isKeyword(a) {
+		p.advance()
+		s.alias = a.text
+	}
+	return s, nil
+}
+
+// parseJoin parses [INNER | LEFT [OUTER]] JOIN collection [alias] ON a = b, it reports false if there is no join
+func (p *parser) parseJoin() (join, bool, error) {
+	t := p.tok()
+	j := join{pos: t.pos}
+	switch {
+	case t.is("RIGHT", "FULL", "CROSS"):
+		return j, false, unsupported(t.pos, "%s JOIN", strings.ToUpper(t.text))
+	case t.is("LEFT"):
+		p.advance()
+		p.accept("OUTER")
+		j.left = true
+	case t.is("INNER"):
+		p.advance()
+	case !t.is("JOIN"):
+		return j, false, nil
+	}
+	if err := p.expect("JOIN"); err != nil {
+		return j, false, err
+	}
+	s, err := p.parseSource()
+	if err != nil {
+		return j, false, err
+	}
+	j.source = s
+	if err = p.expect("ON"); err != nil {
+		return j, false, err
+	}
+	if j.on[0], err = p.parseColumn(); err != nil {
+		return j, false, err
+	}
+	if op := p.tok(); op.kind != tokenOp || op.text != "=" {
+		return j, false, unsupported(op.pos, "join conditions other than an equality of two columns")
+	}
+	p.advance()
+	if j.on[1], err = p.parseColumn(); err != nil {
+		return j, false, err
+	}
+	if t := p.tok(); t.is("AND", "OR") {
+		return j, false, unsupported(t.pos, "join conditions other than an equality of two columns")
+	}
+	return j, true, nil
+}
+
+func (p *parser) parseCount(clause string) (*int64, error) {
+	t := p.advance()
+	n, ok := t.value.(int)
+	if t.kind != tokenNumber || !ok || n < 0 {
+		return nil, syntaxError(t.pos, "expected a non-negative integer after %s, got %s", clause, t)
+	}
+	count := int64(n)
+	return &count, nil
+}
+
+func (p *parser) parseColumn() (*column, error) {
+	t := p.tok()
+	if t.kind != tokenIdent || isKeyword(t) {
+		return nil, syntaxError(t.pos, "expected a column, got %s", t)
+	}
+	p.advance()
+	if p.tok().isPunct("(") {
+		return nil, unsupported(t.pos, "function %s", t.text)
+	}
+	if strings.HasPrefix(t.text, ".") || strings.HasSuffix(t.text, ".") || strings.Contains(t.text, "..") || strings.Contains(t.text, "$") {
+		return nil, syntaxError(t.pos, "invalid column %s", t)
+	}
+	return &column{path: t.text, pos: t.pos}, nil
+}
+
+var aggregateFuncs = map[string]struct{}{"COUNT": {}, "SUM": {}, "AVG": {}, "MIN": {}, "MAX": {}}
+
+// parseOperand parses a column or an aggregate function
+func (p *parser) parseOperand() (operand, error) {
+	t := p.tok()
+	if _, ok := aggregateFuncs[strings.ToUpper(t.text)]; !ok || t.kind != tokenIdent || t.quoted || !p.tokens[p.i+1].isPunct("(") {
+		col, err := p.parseColumn()
+		if err == nil {
+			err = p.checkExpression()
+		}
+		return operand{col: col}, err
+	}
+	p.advance()
+	p.advance()
+	agg := &aggregate{fn: strings.ToUpper(t.text), pos: t.pos}
+	switch arg := p.tok(); {
+	case arg.is("DISTINCT"):
+		return operand{}, unsupported(arg.pos, "%s(DISTINCT ...)", agg.fn)
+	case arg.isPunct("*"):
+		if agg.fn != "COUNT" {
+			return operand{}, syntaxError(arg.pos, "%s(*) is not valid, only COUNT(*)", agg.fn)
+		}
+		p.advance()
+	default:
+		col, err := p.parseColumn()
+		if err != nil {
+			return operand{}, err
+		}
+		agg.arg = col
+	}
+	if err := p.expectPunct(")"); err != nil {
+		return operand{}, err
+	}
+	if t := p.tok(); t.is("OVER") {
+		return operand{}, unsupported(t.pos, "window functions")
+	}
+	return operand{agg: agg}, p.checkExpression()
+}
+
+// checkExpression rejects the arithmetic after an operand
+func (p *parser) checkExpression() error {
+	if t := p.tok(); t.kind == tokenPunct && strings.Contains("+-/%*", t.text) {
+		return unsupported(t.pos, "arithmetic expressions")
+	}
+	return nil
+}
+
+// parseOr parses the disjunction of conjunctions
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses the conjunction of negations
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.accept("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{x: x}, nil
+	}
+	if p.tok().isPunct("(") {
+		if p.tokens[p.i+1].is("SELECT") {
+			return nil, unsupported(p.tok().pos, "subqueries")
+		}
+		p.advance()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expectPunct(")")
+	}
+	return p.parsePredicate()
+}
+
+var mirroredOps = map[string]string{"=": "=", "!=": "!=", "<>": "!=", "<": ">", "<=": ">=", ">": "<", ">=": "<="}
+
+func (p *parser) parsePredicate() (expr, error) {
+	// a literal on the left side of a comparison, e.g. 18 <= age
+	if t := p.tok(); isLiteral(t) {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		op := p.advance()
+		if op.kind != tokenOp {
+			return nil, syntaxError(op.pos, "expected a comparison operator, got %s", op)
+		}
+		if isLiteral(p.tok()) {
+			return nil, unsupported(t.pos, "comparisons of two literals")
+		}
+		o, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &compareExpr{operand: o, op: mirroredOps[op.text], value: value}, nil
+	}
+
+	o, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	t := p.tok()
+	switch {
+	case t.kind == tokenOp:
+		p.advance()
+		if next := p.tok(); next.kind == tokenIdent && !isKeyword(next) {
+			return nil, unsupported(next.pos, "comparisons of two columns")
+		}
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		op := t.text
+		if op == "<>" {
+			op = "!="
+		}
+		return &compareExpr{operand: o, op: op, value: value}, nil
+	case t.is("IS"):
+		p.advance()
+		not := p.accept("NOT")
+		return &nullExpr{operand: o, not: not}, p.expect("NULL")
+	}
+
+	not := p.accept("NOT")
+	switch t = p.tok(); {
+	case t.is("IN"):
+		p.advance()
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return &inExpr{operand: o, values: values, not: not}, nil
+	case t.is("LIKE", "ILIKE"):
+		p.advance()
+		pattern := p.advance()
+		if pattern.kind != tokenString {
+			return nil, syntaxError(pattern.pos, "expected a string after %s, got %s", strings.ToUpper(t.text), pattern)
+		}
+		return &likeExpr{operand: o, pattern: pattern.value.(string), insensitive: t.is("ILIKE"), not: not}, nil
+	case t.is("BETWEEN"):
+		p.advance()
+		low, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return &betweenExpr{operand: o, low: low, high: high, not: not}, nil
+	}
+	return nil, syntaxError(t.pos, "expected a predicate, got %s", t)
+}
+
+func (p *parser) parseList() ([]any, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	if t := p.tok(); t.is("SELECT") {
+		return nil, unsupported(t.pos, "subqueries")
+	}
+	var values []any
+	for {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if !p.tok().isPunct(",") {
+			break
+		}
+		p.advance()
+	}
+	return values, p.expectPunct(")")
+}
+
+func isLiteral(t token) bool {
+	return t.kind == tokenString || t.kind == tokenNumber || t.is("TRUE", "FALSE", "NULL") || t.isPunct("-")
+}
+
+func (p *parser) parseLiteral() (any, error) {
+	t := p.advance()
+	switch {
+	case t.kind == tokenString || t.kind == tokenNumber:
+		return t.value, nil
+	case t.isPunct("-") && p.tok().kind == tokenNumber:
+		switch n := p.advance().value.(type) {
+		case int:
+			return -n, nil
+		case float64:
+			return -n, nil
+		}
+	case t.is("TRUE", "FALSE"):
+		return t.is("TRUE"), nil
+	case t.is("NULL"):
+		return nil, nil
+	case t.kind == tokenIdent && p.tok().isPunct("("):
+		return nil, unsupported(t.pos, "function %s", t.text)
+	case t.isPunct("(") && p.tok().is("SELECT"):
+		return nil, unsupported(t.pos, "subqueries")
+	}
+	return nil, syntaxError(t.pos, "expected a literal, got %s", t)
+}
+
+func syntaxError(pos int, format string, args ...any) error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func unsupported(pos int, format string, args ...any) error {
+	return &Error{Pos: pos, Msg: "unsupported " + fmt.Sprintf(format, args...), Unsupported: true}
+}
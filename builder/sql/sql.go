@@ -0,0 +1,486 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/aggregation"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ErrUnsupported matches the errors of Translate for valid SQL that can't be translated, e.g. RIGHT JOIN or subqueries
+var ErrUnsupported = errors.New("mongox: unsupported SQL")
+
+// Error is the error of Translate, Pos is the byte offset of the error in the statement
+type Error struct {
+	Pos         int
+	Msg         string
+	Unsupported bool
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("mongox: sql error at position %d: %s", e.Pos, e.Msg)
+}
+
+func (e *Error) Is(target error) bool {
+	return target == ErrUnsupported && e.Unsupported
+}
+
+// Query is a translated statement, Pipeline runs on Collection, e.g. coll.Aggregator().Pipeline(q.Pipeline).Aggregate(ctx)
+type Query struct {
+	Collection string
+	Pipeline   mongo.Pipeline
+}
+
+// Translate translates a SELECT statement into an aggregation pipeline:
+//
+//	SELECT * | column [AS alias], COUNT(*) | COUNT | SUM | AVG | MIN | MAX(column) [AS alias], ...
+//	FROM collection [alias]
+//	[[INNER | LEFT] JOIN collection [alias] ON column = column] ...
+//	[WHERE condition] [GROUP BY column, ...] [HAVING condition]
+//	[ORDER BY column | aggregate | alias [ASC | DESC], ...] [LIMIT n] [OFFSET n]
+//
+// The conditions combine with AND, OR, NOT and parentheses the comparisons of a column with a literal
+// (=, !=, <>, <, <=, >, >=), [NOT] IN (...), [NOT] LIKE, [NOT] ILIKE, IS [NOT] NULL and [NOT] BETWEEN.
+// The columns of the joined collections are prefixed by their alias, the others are the fields of the documents,
+// nested fields are separated by dots. A JOIN is a $lookup followed by an $unwind of its alias, which keeps the
+// documents without match for a LEFT JOIN. The aggregates without alias are named count, or the lower case
+// function and the column, e.g. sum_amount.
+// The valid SQL that can't be translated fails with an *Error matching ErrUnsupported.
+func Translate(statement string) (*Query, error) {
+	stmt, err := parse(statement)
+	if err != nil {
+		return nil, err
+	}
+	t := &translator{stmt: stmt, aliases: map[string]bool{}}
+	pipeline, err := t.translate()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{Collection: stmt.from.name, Pipeline: pipeline}, nil
+}
+
+type translator struct {
+	stmt *statement
+	// aliases are the aliases of the joined collections
+	aliases map[string]bool
+	builder *aggregation.StageBuilder
+
+	// the group stage
+	keys         map[string]string
+	accumulators []bson.E
+	accNames     map[string]string
+}
+
+func (t *translator) translate() (mongo.Pipeline, error) {
+	t.builder = aggregation.NewStageBuilder()
+	for _, j := range t.stmt.joins {
+		if err := t.join(j); err != nil {
+			return nil, err
+		}
+	}
+	if t.stmt.where != nil {
+		filter, err := t.filter(t.stmt.where, t.whereField)
+		if err != nil {
+			return nil, err
+		}
+		t.builder.Match(filter)
+	}
+
+	grouped := len(t.stmt.groupBy) > 0 || t.stmt.having != nil
+	for _, item := range t.stmt.items {
+		grouped = grouped || item.agg != nil
+	}
+	for _, item := range t.stmt.orderBy {
+		grouped = grouped || item.agg != nil
+	}
+	var err error
+	if grouped {
+		err = t.group()
+	} else {
+		err = t.project()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t.builder.Build(), nil
+}
+
+func (t *translator) join(j join) error {
+	if j.alias == t.stmt.from.alias || t.aliases[j.alias] {
+		return syntaxError(j.pos, "duplicate alias %s", j.alias)
+	}
+	var local, foreign *column
+	for i, col := range j.on {
+		if prefix, _, _ := strings.Cut(col.path, "."); prefix == j.alias && strings.Contains(col.path, ".") {
+			foreign, local = col, j.on[1-i]
+			break
+		}
+	}
+	if foreign == nil {
+		return syntaxError(j.on[0].pos, "the ON clause must compare a column of %s", j.alias)
+	}
+	if prefix, _, _ := strings.Cut(local.path, "."); prefix == j.alias {
+		return syntaxError(local.pos, "the ON clause must compare a column of %s with another collection", j.alias)
+	}
+	t.aliases[j.alias] = true
+	t.builder.Lookup(j.name, j.alias, &aggregation.LookUpOptions{
+		LocalField:   t.path(local),
+		ForeignField: strings.TrimPrefix(foreign.path, j.alias+"."),
+	})
+	t.builder.Unwind("$"+j.alias, &aggregation.UnWindOptions{PreserveNullAndEmptyArrays: j.left})
+	return nil
+}
+
+// path returns the field of col, without the prefix of the collection in FROM
+func (t *translator) path(col *column) string {
+	for _, prefix := range []string{t.stmt.from.alias, t.stmt.from.name} {
+		if strings.HasPrefix(col.path, prefix+".") && !t.aliases[prefix] {
+			return strings.TrimPrefix(col.path, prefix+".")
+		}
+	}
+	return col.path
+}
+
+// resolve returns the operand of the select item aliased by o if any, so that ORDER BY and HAVING can use the aliases
+func (t *translator) resolve(o operand) operand {
+	if o.col == nil || strings.Contains(o.col.path, ".") {
+		return o
+	}
+	for _, item := range t.stmt.items {
+		if item.alias == o.col.path {
+			return item.operand
+		}
+	}
+	return o
+}
+
+func (t *translator) whereField(o operand) (string, error) {
+	if o.agg != nil {
+		return "", syntaxError(o.pos(), "aggregate functions are not allowed in WHERE, use HAVING")
+	}
+	return t.path(o.col), nil
+}
+
+// groupField returns the field of o after the group stage
+func (t *translator) groupField(o operand) (string, error) {
+	o = t.resolve(o)
+	if o.agg != nil {
+		return t.accumulator(o.agg, ""), nil
+	}
+	key, ok := t.keys[t.path(o.col)]
+	if !ok {
+		return "", syntaxError(o.pos(), "column %s must appear in GROUP BY or be used in an aggregate function", o.col.path)
+	}
+	return key, nil
+}
+
+func (t *translator) group() error {
+	if t.stmt.all {
+		return syntaxError(0, "SELECT * can't be used with GROUP BY or aggregate functions")
+	}
+	t.keys, t.accNames = map[string]string{}, map[string]string{}
+	var id any
+	if len(t.stmt.groupBy) == 1 {
+		path := t.path(t.stmt.groupBy[0])
+		id, t.keys[path] = "$"+path, "_id"
+	} else if len(t.stmt.groupBy) > 1 {
+		keys := bson.D{}
+		for _, col := range t.stmt.groupBy {
+			path := t.path(col)
+			name := strings.ReplaceAll(path, ".", "_")
+			keys = append(keys, bson.E{Key: name, Value: "$" + path})
+			t.keys[path] = "_id." + name
+		}
+		id = keys
+	}
+
+	// the selected aggregates come first to be named by their alias
+	project, names := bson.D{}, map[string]struct{}{}
+	for _, item := range t.stmt.items {
+		if item.agg != nil {
+			t.accumulator(item.agg, item.alias)
+		}
+	}
+	for _, item := range t.stmt.items {
+		field, err := t.groupField(item.operand)
+		if err != nil {
+			return err
+		}
+		name := item.alias
+		if name == "" && item.col != nil {
+			name = t.path(item.col)
+		} else if name == "" {
+			name = field
+		}
+		if _, ok := names[name]; ok {
+			return syntaxError(item.pos(), "duplicate output name %s", name)
+		}
+		names[name] = struct{}{}
+		if name == field {
+			project = append(project, bson.E{Key: name, Value: 1})
+		} else {
+			project = append(project, bson.E{Key: name, Value: "$" + field})
+		}
+	}
+	if _, ok := names["_id"]; !ok {
+		project = append(bson.D{{Key: "_id", Value: 0}}, project...)
+	}
+
+	var having bson.D
+	if t.stmt.having != nil {
+		var err error
+		if having, err = t.filter(t.stmt.having, t.groupField); err != nil {
+			return err
+		}
+	}
+	order, err := t.order(t.groupField)
+	if err != nil {
+		return err
+	}
+
+	t.builder.Group(id, t.accumulators...)
+	if having != nil {
+		t.builder.Match(having)
+	}
+	if order != nil {
+		t.builder.Sort(order)
+	}
+	t.paginate()
+	t.builder.Project(project)
+	return nil
+}
+
+// accumulator returns the name of the accumulator of agg, adding it to the group stage if needed
+func (t *translator) accumulator(agg *aggregate, alias string) string {
+	key := agg.fn + "(*)"
+	if agg.arg != nil {
+		key = agg.fn + "(" + t.path(agg.arg) + ")"
+	}
+	if name, ok := t.accNames[key]; ok {
+		return name
+	}
+	name := alias
+	if name == "" && agg.arg == nil {
+		name = "count"
+	} else if name == "" {
+		name = strings.ToLower(agg.fn) + "_" + strings.ReplaceAll(t.path(agg.arg), ".", "_")
+	}
+	// the names of the accumulators are unique, e.g. SUM(amount) AS count with COUNT(*), and differ from the _id of the group
+	for name == "_id" || t.accNameUsed(name) {
+		name = "_" + name
+	}
+	var value bson.D
+	switch field := ""; {
+	case agg.arg == nil:
+		value = aggregation.SumWithoutKey(1)
+	case agg.fn == "COUNT":
+		field = "$" + t.path(agg.arg)
+		value = aggregation.SumWithoutKey(aggregation.CondWithoutKey(aggregation.GtWithoutKey(field, nil), 1, 0))
+	default:
+		field = "$" + t.path(agg.arg)
+		switch agg.fn {
+		case "SUM":
+			value = aggregation.SumWithoutKey(field)
+		case "AVG":
+			value = aggregation.AvgWithoutKey(field)
+		case "MIN":
+			value = aggregation.MinWithoutKey(field)
+		case "MAX":
+			value = aggregation.MaxWithoutKey(field)
+		}
+	}
+	t.accNames[key] = name
+	t.accumulators = append(t.accumulators, bson.E{Key: name, Value: value})
+	return name
+}
+
+func (t *translator) accNameUsed(name string) bool {
+	for _, acc := range t.accumulators {
+		if acc.Key == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *translator) project() error {
+	order, err := t.order(func(o operand) (string, error) {
+		return t.path(t.resolve(o).col), nil
+	})
+	if err != nil {
+		return err
+	}
+	if order != nil {
+		t.builder.Sort(order)
+	}
+	t.paginate()
+	if t.stmt.all {
+		return nil
+	}
+
+	project, names := bson.D{}, map[string]struct{}{}
+	for _, item := range t.stmt.items {
+		path, name := t.path(item.col), item.alias
+		if name == "" {
+			name = path
+		}
+		if _, ok := names[name]; ok {
+			return syntaxError(item.pos(), "duplicate output name %s", name)
+		}
+		names[name] = struct{}{}
+		if name == path {
+			project = append(project, bson.E{Key: path, Value: 1})
+		} else {
+			project = append(project, bson.E{Key: name, Value: "$" + path})
+		}
+	}
+	if _, ok := names["_id"]; !ok {
+		project = append(bson.D{{Key: "_id", Value: 0}}, project...)
+	}
+	t.builder.Project(project)
+	return nil
+}
+
+func (t *translator) order(field func(o operand) (string, error)) (bson.D, error) {
+	if len(t.stmt.orderBy) == 0 {
+		return nil, nil
+	}
+	b := sort.NewBuilder()
+	for _, item := range t.stmt.orderBy {
+		key, err := field(item.operand)
+		if err != nil {
+			return nil, err
+		}
+		if item.desc {
+			b.Desc(key)
+		} else {
+			b.Asc(key)
+		}
+	}
+	return b.Build(), nil
+}
+
+func (t *translator) paginate() {
+	if t.stmt.offset != nil {
+		t.builder.Skip(*t.stmt.offset)
+	}
+	if t.stmt.limit != nil {
+		t.builder.Limit(*t.stmt.limit)
+	}
+}
+
+// filter translates e into a filter, field returns the field of an operand
+func (t *translator) filter(e expr, field func(o operand) (string, error)) (bson.D, error) {
+	switch e := e.(type) {
+	case *logicalExpr:
+		left, err := t.filter(e.left, field)
+		if err != nil {
+			return nil, err
+		}
+		right, err := t.filter(e.right, field)
+		if err != nil {
+			return nil, err
+		}
+		if e.and {
+			return query.Merge(left, right), nil
+		}
+		return query.MergeOr(left, right), nil
+	case *notExpr:
+		x, err := t.filter(e.x, field)
+		if err != nil {
+			return nil, err
+		}
+		return query.Nor(x), nil
+	case *compareExpr:
+		key, err := field(e.operand)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]func(string, any) bson.D{
+			"=": query.Eq, "!=": query.Ne, "<": query.Lt, "<=": query.Lte, ">": query.Gt, ">=": query.Gte,
+		}[e.op](key, e.value), nil
+	case *inExpr:
+		key, err := field(e.operand)
+		if err != nil {
+			return nil, err
+		}
+		if e.not {
+			return query.NIn(key, e.values...), nil
+		}
+		return query.In(key, e.values...), nil
+	case *likeExpr:
+		key, err := field(e.operand)
+		if err != nil {
+			return nil, err
+		}
+		pattern, options := likeToRegex(e.pattern), ""
+		if e.insensitive {
+			options = "i"
+		}
+		if e.not {
+			return bson.D{{Key: key, Value: bson.D{{Key: query.NotOp, Value: bson.Regex{Pattern: pattern, Options: options}}}}}, nil
+		}
+		if options != "" {
+			return query.RegexOptions(key, pattern, options), nil
+		}
+		return query.Regex(key, pattern), nil
+	case *nullExpr:
+		key, err := field(e.operand)
+		if err != nil {
+			return nil, err
+		}
+		if e.not {
+			return query.Ne(key, nil), nil
+		}
+		return query.Eq(key, nil), nil
+	case *betweenExpr:
+		key, err := field(e.operand)
+		if err != nil {
+			return nil, err
+		}
+		if e.not {
+			return query.MergeOr(query.Lt(key, e.low), query.Gt(key, e.high)), nil
+		}
+		return bson.D{{Key: key, Value: bson.D{{Key: query.GteOp, Value: e.low}, {Key: query.LteOp, Value: e.high}}}}, nil
+	}
+	return nil, fmt.Errorf("mongox: unexpected expression %T", e)
+}
+
+// likeToRegex converts a LIKE pattern into an anchored regular expression, % matches any string and _ any character
+func likeToRegex(pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return strings.TrimPrefix(strings.TrimSuffix(sb.String(), ".*$"), "^.*")
+}
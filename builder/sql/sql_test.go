@@ -0,0 +1,244 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/aggregation"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestTranslate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		statement      string
+		wantCollection string
+		want           mongo.Pipeline
+	}{
+		{
+			name:           "select all",
+			statement:      "SELECT * FROM users;",
+			wantCollection: "users",
+			want:           mongo.Pipeline{},
+		},
+		{
+			name:           "where, order by, limit and offset",
+			statement:      "select name, age AS years from users u where u.age >= 18 and (status = 'active' or name like 'jo%') order by years desc, name limit 10 offset 20",
+			wantCollection: "users",
+			want: aggregation.NewStageBuilder().
+				Match(bson.D{{Key: "age", Value: bson.D{{Key: "$gte", Value: 18}}}, {Key: "$or", Value: []any{query.Eq("status", "active"), query.Regex("name", "^jo")}}}).
+				Sort(bson.D{{Key: "age", Value: -1}, {Key: "name", Value: 1}}).
+				Skip(20).Limit(10).
+				Project(bson.D{{Key: "_id", Value: 0}, {Key: "name", Value: 1}, {Key: "years", Value: "$age"}}).
+				Build(),
+		},
+		{
+			name:           "selected _id",
+			statement:      "SELECT _id, `profile.city` FROM users",
+			wantCollection: "users",
+			want:           aggregation.NewStageBuilder().Project(bson.D{{Key: "_id", Value: 1}, {Key: "profile.city", Value: 1}}).Build(),
+		},
+		{
+			name:           "predicates",
+			statement:      "SELECT * FROM users WHERE 18 < age AND score BETWEEN 1 AND 5.5 AND name NOT LIKE '%a_b%' AND email ILIKE '%@EXAMPLE.COM' AND role NOT IN ('admin', 'root') AND deleted_at IS NULL AND city IS NOT NULL AND active != FALSE",
+			wantCollection: "users",
+			want: aggregation.NewStageBuilder().Match(bson.D{
+				{Key: "age", Value: bson.D{{Key: "$gt", Value: 18}}},
+				{Key: "score", Value: bson.D{{Key: "$gte", Value: 1}, {Key: "$lte", Value: 5.5}}},
+				{Key: "name", Value: bson.D{{Key: "$not", Value: bson.Regex{Pattern: "a.b"}}}},
+				{Key: "email", Value: bson.D{{Key: "$regex", Value: "@EXAMPLE\\.COM$"}, {Key: "$options", Value: "i"}}},
+				{Key: "role", Value: bson.D{{Key: "$nin", Value: []any{"admin", "root"}}}},
+				{Key: "deleted_at", Value: bson.D{{Key: "$eq", Value: nil}}},
+				{Key: "city", Value: bson.D{{Key: "$ne", Value: nil}}},
+				{Key: "active", Value: bson.D{{Key: "$ne", Value: false}}},
+			}).Build(),
+		},
+		{
+			name:           "not and not between",
+			statement:      "SELECT * FROM users WHERE NOT (age IN (1, 2) OR name = 'x''y') AND age NOT BETWEEN -1 AND 10",
+			wantCollection: "users",
+			want: aggregation.NewStageBuilder().Match(bson.D{
+				{Key: "$nor", Value: []any{query.Or(query.In[any]("age", 1, 2), query.Eq("name", "x'y"))}},
+				{Key: "$or", Value: []any{query.Lt("age", -1), query.Gt("age", 10)}},
+			}).Build(),
+		},
+		{
+			name:           "group by",
+			statement:      "SELECT city, COUNT(*), AVG(age) AS avg_age FROM users WHERE age > 0 GROUP BY city HAVING COUNT(*) > 2 ORDER BY avg_age DESC LIMIT 5",
+			wantCollection: "users",
+			want: aggregation.NewStageBuilder().
+				Match(query.Gt("age", 0)).
+				Group("$city", bson.E{Key: "count", Value: aggregation.SumWithoutKey(1)}, bson.E{Key: "avg_age", Value: aggregation.AvgWithoutKey("$age")}).
+				Match(query.Gt("count", 2)).
+				Sort(bson.D{{Key: "avg_age", Value: -1}}).
+				Limit(5).
+				Project(bson.D{{Key: "_id", Value: 0}, {Key: "city", Value: "$_id"}, {Key: "count", Value: 1}, {Key: "avg_age", Value: 1}}).
+				Build(),
+		},
+		{
+			name:           "group by several columns",
+			statement:      "SELECT country, address.city AS city, SUM(amount), MAX(amount) top FROM orders GROUP BY country, address.city HAVING MIN(amount) >= 10 AND country <> 'fr' ORDER BY SUM(amount)",
+			wantCollection: "orders",
+			want: aggregation.NewStageBuilder().
+				Group(bson.D{{Key: "country", Value: "$country"}, {Key: "address_city", Value: "$address.city"}},
+					bson.E{Key: "sum_amount", Value: aggregation.SumWithoutKey("$amount")},
+					bson.E{Key: "top", Value: aggregation.MaxWithoutKey("$amount")},
+					bson.E{Key: "min_amount", Value: aggregation.MinWithoutKey("$amount")},
+				).
+				Match(bson.D{{Key: "min_amount", Value: bson.D{{Key: "$gte", Value: 10}}}, {Key: "_id.country", Value: bson.D{{Key: "$ne", Value: "fr"}}}}).
+				Sort(bson.D{{Key: "sum_amount", Value: 1}}).
+				Project(bson.D{{Key: "_id", Value: 0}, {Key: "country", Value: "$_id.country"}, {Key: "city", Value: "$_id.address_city"}, {Key: "sum_amount", Value: 1}, {Key: "top", Value: 1}}).
+				Build(),
+		},
+		{
+			name:           "aggregates without group by",
+			statement:      "SELECT COUNT(email) AS emails, SUM(amount) AS count, COUNT(*) FROM users",
+			wantCollection: "users",
+			want: aggregation.NewStageBuilder().
+				Group(nil,
+					bson.E{Key: "emails", Value: aggregation.SumWithoutKey(aggregation.CondWithoutKey(aggregation.GtWithoutKey("$email", nil), 1, 0))},
+					bson.E{Key: "count", Value: aggregation.SumWithoutKey("$amount")},
+					bson.E{Key: "_count", Value: aggregation.SumWithoutKey(1)},
+				).
+				Project(bson.D{{Key: "_id", Value: 0}, {Key: "emails", Value: 1}, {Key: "count", Value: 1}, {Key: "_count", Value: 1}}).
+				Build(),
+		},
+		{
+			name:           "group by _id",
+			statement:      "SELECT _id, COUNT(*) FROM c GROUP BY _id",
+			wantCollection: "c",
+			want: aggregation.NewStageBuilder().
+				Group("$_id", bson.E{Key: "count", Value: aggregation.SumWithoutKey(1)}).
+				Project(bson.D{{Key: "_id", Value: 1}, {Key: "count", Value: 1}}).
+				Build(),
+		},
+		{
+			name:           "aggregate named _id",
+			statement:      "SELECT COUNT(*) AS _id FROM c",
+			wantCollection: "c",
+			want: aggregation.NewStageBuilder().
+				Group(nil, bson.E{Key: "__id", Value: aggregation.SumWithoutKey(1)}).
+				Project(bson.D{{Key: "_id", Value: "$__id"}}).
+				Build(),
+		},
+		{
+			name:           "join",
+			statement:      "SELECT u.name, o.amount AS amount FROM users u JOIN orders o ON u._id = o.user_id LEFT OUTER JOIN products AS p ON p.sku = o.sku WHERE o.amount > 100 AND p.name IS NOT NULL",
+			wantCollection: "users",
+			want: aggregation.NewStageBuilder().
+				Lookup("orders", "o", &aggregation.LookUpOptions{LocalField: "_id", ForeignField: "user_id"}).
+				Unwind("$o", &aggregation.UnWindOptions{}).
+				Lookup("products", "p", &aggregation.LookUpOptions{LocalField: "o.sku", ForeignField: "sku"}).
+				Unwind("$p", &aggregation.UnWindOptions{PreserveNullAndEmptyArrays: true}).
+				Match(bson.D{{Key: "o.amount", Value: bson.D{{Key: "$gt", Value: 100}}}, {Key: "p.name", Value: bson.D{{Key: "$ne", Value: nil}}}}).
+				Project(bson.D{{Key: "_id", Value: 0}, {Key: "name", Value: 1}, {Key: "amount", Value: "$o.amount"}}).
+				Build(),
+		},
+		{
+			name:           "join and group by",
+			statement:      "SELECT users.name, SUM(o.amount) AS total FROM users INNER JOIN orders o ON o.user_id = users._id GROUP BY users.name ORDER BY total DESC",
+			wantCollection: "users",
+			want: aggregation.NewStageBuilder().
+				Lookup("orders", "o", &aggregation.LookUpOptions{LocalField: "_id", ForeignField: "user_id"}).
+				Unwind("$o", &aggregation.UnWindOptions{}).
+				Group("$name", bson.E{Key: "total", Value: aggregation.SumWithoutKey("$o.amount")}).
+				Sort(bson.D{{Key: "total", Value: -1}}).
+				Project(bson.D{{Key: "_id", Value: 0}, {Key: "name", Value: "$_id"}, {Key: "total", Value: 1}}).
+				Build(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Translate(tc.statement)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCollection, got.Collection)
+			assert.Equal(t, tc.want, got.Pipeline)
+		})
+	}
+}
+
+func TestTranslate_Error(t *testing.T) {
+	testCases := []struct {
+		name            string
+		statement       string
+		wantPos         int
+		wantMsg         string
+		wantUnsupported bool
+	}{
+		{name: "empty", statement: "", wantPos: 0, wantMsg: "expected SELECT, got end of statement"},
+		{name: "missing from", statement: "SELECT a", wantPos: 8, wantMsg: "expected FROM, got end of statement"},
+		{name: "trailing tokens", statement: "SELECT a FROM t WHERE a = 1 b", wantPos: 28, wantMsg: `unexpected "b"`},
+		{name: "unterminated string", statement: "SELECT a FROM t WHERE a = 'x", wantPos: 26, wantMsg: "unterminated '"},
+		{name: "bad character", statement: "SELECT a FROM t WHERE a = 1 & b", wantPos: 28, wantMsg: `unexpected '&'`},
+		{name: "limit", statement: "SELECT a FROM t LIMIT -1", wantPos: 22, wantMsg: `expected a non-negative integer after LIMIT, got "-"`},
+		{name: "sum star", statement: "SELECT SUM(*) FROM t", wantPos: 11, wantMsg: "SUM(*) is not valid, only COUNT(*)"},
+		{name: "aggregate in where", statement: "SELECT a FROM t WHERE COUNT(*) > 1", wantPos: 22, wantMsg: "aggregate functions are not allowed in WHERE, use HAVING"},
+		{name: "column not grouped", statement: "SELECT a, b FROM t GROUP BY a", wantPos: 10, wantMsg: "column b must appear in GROUP BY or be used in an aggregate function"},
+		{name: "select all grouped", statement: "SELECT * FROM t GROUP BY a", wantPos: 0, wantMsg: "SELECT * can't be used with GROUP BY or aggregate functions"},
+		{name: "on clause", statement: "SELECT a FROM t JOIN u ON t.a = t.b", wantPos: 26, wantMsg: "the ON clause must compare a column of u"},
+		{name: "duplicate alias", statement: "SELECT a FROM t JOIN u t ON t.a = a", wantPos: 16, wantMsg: "duplicate alias t"},
+		{name: "duplicate output name", statement: "SELECT name, name FROM users", wantPos: 13, wantMsg: "duplicate output name name"},
+		{name: "duplicate output name grouped", statement: "SELECT a AS n, COUNT(*) AS n FROM t GROUP BY a", wantPos: 15, wantMsg: "duplicate output name n"},
+		{name: "distinct", statement: "SELECT DISTINCT a FROM t", wantPos: 7, wantMsg: "unsupported SELECT DISTINCT", wantUnsupported: true},
+		{name: "right join", statement: "SELECT a FROM t RIGHT JOIN u ON u.a = t.a", wantPos: 16, wantMsg: "unsupported RIGHT JOIN", wantUnsupported: true},
+		{name: "join condition", statement: "SELECT a FROM t JOIN u ON u.a = t.a AND u.b = 1", wantPos: 36, wantMsg: "unsupported join conditions other than an equality of two columns", wantUnsupported: true},
+		{name: "subquery", statement: "SELECT a FROM t WHERE a IN (SELECT b FROM u)", wantPos: 28, wantMsg: "unsupported subqueries", wantUnsupported: true},
+		{name: "scalar subquery", statement: "SELECT a FROM t WHERE a > (SELECT 1)", wantPos: 26, wantMsg: "unsupported subqueries", wantUnsupported: true},
+		{name: "from subquery", statement: "SELECT a FROM (SELECT a FROM t)", wantPos: 14, wantMsg: "unsupported subqueries", wantUnsupported: true},
+		{name: "union", statement: "SELECT a FROM t UNION SELECT a FROM u", wantPos: 16, wantMsg: "unsupported UNION", wantUnsupported: true},
+		{name: "function", statement: "SELECT UPPER(a) FROM t", wantPos: 7, wantMsg: "unsupported function UPPER", wantUnsupported: true},
+		{name: "arithmetic", statement: "SELECT a + 1 FROM t", wantPos: 9, wantMsg: "unsupported arithmetic expressions", wantUnsupported: true},
+		{name: "count distinct", statement: "SELECT COUNT(DISTINCT a) FROM t", wantPos: 13, wantMsg: "unsupported COUNT(DISTINCT ...)", wantUnsupported: true},
+		{name: "window function", statement: "SELECT COUNT(*) OVER (PARTITION BY a) FROM t", wantPos: 16, wantMsg: "unsupported window functions", wantUnsupported: true},
+		{name: "column comparison", statement: "SELECT a FROM t WHERE a = b", wantPos: 26, wantMsg: "unsupported comparisons of two columns", wantUnsupported: true},
+		{name: "qualified star", statement: "SELECT t.* FROM t", wantPos: 7, wantMsg: "unsupported t.* in the select list", wantUnsupported: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Translate(tc.statement)
+			var sqlErr *Error
+			require.True(t, errors.As(err, &sqlErr), "%v", err)
+			assert.Equal(t, tc.wantPos, sqlErr.Pos)
+			assert.Equal(t, tc.wantMsg, sqlErr.Msg)
+			assert.Equal(t, tc.wantUnsupported, errors.Is(err, ErrUnsupported))
+		})
+	}
+}
+
+func TestLikeToRegex(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: "abc", want: "^abc$"},
+		{pattern: "ab%", want: "^ab"},
+		{pattern: "%ab", want: "ab$"},
+		{pattern: "%a_b%", want: "a.b"},
+		{pattern: "%", want: "^"},
+		{pattern: "a.b(c)", want: "^a\\.b\\(c\\)$"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			assert.Equal(t, tc.want, likeToRegex(tc.pattern))
+		})
+	}
+}
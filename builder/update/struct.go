@@ -0,0 +1,154 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// StructOptions controls how FromStruct converts a struct into an update document
+type StructOptions struct {
+	// OmitZero skips the fields with zero values
+	OmitZero bool
+	// UnsetNil removes the fields whose pointers are nil with $unset instead of setting them to null
+	UnsetNil bool
+	// Allow restricts the update to the listed (dot) paths and the fields nested in them
+	Allow []string
+	// Deny excludes the listed (dot) paths and the fields nested in them
+	Deny []string
+}
+
+// FromStruct converts v, a struct or a pointer to a struct, into an update document.
+//
+//   - the fields are assigned by $set, nested structs are flattened into dot paths, e.g. {"address.city": "x"}
+//   - the keys are the mongo field names parsed by field.ParseFields
+//   - _id, autoID and autoCreateTime fields are skipped, zero autoUpdateTime fields are left to the update hooks
+//   - nil pointers are set to null, or removed by $unset when opts.UnsetNil is true
+//
+// The result is a bson.M so that the hooks of Updater can add their own fields to $set.
+func FromStruct(v any, opts *StructOptions) bson.M {
+	if opts == nil {
+		opts = &StructOptions{}
+	}
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return bson.M{}
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return bson.M{}
+	}
+
+	c := &structConverter{opts: opts, set: bson.M{}, unset: bson.M{}}
+	c.convert(value, field.ParseFields(reflect.New(value.Type()).Interface()), "")
+
+	updates := bson.M{}
+	if len(c.set) > 0 {
+		updates[SetOp] = c.set
+	}
+	if len(c.unset) > 0 {
+		updates[UnsetOp] = c.unset
+	}
+	return updates
+}
+
+type structConverter struct {
+	opts  *StructOptions
+	set   bson.M
+	unset bson.M
+}
+
+func (c *structConverter) convert(v reflect.Value, fields []*field.Filed, prefix string) {
+	for idx, fd := range fields {
+		if idx >= v.NumField() || !v.Type().Field(idx).IsExported() {
+			continue
+		}
+		value := v.Field(idx)
+		if fd.InlinedFields != nil {
+			c.convert(value, fd.InlinedFields, prefix)
+			continue
+		}
+		if fd.MongoField == "-" || fd.MongoField == "_id" || fd.AutoID || fd.AutoCreateTime != 0 {
+			continue
+		}
+		if fd.AutoUpdateTime != 0 && value.IsZero() {
+			continue
+		}
+		path := fd.MongoField
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		if c.denied(path) || !c.allowed(path, false) {
+			continue
+		}
+
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				switch {
+				case !c.allowed(path, true):
+				case c.opts.UnsetNil:
+					c.unset[path] = ""
+				case !c.opts.OmitZero:
+					c.set[path] = nil
+				}
+				continue
+			}
+			value = value.Elem()
+		}
+		if utils.IsDocument(value.Type()) {
+			c.convert(value, field.ParseFields(reflect.New(value.Type()).Interface()), path)
+			continue
+		}
+		if !c.allowed(path, true) || (c.opts.OmitZero && value.IsZero()) {
+			continue
+		}
+		c.set[path] = value.Interface()
+	}
+}
+
+func (c *structConverter) denied(path string) bool {
+	for _, deny := range c.opts.Deny {
+		if isSubPath(path, deny) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed reports whether path can be assigned, or, when exact is false, whether a path nested in it may be
+func (c *structConverter) allowed(path string, exact bool) bool {
+	if len(c.opts.Allow) == 0 {
+		return true
+	}
+	for _, allow := range c.opts.Allow {
+		if isSubPath(path, allow) || (!exact && isSubPath(allow, path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubPath reports whether path is parent or nested in parent
+func isSubPath(path, parent string) bool {
+	return path == parent || strings.HasPrefix(path, parent+".")
+}
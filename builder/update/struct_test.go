@@ -0,0 +1,129 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type StructBase struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	CreatedAt time.Time     `bson:"created_at"`
+	UpdatedAt time.Time     `bson:"updated_at"`
+}
+
+type structAddress struct {
+	City    string `bson:"city"`
+	ZipCode string `bson:"zip_code"`
+}
+
+type structUser struct {
+	StructBase `bson:",inline"`
+	UID        string         `bson:"uid" mongox:"autoID"`
+	Name       string         `bson:"name"`
+	Age        int            `bson:"age"`
+	Nickname   *string        `bson:"nickname"`
+	Address    structAddress  `bson:"address"`
+	Company    *structAddress `bson:"company"`
+	Ignored    string         `bson:"-"`
+}
+
+func TestFromStruct(t *testing.T) {
+	updatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	nickname := "chen"
+	user := &structUser{
+		StructBase: StructBase{ID: bson.NewObjectID(), CreatedAt: updatedAt},
+		UID:        "uid",
+		Name:       "chenmingyong",
+		Address:    structAddress{City: "shenzhen"},
+	}
+
+	testCases := []struct {
+		name string
+		v    any
+		opts *StructOptions
+		want bson.M
+	}{
+		{
+			name: "nil",
+			v:    nil,
+			want: bson.M{},
+		},
+		{
+			name: "not a struct",
+			v:    "name",
+			want: bson.M{},
+		},
+		{
+			name: "default options",
+			v:    user,
+			want: bson.M{"$set": bson.M{
+				"name":             "chenmingyong",
+				"age":              0,
+				"nickname":         nil,
+				"address.city":     "shenzhen",
+				"address.zip_code": "",
+				"company":          nil,
+			}},
+		},
+		{
+			name: "struct value and explicit updatedAt",
+			v:    structUser{StructBase: StructBase{UpdatedAt: updatedAt}, Nickname: &nickname, Company: &structAddress{City: "guangzhou"}},
+			opts: &StructOptions{OmitZero: true},
+			want: bson.M{"$set": bson.M{
+				"updated_at":   updatedAt,
+				"nickname":     "chen",
+				"company.city": "guangzhou",
+			}},
+		},
+		{
+			name: "omit zero and unset nil",
+			v:    user,
+			opts: &StructOptions{OmitZero: true, UnsetNil: true},
+			want: bson.M{
+				"$set":   bson.M{"name": "chenmingyong", "address.city": "shenzhen"},
+				"$unset": bson.M{"nickname": "", "company": ""},
+			},
+		},
+		{
+			name: "allow list",
+			v:    user,
+			opts: &StructOptions{Allow: []string{"name", "address.city", "company"}},
+			want: bson.M{"$set": bson.M{
+				"name":         "chenmingyong",
+				"address.city": "shenzhen",
+				"company":      nil,
+			}},
+		},
+		{
+			name: "deny list",
+			v:    user,
+			opts: &StructOptions{Deny: []string{"age", "address", "nickname"}, UnsetNil: true},
+			want: bson.M{
+				"$set":   bson.M{"name": "chenmingyong"},
+				"$unset": bson.M{"company": ""},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, FromStruct(tc.v, tc.opts))
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/chenmingyong0423/go-mongox/v2/internal/hook/field"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/hook/validator"
 
 	"github.com/chenmingyong0423/go-mongox/v2/operation"
 )
@@ -33,6 +34,12 @@ func InitializeCallbacks() *Callback {
 					return field.Execute(ctx, opCtx, operation.OpTypeBeforeInsert, opts...)
 				},
 			},
+			{
+				name: "mongox:validator",
+				fn: func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+					return validator.Execute(ctx, opCtx, operation.OpTypeBeforeInsert, opts...)
+				},
+			},
 		},
 		afterInsert: make([]callbackHandler, 0),
 		beforeUpdate: []callbackHandler{
@@ -42,6 +49,12 @@ func InitializeCallbacks() *Callback {
 					return field.Execute(ctx, opCtx, operation.OpTypeBeforeUpdate, opts...)
 				},
 			},
+			{
+				name: "mongox:validator",
+				fn: func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+					return validator.Execute(ctx, opCtx, operation.OpTypeBeforeUpdate, opts...)
+				},
+			},
 		},
 		afterUpdate:  make([]callbackHandler, 0),
 		beforeDelete: make([]callbackHandler, 0),
@@ -53,6 +66,12 @@ func InitializeCallbacks() *Callback {
 					return field.Execute(ctx, opCtx, operation.OpTypeBeforeUpsert, opts...)
 				},
 			},
+			{
+				name: "mongox:validator",
+				fn: func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+					return validator.Execute(ctx, opCtx, operation.OpTypeBeforeUpsert, opts...)
+				},
+			},
 		},
 		afterUpsert: make([]callbackHandler, 0),
 		beforeFind:  make([]callbackHandler, 0),
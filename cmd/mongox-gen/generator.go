@@ -0,0 +1,284 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// packages whose struct types are stored as a single bson value
+var leafPackages = map[string]bool{
+	"time":                                true,
+	"go.mongodb.org/mongo-driver/v2/bson": true,
+}
+
+type fieldNode struct {
+	goName string
+	path   string
+	// typeName is the name of the generated descriptor type of an embedded document, empty for leaves
+	typeName string
+	children []*fieldNode
+}
+
+type descriptor struct {
+	typeName string
+	fields   []*fieldNode
+}
+
+// generate type-checks the package in dir and returns the formatted source of the descriptors of typeNames.
+// The file at outputPath is excluded from the package so that a stale generated file is never read.
+func generate(dir string, typeNames []string, outputPath string) ([]byte, error) {
+	pkg, err := loadPackage(dir, outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptors := make([]*descriptor, 0, len(typeNames))
+	for _, typeName := range typeNames {
+		typeName = strings.TrimSpace(typeName)
+		obj := pkg.Scope().Lookup(typeName)
+		if obj == nil {
+			return nil, fmt.Errorf("type %s not found in package %s", typeName, pkg.Name())
+		}
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			return nil, fmt.Errorf("type %s is not a struct", typeName)
+		}
+		named, _ := obj.Type().(*types.Named)
+		rootTypeName := lowerFirst(typeName) + "Fields"
+		fields, err := buildFields(st, "", rootTypeName, map[*types.Named]bool{named: true})
+		if err != nil {
+			return nil, fmt.Errorf("type %s: %w", typeName, err)
+		}
+		descriptors = append(descriptors, &descriptor{typeName: typeName, fields: fields})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by mongox-gen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", pkg.Name())
+	for _, d := range descriptors {
+		rootTypeName := lowerFirst(d.typeName) + "Fields"
+		fmt.Fprintf(&buf, "\n// %sFields holds the bson paths of the fields of %s\n", d.typeName, d.typeName)
+		fmt.Fprintf(&buf, "var %sFields = %s{\n", d.typeName, rootTypeName)
+		writeValues(&buf, d.fields)
+		buf.WriteString("}\n")
+		writeTypes(&buf, rootTypeName, "", d.fields)
+	}
+	return format.Source(buf.Bytes())
+}
+
+func loadPackage(dir, outputPath string) (*types.Package, error) {
+	buildPkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(buildPkg.GoFiles))
+	for _, name := range buildPkg.GoFiles {
+		path := filepath.Join(dir, name)
+		if outputPath != "" && filepath.Clean(path) == filepath.Clean(outputPath) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		// errors in unrelated declarations must not prevent the generation
+		Error: func(error) {},
+	}
+	pkg, _ := conf.Check(buildPkg.ImportPath, fset, files, nil)
+	if pkg == nil {
+		return nil, fmt.Errorf("failed to load the package in %s", dir)
+	}
+	return pkg, nil
+}
+
+func buildFields(st *types.Struct, prefix, typeName string, visiting map[*types.Named]bool) ([]*fieldNode, error) {
+	nodes := make([]*fieldNode, 0, st.NumFields())
+	names := make(map[string]bool, st.NumFields())
+	add := func(node *fieldNode) error {
+		if node.goName == "String" && typeName != "" && prefix != "" {
+			return fmt.Errorf("field String of %s conflicts with the generated String method", prefix)
+		}
+		// like Go, the shallower field wins over the inlined one
+		if !names[node.goName] {
+			names[node.goName] = true
+			nodes = append(nodes, node)
+		}
+		return nil
+	}
+
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Exported() {
+			continue
+		}
+		name, inline, skip := parseBsonTag(f.Name(), st.Tag(i))
+		if skip {
+			continue
+		}
+		inner, named := documentStruct(f.Type())
+		if inline {
+			if inner == nil {
+				// inlined maps have no static fields
+				continue
+			}
+			children, err := buildFields(inner, prefix, typeName, visiting)
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range children {
+				if err = add(child); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		node := &fieldNode{goName: f.Name(), path: joinPath(prefix, name)}
+		if inner != nil && (named == nil || !visiting[named]) {
+			node.typeName = typeName + f.Name()
+			visiting[named] = true
+			children, err := buildFields(inner, node.path, node.typeName, visiting)
+			delete(visiting, named)
+			if err != nil {
+				return nil, err
+			}
+			node.children = children
+		}
+		if err := add(node); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// documentStruct returns the struct stored as an embedded document, looking through pointers, slices and arrays
+func documentStruct(t types.Type) (*types.Struct, *types.Named) {
+	for {
+		switch v := t.(type) {
+		case *types.Pointer:
+			t = v.Elem()
+			continue
+		case *types.Slice:
+			t = v.Elem()
+			continue
+		case *types.Array:
+			t = v.Elem()
+			continue
+		}
+		break
+	}
+	named, _ := t.(*types.Named)
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil
+	}
+	if named != nil {
+		if named.Obj().Pkg() != nil && leafPackages[named.Obj().Pkg().Path()] {
+			return nil, nil
+		}
+		// types with a custom bson encoding are stored as a single value
+		if obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, named.Obj().Pkg(), "MarshalBSONValue"); obj != nil {
+			return nil, nil
+		}
+	}
+	return st, named
+}
+
+// parseBsonTag mirrors the struct tag parser of the bson package
+func parseBsonTag(fieldName, tag string) (name string, inline, skip bool) {
+	name = strings.ToLower(fieldName)
+	bsonTag, ok := reflect.StructTag(tag).Lookup("bson")
+	if !ok && !strings.Contains(tag, ":") && len(tag) > 0 {
+		bsonTag = tag
+	}
+	if bsonTag == "-" {
+		return "", false, true
+	}
+	for idx, s := range strings.Split(bsonTag, ",") {
+		if idx == 0 && s != "" {
+			name = s
+		}
+		if s == "inline" {
+			inline = true
+		}
+	}
+	return name, inline, false
+}
+
+func writeValues(buf *bytes.Buffer, nodes []*fieldNode) {
+	for _, node := range nodes {
+		if node.typeName == "" {
+			fmt.Fprintf(buf, "%s: %q,\n", node.goName, node.path)
+			continue
+		}
+		fmt.Fprintf(buf, "%s: %s{\npath: %q,\n", node.goName, node.typeName, node.path)
+		writeValues(buf, node.children)
+		buf.WriteString("},\n")
+	}
+}
+
+func writeTypes(buf *bytes.Buffer, typeName, path string, nodes []*fieldNode) {
+	fmt.Fprintf(buf, "\ntype %s struct {\n", typeName)
+	if path != "" {
+		buf.WriteString("path string\n")
+	}
+	for _, node := range nodes {
+		if node.typeName == "" {
+			fmt.Fprintf(buf, "%s string\n", node.goName)
+		} else {
+			fmt.Fprintf(buf, "%s %s\n", node.goName, node.typeName)
+		}
+	}
+	buf.WriteString("}\n")
+	if path != "" {
+		fmt.Fprintf(buf, "\n// String returns the path of the embedded document\nfunc (f %s) String() string {\nreturn f.path\n}\n", typeName)
+	}
+	for _, node := range nodes {
+		if node.typeName != "" {
+			writeTypes(buf, node.typeName, node.path, node.children)
+		}
+	}
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_generate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		typeNames []string
+		golden    string
+		wantErr   string
+	}{
+		{
+			name:      "type not found",
+			typeNames: []string{"Unknown"},
+			wantErr:   "type Unknown not found in package models",
+		},
+		{
+			name:      "field String in embedded document",
+			typeNames: []string{"Invalid"},
+			wantErr:   "type Invalid: field String of meta conflicts with the generated String method",
+		},
+		{
+			name:      "inline, nested, slices and recursive types",
+			typeNames: []string{"User", "Order"},
+			golden:    "testdata/models_fields_gen.golden",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join("testdata", "models")
+			got, err := generate(dir, tc.typeNames, filepath.Join(dir, "models_fields_gen.go"))
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			want, err := os.ReadFile(tc.golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func Test_parseBsonTag(t *testing.T) {
+	testCases := []struct {
+		name       string
+		fieldName  string
+		tag        string
+		wantName   string
+		wantInline bool
+		wantSkip   bool
+	}{
+		{name: "no tag", fieldName: "ZipCode", wantName: "zipcode"},
+		{name: "bson tag", fieldName: "ZipCode", tag: `bson:"zip_code,omitempty"`, wantName: "zip_code"},
+		{name: "empty name", fieldName: "ZipCode", tag: `bson:",omitempty"`, wantName: "zipcode"},
+		{name: "inline", fieldName: "Base", tag: `bson:",inline"`, wantName: "base", wantInline: true},
+		{name: "skip", fieldName: "ZipCode", tag: `bson:"-"`, wantSkip: true},
+		{name: "legacy tag", fieldName: "ZipCode", tag: `zip`, wantName: "zip"},
+		{name: "other tags only", fieldName: "ZipCode", tag: `json:"zip"`, wantName: "zipcode"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, inline, skip := parseBsonTag(tc.fieldName, tc.tag)
+			assert.Equal(t, tc.wantSkip, skip)
+			if !tc.wantSkip {
+				assert.Equal(t, tc.wantName, name)
+				assert.Equal(t, tc.wantInline, inline)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// mongox-gen generates typed field descriptors from struct definitions,
+// so that the keys passed to the query and update builders are checked at compile time.
+//
+// Usage:
+//
+//	//go:generate mongox-gen -type User,Order
+//
+// For every type T, a variable TFields is generated whose fields hold the bson paths of the fields of T,
+// e.g. UserFields.Address.City is "address.city". Embedded documents also expose their own path
+// through String(), e.g. UserFields.Address.String() is "address".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; required")
+	output := flag.String("output", "", "output file name; default <first type>_fields_gen.go")
+	dir := flag.String("dir", ".", "directory of the package containing the types")
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	types := strings.Split(*typeNames, ",")
+	if *output == "" {
+		*output = strings.ToLower(types[0]) + "_fields_gen.go"
+	}
+	outputPath := *output
+	if !filepath.IsAbs(outputPath) {
+		outputPath = filepath.Join(*dir, outputPath)
+	}
+
+	src, err := generate(*dir, types, outputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mongox-gen:", err)
+		os.Exit(1)
+	}
+	if err = os.WriteFile(outputPath, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "mongox-gen:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type Base struct {
+	ID        string    `bson:"_id,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+type Address struct {
+	City    string
+	ZipCode string `bson:"zip_code"`
+	Geo     *Geo   `bson:"geo"`
+}
+
+type Geo struct {
+	Lat float64 `bson:"lat"`
+	Lng float64 `bson:"lng"`
+}
+
+type User struct {
+	Base     `bson:",inline"`
+	Name     string   `bson:"name"`
+	Age      int      `bson:"age,omitempty"`
+	Address  Address  `bson:"address"`
+	Tags     []string `bson:"tags"`
+	Friends  []*User  `bson:"friends"`
+	Ignored  string   `bson:"-"`
+	secret   string
+	LoggedAt time.Time `bson:"logged_at"`
+}
+
+type Order struct {
+	ID    string `bson:"_id"`
+	Items []Item `bson:"items"`
+}
+
+type Item struct {
+	SKU   string `bson:"sku"`
+	Price int    `bson:"price"`
+}
+
+type Invalid struct {
+	Meta Meta `bson:"meta"`
+}
+
+type Meta struct {
+	String string `bson:"string"`
+}
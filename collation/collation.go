@@ -0,0 +1,99 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collation
+
+import (
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Strength is the level of comparison of a collation
+type Strength int
+
+const (
+	// StrengthPrimary compares the base characters only, ignoring case and diacritics
+	StrengthPrimary Strength = 1
+	// StrengthSecondary compares the base characters and diacritics, ignoring case
+	StrengthSecondary Strength = 2
+	// StrengthTertiary compares the base characters, diacritics and case, the default of the server
+	StrengthTertiary   Strength = 3
+	StrengthQuaternary Strength = 4
+	StrengthIdentical  Strength = 5
+)
+
+// CaseFirst is the sort order of case differences during tertiary level comparisons
+type CaseFirst string
+
+const (
+	CaseFirstUpper CaseFirst = "upper"
+	CaseFirstLower CaseFirst = "lower"
+	CaseFirstOff   CaseFirst = "off"
+)
+
+// Alternate controls whether the collation considers whitespace and punctuation as base characters
+type Alternate string
+
+const (
+	AlternateNonIgnorable Alternate = "non-ignorable"
+	AlternateShifted      Alternate = "shifted"
+)
+
+// MaxVariable is the set of characters ignored when Alternate is AlternateShifted
+type MaxVariable string
+
+const (
+	MaxVariablePunct MaxVariable = "punct"
+	MaxVariableSpace MaxVariable = "space"
+)
+
+// Collation is the language-specific rules used to compare strings, the zero values are left to the server defaults
+type Collation struct {
+	Locale          string
+	CaseLevel       bool
+	CaseFirst       CaseFirst
+	Strength        Strength
+	NumericOrdering bool
+	Alternate       Alternate
+	MaxVariable     MaxVariable
+	Normalization   bool
+	Backwards       bool
+}
+
+// New returns the collation of locale, e.g. "en" or "simple" for the binary comparison
+func New(locale string) *Collation {
+	return &Collation{Locale: locale}
+}
+
+// CaseInsensitive returns the collation of locale ignoring case differences
+func CaseInsensitive(locale string) *Collation {
+	return &Collation{Locale: locale, Strength: StrengthSecondary}
+}
+
+// Options converts c into the collation options of the driver, nil if c is nil
+func (c *Collation) Options() *options.Collation {
+	if c == nil {
+		return nil
+	}
+	return &options.Collation{
+		Locale:          c.Locale,
+		CaseLevel:       c.CaseLevel,
+		CaseFirst:       string(c.CaseFirst),
+		Strength:        int(c.Strength),
+		NumericOrdering: c.NumericOrdering,
+		Alternate:       string(c.Alternate),
+		MaxVariable:     string(c.MaxVariable),
+		Normalization:   c.Normalization,
+		Backwards:       c.Backwards,
+	}
+}
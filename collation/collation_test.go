@@ -0,0 +1,64 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestCollation_Options(t *testing.T) {
+	testCases := []struct {
+		name      string
+		collation *Collation
+		want      *options.Collation
+	}{
+		{name: "nil", collation: nil, want: nil},
+		{name: "locale", collation: New("fr"), want: &options.Collation{Locale: "fr"}},
+		{name: "case insensitive", collation: CaseInsensitive("en"), want: &options.Collation{Locale: "en", Strength: 2}},
+		{
+			name: "all",
+			collation: &Collation{
+				Locale:          "de",
+				CaseLevel:       true,
+				CaseFirst:       CaseFirstUpper,
+				Strength:        StrengthTertiary,
+				NumericOrdering: true,
+				Alternate:       AlternateShifted,
+				MaxVariable:     MaxVariablePunct,
+				Normalization:   true,
+				Backwards:       true,
+			},
+			want: &options.Collation{
+				Locale:          "de",
+				CaseLevel:       true,
+				CaseFirst:       "upper",
+				Strength:        3,
+				NumericOrdering: true,
+				Alternate:       "shifted",
+				MaxVariable:     "punct",
+				Normalization:   true,
+				Backwards:       true,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.collation.Options())
+		})
+	}
+}
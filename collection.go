@@ -15,23 +15,46 @@
 package mongox
 
 import (
+	"context"
+	"errors"
+
 	"github.com/chenmingyong0423/go-mongox/v2/aggregator"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
 	"github.com/chenmingyong0423/go-mongox/v2/callback"
 	"github.com/chenmingyong0423/go-mongox/v2/creator"
 	"github.com/chenmingyong0423/go-mongox/v2/deleter"
+	"github.com/chenmingyong0423/go-mongox/v2/discriminator"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
 	"github.com/chenmingyong0423/go-mongox/v2/field"
 	"github.com/chenmingyong0423/go-mongox/v2/finder"
+	"github.com/chenmingyong0423/go-mongox/v2/tracker"
 	"github.com/chenmingyong0423/go-mongox/v2/updater"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
+// ErrTrackingDisabled is returned by Save when the tracking mode of the collection is not enabled
+// and the context carries no unit of work
+var ErrTrackingDisabled = errors.New("mongox: tracking is disabled, call EnableTracking or UnitOfWork first")
+
+// NewCollection creates a Collection for the documents of type T.
+// It panics if the tags of T are invalid, e.g. a default value that can't be parsed into the field type.
 func NewCollection[T any](db *Database, collection string) *Collection[T] {
-	return &Collection[T]{
+	fields, err := field.ParseFieldsStrict(new(T))
+	if err != nil {
+		panic(err)
+	}
+	c := &Collection[T]{
 		db:         db,
 		collection: db.Database().Collection(collection),
 		callbacks:  db.callbacks,
-		fields:     field.ParseFields(new(T)),
+		fields:     fields,
+	}
+	cfg := db.client.config()
+	if cfg != nil && cfg.KeyProvider != nil {
+		c.cipher = encryption.NewCipher(cfg.KeyProvider)
 	}
+	c.emptyFilterGuard = cfg == nil || !cfg.DisableEmptyFilterGuard
+	return c
 }
 
 type Collection[T any] struct {
@@ -41,27 +64,123 @@ type Collection[T any] struct {
 	callbacks *callback.Callback
 
 	fields []*field.Filed
+	// cipher is set when the client is configured with a KeyProvider, even if T has no encrypted fields,
+	// since the preloaded documents and the registered types of the discriminator may have some
+	cipher *encryption.Cipher
+	// tracker is only set when the tracking mode is enabled
+	tracker *tracker.Tracker[T]
+	// discriminator maps the discriminator values of the documents to their types
+	discriminator *discriminator.Registry
+	// defaultScopes are applied by the finders, updaters, deleters and aggregators unless they are unscoped
+	defaultScopes []query.Scope
+	// emptyFilterGuard makes the updaters and deleters return guard.ErrEmptyFilter for the empty filters
+	emptyFilterGuard bool
+}
+
+// UseDiscriminator sets the registry used to stamp the discriminator field of the inserted documents
+// and, when T is an interface, to decode the found documents into their registered types
+func (c *Collection[T]) UseDiscriminator(registry *discriminator.Registry) *Collection[T] {
+	c.discriminator = registry
+	return c
+}
+
+// Discriminator returns the discriminator registry of the collection, it is nil if none is used
+func (c *Collection[T]) Discriminator() *discriminator.Registry {
+	return c.discriminator
+}
+
+// UseDefaultScopes adds scopes to the default scopes, whose conditions are added to the filters of the finders,
+// updaters and deleters and to a $match stage prepended to the pipelines of the aggregators, unless Unscoped is called
+func (c *Collection[T]) UseDefaultScopes(scopes ...query.Scope) *Collection[T] {
+	c.defaultScopes = append(c.defaultScopes, scopes...)
+	return c
+}
+
+// DefaultScopes returns the default scopes of the collection
+func (c *Collection[T]) DefaultScopes() []query.Scope {
+	return c.defaultScopes
+}
+
+// EnableTracking turns on the tracking mode: the documents found by the finders of the collection are snapshotted,
+// so that Save only writes the fields that changed since they were loaded.
+// The snapshots are shared by the callers and bounded by tracker.DefaultCapacity,
+// the concurrent requests should rather use their own unit of work, see UnitOfWork.
+func (c *Collection[T]) EnableTracking() *Collection[T] {
+	if c.tracker == nil {
+		c.tracker = tracker.New[T](c.fields, tracker.WithCapacity(tracker.DefaultCapacity))
+	}
+	return c
+}
+
+// UnitOfWork returns a copy of ctx carrying a new tracker of the collection: the documents found by the finders
+// called with the context are snapshotted by it and Save compares against it, whether the tracking mode is enabled or not.
+// The snapshots are released with the context.
+func (c *Collection[T]) UnitOfWork(ctx context.Context) context.Context {
+	return tracker.NewContext(ctx, tracker.New[T](c.fields))
+}
+
+// Tracker returns the tracker of the collection, it is nil when the tracking mode is disabled
+func (c *Collection[T]) Tracker() *tracker.Tracker[T] {
+	return c.tracker
+}
+
+// Save persists doc, which requires the tracking mode or a unit of work.
+// A document loaded by a finder is updated with the $set/$unset of its changes, nothing is written if it didn't change.
+// A document without snapshot is inserted, and tracked afterwards.
+func (c *Collection[T]) Save(ctx context.Context, doc *T) error {
+	t := tracker.FromContext[T](ctx)
+	if t == nil {
+		t = c.tracker
+	}
+	if t == nil {
+		return ErrTrackingDisabled
+	}
+	updates, tracked, err := t.Changes(doc)
+	if err != nil {
+		return err
+	}
+	if !tracked {
+		result, err := c.Creator().InsertOne(ctx, doc)
+		if err != nil {
+			return err
+		}
+		t.SetID(doc, result.InsertedID)
+		return t.Track(doc)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	id, _ := t.ID(doc)
+	if _, err = c.Updater().Unscoped().Filter(query.Id(id)).Updates(updates).UpdateOne(ctx); err != nil {
+		return err
+	}
+	return t.Track(doc)
 }
 
 func (c *Collection[T]) Finder() *finder.Finder[T] {
-	return finder.NewFinder[T](c.collection, c.callbacks, c.fields)
+	return finder.NewFinder[T](c.collection, c.callbacks, c.fields).Cipher(c.cipher).Tracker(c.tracker).Discriminator(c.discriminator).DefaultScopes(c.defaultScopes...)
 }
 
 func (c *Collection[T]) Creator() *creator.Creator[T] {
-	return creator.NewCreator[T](c.collection, c.callbacks, c.fields)
+	return creator.NewCreator[T](c.collection, c.callbacks, c.fields).Cipher(c.cipher).Discriminator(c.discriminator)
 }
 
 func (c *Collection[T]) Updater() *updater.Updater[T] {
-	return updater.NewUpdater[T](c.collection, c.callbacks, c.fields)
+	return updater.NewUpdater[T](c.collection, c.callbacks, c.fields).Cipher(c.cipher).DefaultScopes(c.defaultScopes...).GuardEmptyFilter(c.emptyFilterGuard)
 }
 
 func (c *Collection[T]) Deleter() *deleter.Deleter[T] {
-	return deleter.NewDeleter[T](c.collection, c.callbacks, c.fields)
+	return deleter.NewDeleter[T](c.collection, c.callbacks, c.fields).DefaultScopes(c.defaultScopes...).GuardEmptyFilter(c.emptyFilterGuard)
 }
 func (c *Collection[T]) Aggregator() *aggregator.Aggregator[T] {
-	return aggregator.NewAggregator[T](c.collection, c.callbacks, c.fields)
+	return aggregator.NewAggregator[T](c.collection, c.callbacks, c.fields).Cipher(c.cipher).DefaultScopes(c.defaultScopes...)
 }
 
 func (c *Collection[T]) Collection() *mongo.Collection {
 	return c.collection
 }
+
+// Cipher returns the cipher used for the encrypted fields, it is nil when encryption is disabled
+func (c *Collection[T]) Cipher() *encryption.Cipher {
+	return c.cipher
+}
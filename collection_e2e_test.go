@@ -17,9 +17,17 @@
 package mongox
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
+	"github.com/chenmingyong0423/go-mongox/v2/bsonx"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/discriminator"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
@@ -63,6 +71,287 @@ func TestCollection_e2e_New(t *testing.T) {
 	assert.NotNil(t, getCollection[any](t))
 }
 
+func TestCollection_e2e_Encryption(t *testing.T) {
+	type user struct {
+		ID    bson.ObjectID `bson:"_id,omitempty" mongox:"autoID"`
+		Name  string        `bson:"name"`
+		Email string        `bson:"email" mongox:"encrypt:deterministic"`
+		Age   int           `bson:"age" mongox:"encrypt"`
+	}
+	keyRing, err := encryption.NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
+		Username:   "test",
+		Password:   "test",
+		AuthSource: "db-test",
+	}))
+	require.NoError(t, err)
+	collection := NewCollection[user](NewClient(client, &Config{KeyProvider: keyRing}).NewDatabase("db-test"), "test_user")
+	ctx := context.Background()
+	defer func() {
+		_, err := collection.Collection().DeleteMany(ctx, query.NewBuilder().Build())
+		require.NoError(t, err)
+	}()
+
+	u := &user{Name: "chenmingyong", Email: "chenmingyong@mongox.com", Age: 24}
+	_, err = collection.Creator().InsertOne(ctx, u)
+	require.NoError(t, err)
+
+	// the stored values are encrypted
+	raw, err := collection.Collection().FindOne(ctx, bsonx.Id(u.ID)).Raw()
+	require.NoError(t, err)
+	subtype, _, ok := raw.Lookup("email").BinaryOK()
+	require.True(t, ok)
+	require.Equal(t, encryption.BinarySubtype, subtype)
+
+	filter, err := collection.Cipher().Eq(ctx, "email", "chenmingyong@mongox.com")
+	require.NoError(t, err)
+	found, err := collection.Finder().Filter(filter).FindOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, u, found)
+
+	_, err = collection.Updater().Filter(bsonx.Id(u.ID)).Updates(bsonx.M("$set", bsonx.M("age", 25))).UpdateOne(ctx)
+	require.NoError(t, err)
+	users, err := collection.Finder().Filter(bsonx.Id(u.ID)).Find(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, 25, users[0].Age)
+}
+
+func TestCollection_e2e_PreloadEncrypted(t *testing.T) {
+	type author struct {
+		ID    bson.ObjectID `bson:"_id,omitempty"`
+		Email string        `bson:"email" mongox:"encrypt"`
+	}
+	type post struct {
+		ID       bson.ObjectID `bson:"_id,omitempty"`
+		AuthorID bson.ObjectID `bson:"author_id"`
+		Author   *author       `bson:"-" mongox:"ref:test_author,localField:author_id"`
+	}
+	keyRing, err := encryption.NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
+		Username:   "test",
+		Password:   "test",
+		AuthSource: "db-test",
+	}))
+	require.NoError(t, err)
+	db := NewClient(client, &Config{KeyProvider: keyRing}).NewDatabase("db-test")
+	authors, posts := NewCollection[author](db, "test_author"), NewCollection[post](db, "test_user")
+	ctx := context.Background()
+	defer func() {
+		_, err := authors.Collection().DeleteMany(ctx, query.NewBuilder().Build())
+		require.NoError(t, err)
+		_, err = posts.Collection().DeleteMany(ctx, query.NewBuilder().Build())
+		require.NoError(t, err)
+	}()
+
+	a := &author{ID: bson.NewObjectID(), Email: "chenmingyong@mongox.com"}
+	_, err = authors.Creator().InsertOne(ctx, a)
+	require.NoError(t, err)
+	p := &post{ID: bson.NewObjectID(), AuthorID: a.ID}
+	_, err = posts.Creator().InsertOne(ctx, p)
+	require.NoError(t, err)
+
+	// the parent has no encrypted fields, the preloaded author is still decrypted
+	found, err := posts.Finder().Filter(bsonx.Id(p.ID)).Preload("Author").FindOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, a, found.Author)
+}
+
+func TestCollection_e2e_Save(t *testing.T) {
+	type user struct {
+		ID        bson.ObjectID `bson:"_id,omitempty"`
+		Name      string        `bson:"name"`
+		Nickname  *string       `bson:"nickname,omitempty"`
+		Age       int           `bson:"age"`
+		UpdatedAt int64         `bson:"updated_at" mongox:"autoUpdateTime:milli"`
+	}
+	collection := getCollection[user](t).EnableTracking()
+	ctx := context.Background()
+	defer func() {
+		_, err := collection.Collection().DeleteMany(ctx, query.NewBuilder().Build())
+		require.NoError(t, err)
+	}()
+
+	// a new document is inserted
+	nickname := "chen"
+	u := &user{Name: "chenmingyong", Nickname: &nickname, Age: 24}
+	require.NoError(t, collection.Save(ctx, u))
+	require.False(t, u.ID.IsZero())
+	require.True(t, collection.Tracker().Tracked(u))
+
+	found, err := collection.Finder().Filter(bsonx.Id(u.ID)).FindOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "chenmingyong", found.Name)
+
+	// a field changed in the database meanwhile is not overwritten
+	_, err = collection.Collection().UpdateOne(ctx, bsonx.Id(u.ID), bsonx.M("$set", bsonx.M("age", 30)))
+	require.NoError(t, err)
+
+	found.Name = "Mingyong Chen"
+	found.Nickname = nil
+	require.NoError(t, collection.Save(ctx, found))
+
+	raw, err := collection.Collection().FindOne(ctx, bsonx.Id(u.ID)).Raw()
+	require.NoError(t, err)
+	require.Equal(t, "Mingyong Chen", raw.Lookup("name").StringValue())
+	require.Equal(t, int32(30), raw.Lookup("age").Int32())
+	_, err = raw.LookupErr("nickname")
+	require.Error(t, err)
+	require.NotZero(t, raw.Lookup("updated_at").Int64())
+}
+
+func TestCollection_e2e_UnitOfWork(t *testing.T) {
+	type user struct {
+		ID   bson.ObjectID `bson:"_id,omitempty"`
+		Name string        `bson:"name"`
+		Age  int           `bson:"age"`
+	}
+	collection := getCollection[user](t)
+	ctx := context.Background()
+	defer func() {
+		_, err := collection.Collection().DeleteMany(ctx, query.NewBuilder().Build())
+		require.NoError(t, err)
+	}()
+	u := &user{ID: bson.NewObjectID(), Name: "chenmingyong", Age: 24}
+	_, err := collection.Creator().InsertOne(ctx, u)
+	require.NoError(t, err)
+
+	// two requests load the same document, each one saves its changes against its own snapshot
+	ctx1, ctx2 := collection.UnitOfWork(ctx), collection.UnitOfWork(ctx)
+	found1, err := collection.Finder().Filter(bsonx.Id(u.ID)).FindOne(ctx1)
+	require.NoError(t, err)
+	found2, err := collection.Finder().Filter(bsonx.Id(u.ID)).FindOne(ctx2)
+	require.NoError(t, err)
+	found1.Name = "Mingyong Chen"
+	found2.Age = 30
+	require.NoError(t, collection.Save(ctx1, found1))
+	require.NoError(t, collection.Save(ctx2, found2))
+
+	raw, err := collection.Collection().FindOne(ctx, bsonx.Id(u.ID)).Raw()
+	require.NoError(t, err)
+	require.Equal(t, "Mingyong Chen", raw.Lookup("name").StringValue())
+	require.Equal(t, int32(30), raw.Lookup("age").Int32())
+
+	// without a unit of work nor the tracking mode
+	require.ErrorIs(t, collection.Save(ctx, found1), ErrTrackingDisabled)
+}
+
+type testEvent interface {
+	EventName() string
+}
+
+type testClick struct {
+	ID     bson.ObjectID `bson:"_id,omitempty"`
+	Button string        `bson:"button"`
+}
+
+func (c *testClick) EventName() string { return "click" }
+
+type testView struct {
+	ID   bson.ObjectID `bson:"_id,omitempty"`
+	Page string        `bson:"page"`
+}
+
+func (v *testView) EventName() string { return "view" }
+
+func TestCollection_e2e_Discriminator(t *testing.T) {
+	registry := discriminator.NewRegistry("").Register("click", testClick{}).Register("view", testView{})
+	events := getCollection[testEvent](t).UseDiscriminator(registry)
+	ctx := context.Background()
+	defer func() {
+		_, err := events.Collection().DeleteMany(ctx, query.NewBuilder().Build())
+		require.NoError(t, err)
+	}()
+
+	var click testEvent = &testClick{ID: bson.NewObjectID(), Button: "left"}
+	_, err := events.Creator().InsertOne(ctx, &click)
+	require.NoError(t, err)
+	view1, view2 := testEvent(&testView{ID: bson.NewObjectID(), Page: "home"}), testEvent(&testView{ID: bson.NewObjectID(), Page: "about"})
+	_, err = events.Creator().InsertMany(ctx, []*testEvent{&view1, &view2})
+	require.NoError(t, err)
+
+	// the discriminator is stamped
+	raw, err := events.Collection().FindOne(ctx, bsonx.Id(click.(*testClick).ID)).Raw()
+	require.NoError(t, err)
+	require.Equal(t, "click", raw.Lookup("_type").StringValue())
+
+	// each document is decoded into its type
+	found, err := events.Finder().Filter(bsonx.Id(click.(*testClick).ID)).FindOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, click, *found)
+
+	all, err := events.Finder().Sort(bsonx.M("_id", 1)).Find(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*testEvent{&click, &view1, &view2}, all)
+
+	views, err := finder.OfType[testView](events.Finder()).Sort(bsonx.M("_id", 1)).Find(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*testEvent{&view1, &view2}, views)
+
+	// a collection of a concrete type shares the documents of its type
+	clicks := NewCollection[testClick](events.db, "test_user").UseDiscriminator(registry)
+	count, err := finder.OfType[testClick](clicks.Finder()).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+type testSignIn struct {
+	ID    bson.ObjectID `bson:"_id,omitempty"`
+	Email string        `bson:"email" mongox:"encrypt:deterministic"`
+}
+
+func (s *testSignIn) EventName() string { return "sign_in" }
+
+func TestCollection_e2e_DiscriminatorEncryption(t *testing.T) {
+	keyRing, err := encryption.NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
+		Username:   "test",
+		Password:   "test",
+		AuthSource: "db-test",
+	}))
+	require.NoError(t, err)
+	registry := discriminator.NewRegistry("").Register("click", testClick{}).Register("sign_in", testSignIn{})
+	events := NewCollection[testEvent](NewClient(client, &Config{KeyProvider: keyRing}).NewDatabase("db-test"), "test_user").UseDiscriminator(registry)
+	ctx := context.Background()
+	defer func() {
+		_, err := events.Collection().DeleteMany(ctx, query.NewBuilder().Build())
+		require.NoError(t, err)
+	}()
+
+	var signIn testEvent = &testSignIn{ID: bson.NewObjectID(), Email: "chenmingyong@mongox.com"}
+	_, err = events.Creator().InsertOne(ctx, &signIn)
+	require.NoError(t, err)
+	click, signIn2 := testEvent(&testClick{ID: bson.NewObjectID(), Button: "left"}), testEvent(&testSignIn{ID: bson.NewObjectID(), Email: "mongox@mongox.com"})
+	_, err = events.Creator().InsertMany(ctx, []*testEvent{&click, &signIn2})
+	require.NoError(t, err)
+
+	// the encrypted fields of the registered types are encrypted
+	for _, id := range []bson.ObjectID{signIn.(*testSignIn).ID, signIn2.(*testSignIn).ID} {
+		raw, err := events.Collection().FindOne(ctx, bsonx.Id(id)).Raw()
+		require.NoError(t, err)
+		require.Equal(t, "sign_in", raw.Lookup("_type").StringValue())
+		subtype, _, ok := raw.Lookup("email").BinaryOK()
+		require.True(t, ok)
+		require.Equal(t, encryption.BinarySubtype, subtype)
+	}
+
+	// and decrypted when the documents are found
+	all, err := events.Finder().Sort(bsonx.M("_id", 1)).Find(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*testEvent{&signIn, &click, &signIn2}, all)
+
+	// the ids of the documents are those of their concrete types
+	missingID := bson.NewObjectID()
+	found, missing, err := events.Finder().FindByIDs(ctx, []bson.ObjectID{signIn2.(*testSignIn).ID, missingID, click.(*testClick).ID})
+	require.NoError(t, err)
+	require.Equal(t, []*testEvent{&signIn2, &click}, found)
+	require.Equal(t, []any{missingID}, missing)
+}
+
 func getCollection[T any](t *testing.T) *Collection[T] {
 	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
 		Username:   "test",
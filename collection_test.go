@@ -15,14 +15,26 @@
 package mongox
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"testing"
 
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/deleter"
+	"github.com/chenmingyong0423/go-mongox/v2/discriminator"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
+	"github.com/chenmingyong0423/go-mongox/v2/guard"
+	"github.com/chenmingyong0423/go-mongox/v2/tracker"
+
 	"github.com/chenmingyong0423/go-mongox/v2/updater"
 
 	"github.com/chenmingyong0423/go-mongox/v2/creator"
 
 	"github.com/chenmingyong0423/go-mongox/v2/finder"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
@@ -62,3 +74,94 @@ func TestCollection_Collection(t *testing.T) {
 	a := NewCollection[any](NewClient(&mongo.Client{}, &Config{}).NewDatabase("db-test"), "collection-test")
 	assert.NotNil(t, a.Collection(), "Expected non-nil *mongo.Collection")
 }
+
+func TestCollection_Cipher(t *testing.T) {
+	type user struct {
+		Name  string `bson:"name"`
+		Email string `bson:"email" mongox:"encrypt:deterministic"`
+	}
+	keyRing, err := encryption.NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	assert.NoError(t, err)
+
+	assert.Nil(t, NewCollection[user](NewClient(&mongo.Client{}, &Config{}).NewDatabase("db-test"), "collection-test").Cipher())
+	assert.NotNil(t, NewCollection[any](NewClient(&mongo.Client{}, &Config{KeyProvider: keyRing}).NewDatabase("db-test"), "collection-test").Cipher())
+	assert.NotNil(t, NewCollection[user](NewClient(&mongo.Client{}, &Config{KeyProvider: keyRing}).NewDatabase("db-test"), "collection-test").Cipher())
+}
+
+func TestCollection_NewWithInvalidTags(t *testing.T) {
+	type user struct {
+		Age int `bson:"age" mongox:"default:abc"`
+	}
+	assert.Panics(t, func() {
+		NewCollection[user](NewClient(&mongo.Client{}, &Config{}).NewDatabase("db-test"), "collection-test")
+	})
+}
+
+func TestCollection_Tracking(t *testing.T) {
+	collection := NewCollection[any](NewClient(&mongo.Client{}, &Config{}).NewDatabase("db-test"), "collection-test")
+	assert.Nil(t, collection.Tracker())
+	assert.ErrorIs(t, collection.Save(context.Background(), new(any)), ErrTrackingDisabled)
+
+	tracker := collection.EnableTracking().Tracker()
+	assert.NotNil(t, tracker)
+	assert.Same(t, tracker, collection.EnableTracking().Tracker())
+}
+
+func TestCollection_UnitOfWork(t *testing.T) {
+	collection := NewCollection[any](NewClient(&mongo.Client{}, &Config{}).NewDatabase("db-test"), "collection-test")
+	ctx1, ctx2 := collection.UnitOfWork(context.Background()), collection.UnitOfWork(context.Background())
+	require.NotNil(t, tracker.FromContext[any](ctx1))
+	assert.NotSame(t, tracker.FromContext[any](ctx1), tracker.FromContext[any](ctx2))
+	assert.Nil(t, collection.Tracker())
+}
+
+func TestCollection_UseDiscriminator(t *testing.T) {
+	collection := NewCollection[any](NewClient(&mongo.Client{}, &Config{}).NewDatabase("db-test"), "collection-test")
+	assert.Nil(t, collection.Discriminator())
+
+	registry := discriminator.NewRegistry("")
+	assert.Same(t, registry, collection.UseDiscriminator(registry).Discriminator())
+}
+
+func TestCollection_UseDefaultScopes(t *testing.T) {
+	collection := NewCollection[any](NewClient(&mongo.Client{}, &Config{}).NewDatabase("db-test"), "collection-test")
+	assert.Empty(t, collection.DefaultScopes())
+
+	notDeleted := func(b *query.Builder) *query.Builder { return b.Eq("deleted", false) }
+	collection.UseDefaultScopes(notDeleted)
+	assert.Len(t, collection.DefaultScopes(), 1)
+
+	errStop := errors.New("stop")
+	var filters []any
+	hook := func(ctx context.Context, opContext *deleter.OpContext, opts ...any) error {
+		filters = append(filters, opContext.Filter)
+		return errStop
+	}
+	_, err := collection.Deleter().Filter(query.Id(1)).RegisterBeforeHooks(hook).DeleteOne(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	_, err = collection.Deleter().Filter(query.Id(1)).Unscoped().RegisterBeforeHooks(hook).DeleteOne(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []any{
+		bson.D{{Key: "_id", Value: 1}, {Key: "deleted", Value: bson.D{{Key: "$eq", Value: false}}}},
+		query.Id(1),
+	}, filters)
+}
+
+func TestCollection_EmptyFilterGuard(t *testing.T) {
+	errStop := errors.New("stop")
+	hook := func(ctx context.Context, opContext *deleter.OpContext, opts ...any) error {
+		return errStop
+	}
+
+	collection := NewCollection[any](NewClient(&mongo.Client{}, &Config{}).NewDatabase("db-test"), "collection-test")
+	_, err := collection.Deleter().RegisterBeforeHooks(hook).DeleteMany(context.Background())
+	assert.ErrorIs(t, err, guard.ErrEmptyFilter)
+	_, err = collection.Updater().Updates(bson.M{"$set": bson.M{"name": "burt"}}).UpdateMany(context.Background())
+	assert.ErrorIs(t, err, guard.ErrEmptyFilter)
+	_, err = collection.Deleter().AllowFullCollection().RegisterBeforeHooks(hook).DeleteMany(context.Background())
+	assert.ErrorIs(t, err, errStop)
+
+	collection = NewCollection[any](NewClient(&mongo.Client{}, &Config{DisableEmptyFilterGuard: true}).NewDatabase("db-test"), "collection-test")
+	_, err = collection.Deleter().RegisterBeforeHooks(hook).DeleteMany(context.Background())
+	assert.ErrorIs(t, err, errStop)
+}
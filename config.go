@@ -14,5 +14,12 @@
 
 package mongox
 
+import "github.com/chenmingyong0423/go-mongox/v2/encryption"
+
 type Config struct {
+	// KeyProvider enables client-side encryption of the fields tagged with `mongox:"encrypt"`
+	KeyProvider encryption.KeyProvider
+	// DisableEmptyFilterGuard allows the update and delete methods to be called with an empty filter
+	// without AllowFullCollection, instead of returning guard.ErrEmptyFilter
+	DisableEmptyFilterGuard bool
 }
@@ -19,6 +19,8 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/chenmingyong0423/go-mongox/v2/discriminator"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
 	"github.com/chenmingyong0423/go-mongox/v2/field"
 
 	"github.com/chenmingyong0423/go-mongox/v2/callback"
@@ -52,7 +54,9 @@ type Creator[T any] struct {
 	BeforeHooks []HookFn[T]
 	AfterHooks  []HookFn[T]
 
-	fields []*field.Filed
+	fields        []*field.Filed
+	cipher        *encryption.Cipher
+	discriminator *discriminator.Registry
 }
 
 func NewCreator[T any](collection *mongo.Collection, dbCallbacks *callback.Callback, fields []*field.Filed) *Creator[T] {
@@ -68,6 +72,18 @@ func (c *Creator[T]) ModelHook(modelHook any) ICreator[T] {
 	return c
 }
 
+// Cipher is used to encrypt the fields tagged with `mongox:"encrypt"` before they are inserted
+func (c *Creator[T]) Cipher(cipher *encryption.Cipher) *Creator[T] {
+	c.cipher = cipher
+	return c
+}
+
+// Discriminator is used to set the discriminator field of the inserted documents to the value registered for their type
+func (c *Creator[T]) Discriminator(registry *discriminator.Registry) *Creator[T] {
+	c.discriminator = registry
+	return c
+}
+
 // RegisterBeforeHooks is used to set the after hooks of the insert operation
 // If you register the hook for InsertOne, the opContext.Docs will be nil
 // If you register the hook for InsertMany, the opContext.Doc will be nil
@@ -121,7 +137,17 @@ func (c *Creator[T]) InsertOne(ctx context.Context, doc *T, opts ...options.List
 		return nil, err
 	}
 
-	result, err := c.collection.InsertOne(ctx, doc, opts...)
+	insertDoc, err := c.encrypt(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	if c.discriminator != nil {
+		if insertDoc, err = c.discriminator.Stamp(doc, insertDoc); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := c.collection.InsertOne(ctx, insertDoc, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +174,21 @@ func (c *Creator[T]) InsertMany(ctx context.Context, docs []*T, opts ...options.
 		return nil, err
 	}
 
-	result, err := c.collection.InsertMany(ctx, utils.ToAnySlice(docs...), opts...)
+	insertDocs := utils.ToAnySlice(docs...)
+	for i, doc := range docs {
+		if insertDocs[i], err = c.encrypt(ctx, doc); err != nil {
+			return nil, err
+		}
+	}
+	if c.discriminator != nil {
+		for i, doc := range docs {
+			if insertDocs[i], err = c.discriminator.Stamp(doc, insertDocs[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result, err := c.collection.InsertMany(ctx, insertDocs, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -164,3 +204,25 @@ func (c *Creator[T]) InsertMany(ctx context.Context, docs []*T, opts ...options.
 func (c *Creator[T]) GetCollection() *mongo.Collection {
 	return c.collection
 }
+
+// encrypt returns doc with the values of its encrypted fields encrypted, or doc itself when it has none.
+// The document of an interface T is encrypted with the fields of its concrete type.
+func (c *Creator[T]) encrypt(ctx context.Context, doc *T) (any, error) {
+	if c.cipher == nil {
+		return doc, nil
+	}
+	var value any = doc
+	fields := c.fields
+	if v := reflect.ValueOf(doc); !v.IsNil() && v.Elem().Kind() == reflect.Interface {
+		if v.Elem().IsNil() {
+			return doc, nil
+		}
+		// the encoder can't marshal a pointer to an interface
+		value = v.Elem().Interface()
+		fields = field.ParseFields(value)
+	}
+	if !encryption.HasEncryptedFields(fields) {
+		return doc, nil
+	}
+	return c.cipher.EncryptDocument(ctx, value, fields)
+}
@@ -15,11 +15,21 @@
 package mongox
 
 import (
+	"context"
+	"errors"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
 	"github.com/chenmingyong0423/go-mongox/v2/callback"
 	"github.com/chenmingyong0423/go-mongox/v2/operation"
+	"github.com/chenmingyong0423/go-mongox/v2/schema"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// namespaceNotFoundCode is the server error code returned by collMod when the collection does not exist
+const namespaceNotFoundCode = 26
+
 type Database struct {
 	client *Client
 	db     *mongo.Database
@@ -46,3 +56,32 @@ func (d *Database) RegisterPlugin(name string, cb callback.CbFn, opType operatio
 func (d *Database) RemovePlugin(name string, opType operation.OpType) {
 	d.callbacks.Remove(opType, name)
 }
+
+// ApplyValidator sets jsonSchema as the $jsonSchema validator of the collection, e.g. the result of schema.FromType.
+// The validator of an existing collection is replaced with collMod, otherwise the collection is created.
+// Empty level and action keep the server defaults.
+func (d *Database) ApplyValidator(ctx context.Context, collection string, jsonSchema any, level schema.ValidationLevel, action schema.ValidationAction) error {
+	validator := query.JsonSchema(jsonSchema)
+
+	cmd := bson.D{{Key: "collMod", Value: collection}, {Key: "validator", Value: validator}}
+	if level != "" {
+		cmd = append(cmd, bson.E{Key: "validationLevel", Value: string(level)})
+	}
+	if action != "" {
+		cmd = append(cmd, bson.E{Key: "validationAction", Value: string(action)})
+	}
+	err := d.db.RunCommand(ctx, cmd).Err()
+	var cmdErr mongo.CommandError
+	if err == nil || !errors.As(err, &cmdErr) || cmdErr.Code != namespaceNotFoundCode {
+		return err
+	}
+
+	opts := options.CreateCollection().SetValidator(validator)
+	if level != "" {
+		opts.SetValidationLevel(string(level))
+	}
+	if action != "" {
+		opts.SetValidationAction(string(action))
+	}
+	return d.db.CreateCollection(ctx, collection, opts)
+}
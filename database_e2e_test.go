@@ -24,6 +24,7 @@ import (
 	"go.mongodb.org/mongo-driver/v2/bson"
 
 	"github.com/chenmingyong0423/go-mongox/v2/operation"
+	"github.com/chenmingyong0423/go-mongox/v2/schema"
 
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -380,3 +381,25 @@ func TestRegisterPlugin_BeforeAny(t *testing.T) {
 		assert.False(t, isCalled)
 	})
 }
+
+func TestDatabase_e2e_ApplyValidator(t *testing.T) {
+	type User struct {
+		Name string `bson:"name" validate:"required"`
+		Age  int    `bson:"age" validate:"min=18"`
+	}
+	ctx := context.Background()
+	db := newDatabase(NewClient(getMongoClient(t), &Config{}), "db-test")
+	defer func() {
+		require.NoError(t, db.Database().Collection("test_validator").Drop(ctx))
+	}()
+
+	// the collection does not exist yet and is created
+	require.NoError(t, db.ApplyValidator(ctx, "test_validator", schema.FromType[User](), schema.ValidationLevelStrict, schema.ValidationActionError))
+	_, err := db.Database().Collection("test_validator").InsertOne(ctx, bson.M{"age": 20})
+	require.Error(t, err)
+
+	// the validator of the existing collection is replaced
+	require.NoError(t, db.ApplyValidator(ctx, "test_validator", schema.FromType[User](), schema.ValidationLevelModerate, schema.ValidationActionWarn))
+	_, err = db.Database().Collection("test_validator").InsertOne(ctx, bson.M{"age": 20})
+	require.NoError(t, err)
+}
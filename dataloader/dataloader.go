@@ -0,0 +1,325 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/finder"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	DefaultWait     = 2 * time.Millisecond
+	DefaultMaxBatch = 1000
+	DefaultTimeout  = 30 * time.Second
+)
+
+// Collection creates the finders used by the loaders, it is implemented by *mongox.Collection[T]
+type Collection[T any] interface {
+	Finder() *finder.Finder[T]
+}
+
+type Option func(*config)
+
+type config struct {
+	wait     time.Duration
+	maxBatch int
+	timeout  time.Duration
+}
+
+func defaultConfig() config {
+	return config{wait: DefaultWait, maxBatch: DefaultMaxBatch, timeout: DefaultTimeout}
+}
+
+// WithWait sets how long a loader collects the keys before querying them, DefaultWait by default
+func WithWait(wait time.Duration) Option {
+	return func(o *config) {
+		o.wait = wait
+	}
+}
+
+// WithMaxBatch sets the maximum number of keys of a query, DefaultMaxBatch by default
+func WithMaxBatch(maxBatch int) Option {
+	return func(o *config) {
+		o.maxBatch = maxBatch
+	}
+}
+
+// WithTimeout sets the timeout of the batch queries, DefaultTimeout by default, no timeout if not positive.
+// A batch holds the keys of several callers, so it isn't canceled with the context of any of them.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *config) {
+		o.timeout = timeout
+	}
+}
+
+type contextKey struct{}
+
+type registry struct {
+	opts    config
+	mu      sync.Mutex
+	loaders map[string]any
+}
+
+// NewContext returns a copy of ctx holding the loaders of a request, their results are cached until ctx is dropped
+func NewContext(ctx context.Context, opts ...Option) context.Context {
+	r := &registry{opts: defaultConfig(), loaders: make(map[string]any)}
+	for _, opt := range opts {
+		opt(&r.opts)
+	}
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// For returns the loader of the documents of collection keyed by field (the mongo field name, _id if empty) in ctx.
+// Without NewContext a new loader is returned every time, so nothing is batched nor cached across calls.
+func For[T any](ctx context.Context, collection Collection[T], field string) *Loader[T] {
+	if field == "" {
+		field = "_id"
+	}
+	r, ok := ctx.Value(contextKey{}).(*registry)
+	if !ok {
+		return newLoader(collection, field, defaultConfig())
+	}
+	coll := collection.Finder().GetCollection()
+	name := fmt.Sprintf("%s.%s:%s:%s", coll.Database().Name(), coll.Name(), field, reflect.TypeOf((*T)(nil)).Elem())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l, ok := r.loaders[name].(*Loader[T]); ok {
+		return l
+	}
+	l := newLoader(collection, field, r.opts)
+	r.loaders[name] = l
+	return l
+}
+
+// Loader batches the keys loaded within a short window into a single $in query through the Finder of the collection,
+// so the plugins and hooks of the collection run once per batch. The results are cached by key.
+type Loader[T any] struct {
+	collection Collection[T]
+	field      string
+	opts       config
+
+	mu    sync.Mutex
+	cache map[string]*entry[T]
+	batch *batch[T]
+}
+
+type entry[T any] struct {
+	done chan struct{}
+	docs []*T
+	err  error
+}
+
+type batch[T any] struct {
+	// ctx is the context of the first key queued, only its values are used by the query
+	ctx     context.Context
+	keys    []any
+	entries map[string]*entry[T]
+	timer   *time.Timer
+}
+
+func newLoader[T any](collection Collection[T], field string, opts config) *Loader[T] {
+	if opts.maxBatch <= 0 {
+		opts.maxBatch = DefaultMaxBatch
+	}
+	return &Loader[T]{collection: collection, field: field, opts: opts, cache: make(map[string]*entry[T])}
+}
+
+// Load returns the document whose field equals key, mongo.ErrNoDocuments if there is none.
+// If several documents match, the first one returned by the query is used, see LoadAll.
+func (l *Loader[T]) Load(ctx context.Context, key any) (*T, error) {
+	docs, err := l.LoadAll(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return docs[0], nil
+}
+
+// LoadAll returns all the documents whose field equals key.
+// It returns when ctx is done even if the batch of key is still being queried for the other callers.
+func (l *Loader[T]) LoadAll(ctx context.Context, key any) ([]*T, error) {
+	e, err := l.enqueue(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-e.done:
+		return e.docs, e.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// LoadMany returns the documents of keys in the same order, nil for the keys without document.
+// The keys are queried in the same batch.
+func (l *Loader[T]) LoadMany(ctx context.Context, keys ...any) ([]*T, error) {
+	entries := make([]*entry[T], 0, len(keys))
+	for _, key := range keys {
+		e, err := l.enqueue(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	docs := make([]*T, len(keys))
+	for i, e := range entries {
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if e.err != nil {
+			return nil, e.err
+		}
+		if len(e.docs) > 0 {
+			docs[i] = e.docs[0]
+		}
+	}
+	return docs, nil
+}
+
+// Prime caches doc as the document of key unless key has already been loaded
+func (l *Loader[T]) Prime(key any, doc *T) {
+	k, err := utils.BsonKey(key)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[k]; ok {
+		return
+	}
+	e := &entry[T]{done: make(chan struct{}), docs: []*T{doc}}
+	close(e.done)
+	l.cache[k] = e
+}
+
+// Clear removes key from the cache, e.g. after the document has been updated
+func (l *Loader[T]) Clear(key any) {
+	k, err := utils.BsonKey(key)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cache, k)
+}
+
+// enqueue returns the cached entry of key, adding it to the pending batch if it has never been loaded
+func (l *Loader[T]) enqueue(ctx context.Context, key any) (*entry[T], error) {
+	k, err := utils.BsonKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.cache[k]; ok {
+		return e, nil
+	}
+	e := &entry[T]{done: make(chan struct{})}
+	l.cache[k] = e
+
+	if l.batch == nil {
+		b := &batch[T]{ctx: ctx, entries: make(map[string]*entry[T])}
+		b.timer = time.AfterFunc(l.opts.wait, func() {
+			l.mu.Lock()
+			if l.batch != b {
+				// already dispatched because it was full
+				l.mu.Unlock()
+				return
+			}
+			l.batch = nil
+			l.mu.Unlock()
+			l.dispatch(b)
+		})
+		l.batch = b
+	}
+	b := l.batch
+	b.keys = append(b.keys, key)
+	b.entries[k] = e
+	if len(b.keys) >= l.opts.maxBatch {
+		b.timer.Stop()
+		l.batch = nil
+		go l.dispatch(b)
+	}
+	return e, nil
+}
+
+// dispatch queries the keys of b and completes its entries, the failed entries are removed from the cache
+func (l *Loader[T]) dispatch(b *batch[T]) {
+	ctx := context.Context(detachedContext{parent: b.ctx})
+	if l.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.opts.timeout)
+		defer cancel()
+	}
+	docs, err := l.collection.Finder().Filter(query.In(l.field, b.keys...)).Find(ctx)
+	if err == nil {
+		for _, doc := range docs {
+			var keys []string
+			if keys, err = utils.LookupKeys(doc, l.field); err != nil {
+				break
+			}
+			for _, k := range keys {
+				if e, ok := b.entries[k]; ok {
+					e.docs = append(e.docs, doc)
+				}
+			}
+		}
+	}
+
+	if err != nil {
+		l.mu.Lock()
+		for k, e := range b.entries {
+			if l.cache[k] == e {
+				delete(l.cache, k)
+			}
+		}
+		l.mu.Unlock()
+	}
+	for _, e := range b.entries {
+		if err != nil {
+			e.docs = nil
+		}
+		e.err = err
+		close(e.done)
+	}
+}
+
+// detachedContext keeps the values of parent but not its deadline and cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (c detachedContext) Value(key any) any { return c.parent.Value(key) }
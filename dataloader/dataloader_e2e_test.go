@@ -0,0 +1,119 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+type testPost struct {
+	ID       bson.ObjectID `bson:"_id,omitempty"`
+	AuthorID bson.ObjectID `bson:"author_id"`
+	Title    string        `bson:"title"`
+}
+
+func getDatabase(t *testing.T) *mongox.Database {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
+		Username:   "test",
+		Password:   "test",
+		AuthSource: "db-test",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, client.Ping(context.Background(), readpref.Primary()))
+	return mongox.NewClient(client, &mongox.Config{}).NewDatabase("db-test")
+}
+
+func TestLoader_e2e(t *testing.T) {
+	db := getDatabase(t)
+	var finds int
+	db.RegisterPlugin("count finds", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+		finds++
+		return nil
+	}, operation.OpTypeBeforeFind)
+
+	users := mongox.NewCollection[user](db, "test_user")
+	posts := mongox.NewCollection[testPost](db, "test_post")
+	bg := context.Background()
+	defer func() {
+		_, err := users.Collection().DeleteMany(bg, query.NewBuilder().Build())
+		require.NoError(t, err)
+		_, err = posts.Collection().DeleteMany(bg, query.NewBuilder().Build())
+		require.NoError(t, err)
+	}()
+
+	alice, bob := &user{ID: bson.NewObjectID(), Name: "alice"}, &user{ID: bson.NewObjectID(), Name: "bob"}
+	_, err := users.Creator().InsertMany(bg, []*user{alice, bob})
+	require.NoError(t, err)
+	_, err = posts.Creator().InsertMany(bg, []*testPost{
+		{ID: bson.NewObjectID(), AuthorID: alice.ID, Title: "a1"},
+		{ID: bson.NewObjectID(), AuthorID: alice.ID, Title: "a2"},
+		{ID: bson.NewObjectID(), AuthorID: bob.ID, Title: "b1"},
+	})
+	require.NoError(t, err)
+
+	ctx := NewContext(bg)
+	ids := []bson.ObjectID{alice.ID, bob.ID, alice.ID, bson.NewObjectID()}
+	owners := make([]*user, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id bson.ObjectID) {
+			defer wg.Done()
+			owners[i], errs[i] = For[user](ctx, users, "").Load(ctx, id)
+		}(i, id)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, finds)
+	assert.Equal(t, []*user{alice, bob, alice, nil}, owners)
+	assert.Equal(t, []error{nil, nil, nil, mongo.ErrNoDocuments}, errs)
+
+	// cached per request
+	owner, err := For[user](ctx, users, "").Load(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, bob, owner)
+	assert.Equal(t, 1, finds)
+
+	many, err := For[user](ctx, users, "").LoadMany(ctx, alice.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []*user{alice, bob}, many)
+	assert.Equal(t, 1, finds)
+
+	aliceIDs, err := For[testPost](ctx, posts, "author_id").LoadAll(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Len(t, aliceIDs, 2)
+	assert.Equal(t, 2, finds)
+
+	// a new request queries again
+	owner, err = For[user](NewContext(bg), users, "").Load(bg, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice, owner)
+	assert.Equal(t, 3, finds)
+}
@@ -0,0 +1,180 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/finder"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type user struct {
+	ID   bson.ObjectID `bson:"_id"`
+	Name string        `bson:"name"`
+}
+
+// failingCollection counts the queries and fails them in a before hook, before reaching the database
+type failingCollection struct {
+	collection *mongo.Collection
+	queries    int32
+	filters    chan any
+	ctxErrs    chan error
+}
+
+func (c *failingCollection) Finder() *finder.Finder[user] {
+	f := finder.NewFinder[user](c.collection, callback.InitializeCallbacks(), nil)
+	return f.RegisterBeforeHooks(func(ctx context.Context, opContext *finder.OpContext[user], opts ...any) error {
+		atomic.AddInt32(&c.queries, 1)
+		c.filters <- opContext.Filter
+		c.ctxErrs <- ctx.Err()
+		return errors.New("query failed")
+	}).(*finder.Finder[user])
+}
+
+func newFailingCollection(name string) *failingCollection {
+	return &failingCollection{
+		collection: (&mongo.Client{}).Database("db-test").Collection(name),
+		filters:    make(chan any, 10),
+		ctxErrs:    make(chan error, 10),
+	}
+}
+
+func TestFor(t *testing.T) {
+	users, posts := newFailingCollection("users"), newFailingCollection("posts")
+
+	assert.NotSame(t, For[user](context.Background(), users, ""), For[user](context.Background(), users, ""))
+
+	ctx := NewContext(context.Background(), WithWait(time.Second), WithMaxBatch(10))
+	l := For[user](ctx, users, "")
+	assert.Same(t, l, For[user](ctx, users, "_id"))
+	assert.Equal(t, "_id", l.field)
+	assert.Equal(t, config{wait: time.Second, maxBatch: 10, timeout: DefaultTimeout}, l.opts)
+	assert.NotSame(t, l, For[user](ctx, users, "name"))
+	assert.NotSame(t, l, For[user](ctx, posts, ""))
+	assert.NotSame(t, l, For[user](NewContext(context.Background()), users, ""))
+}
+
+func TestLoader_Batch(t *testing.T) {
+	users := newFailingCollection("users")
+	l := For[user](NewContext(context.Background()), users, "")
+	ids := []bson.ObjectID{bson.NewObjectID(), bson.NewObjectID(), bson.NewObjectID()}
+
+	var wg sync.WaitGroup
+	for _, id := range append(ids, ids[0]) {
+		wg.Add(1)
+		go func(id bson.ObjectID) {
+			defer wg.Done()
+			_, err := l.Load(context.Background(), id)
+			assert.EqualError(t, err, "query failed")
+		}(id)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&users.queries))
+	filter := (<-users.filters).(bson.D)
+	assert.Equal(t, "_id", filter[0].Key)
+	assert.ElementsMatch(t, ids, filter[0].Value.(bson.D)[0].Value)
+
+	// failed keys are not cached
+	_, err := l.Load(context.Background(), ids[0])
+	assert.EqualError(t, err, "query failed")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&users.queries))
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	users := newFailingCollection("users")
+	l := For[user](NewContext(context.Background(), WithWait(time.Hour), WithMaxBatch(2)), users, "name")
+
+	docs, err := l.LoadMany(context.Background(), "a", "b")
+	assert.Nil(t, docs)
+	assert.EqualError(t, err, "query failed")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&users.queries))
+	assert.Equal(t, bson.D{{Key: "name", Value: bson.D{{Key: "$in", Value: []any{"a", "b"}}}}}, <-users.filters)
+}
+
+func TestLoader_NumericKeys(t *testing.T) {
+	users := newFailingCollection("users")
+	l := For[user](NewContext(context.Background(), WithWait(time.Hour), WithMaxBatch(2)), users, "age")
+
+	// 1, int64(1) and 1.0 are the same key, as the server matches them by value
+	_, err := l.LoadMany(context.Background(), 1, int64(1), 1.0, 2)
+	assert.EqualError(t, err, "query failed")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&users.queries))
+	assert.Equal(t, bson.D{{Key: "age", Value: bson.D{{Key: "$in", Value: []any{1, 2}}}}}, <-users.filters)
+}
+
+func TestLoader_CanceledCaller(t *testing.T) {
+	users := newFailingCollection("users")
+	l := For[user](NewContext(context.Background(), WithWait(50*time.Millisecond)), users, "")
+
+	// the first caller of the batch gives up, the query still runs for the others
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := l.Load(ctx, bson.NewObjectID())
+		errs <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	_, err := l.Load(context.Background(), bson.NewObjectID())
+	assert.EqualError(t, err, "query failed")
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&users.queries))
+	assert.NoError(t, <-users.ctxErrs)
+}
+
+func Test_detachedContext(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "value"), time.Hour)
+	cancel()
+
+	ctx := detachedContext{parent: parent}
+	assert.Equal(t, "value", ctx.Value(key{}))
+	assert.Nil(t, ctx.Done())
+	assert.NoError(t, ctx.Err())
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+}
+
+func TestLoader_Cache(t *testing.T) {
+	l := For[user](NewContext(context.Background()), newFailingCollection("users"), "")
+	id := bson.NewObjectID()
+	doc := &user{ID: id}
+	l.Prime(id, doc)
+
+	got, err := l.Load(context.Background(), id)
+	require.NoError(t, err)
+	assert.Same(t, doc, got)
+
+	l.Clear(id)
+	l.mu.Lock()
+	assert.Empty(t, l.cache)
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = For[user](NewContext(context.Background(), WithWait(time.Hour)), newFailingCollection("users"), "").Load(ctx, id)
+	assert.ErrorIs(t, err, context.Canceled)
+}
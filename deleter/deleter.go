@@ -18,14 +18,20 @@ import (
 	"context"
 	"time"
 
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
 	"github.com/chenmingyong0423/go-mongox/v2/field"
 
 	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/collation"
+	"github.com/chenmingyong0423/go-mongox/v2/guard"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/driveropt"
 
 	"github.com/chenmingyong0423/go-mongox/v2/operation"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 )
 
 //go:generate mockgen -source=deleter.go -destination=../mock/deleter.mock.go -package=mocks
@@ -33,6 +39,18 @@ type IDeleter[T any] interface {
 	DeleteOne(ctx context.Context, opts ...options.Lister[options.DeleteOneOptions]) (*mongo.DeleteResult, error)
 	DeleteMany(ctx context.Context, opts ...options.Lister[options.DeleteManyOptions]) (*mongo.DeleteResult, error)
 	Filter(filter any) IDeleter[T]
+	Where(conds ...any) IDeleter[T]
+	OrWhere(conds ...any) IDeleter[T]
+	Not(conds ...any) IDeleter[T]
+	Scopes(scopes ...query.Scope) IDeleter[T]
+	Unscoped() IDeleter[T]
+	AllowFullCollection() IDeleter[T]
+	Collation(collation *collation.Collation) IDeleter[T]
+	Hint(hint any) IDeleter[T]
+	MaxTime(maxTime time.Duration) IDeleter[T]
+	Comment(comment any) IDeleter[T]
+	Let(let any) IDeleter[T]
+	WriteConcern(wc *writeconcern.WriteConcern) IDeleter[T]
 	ModelHook(modelHook any) IDeleter[T]
 	RegisterAfterHooks(hooks ...AfterHookFn) IDeleter[T]
 	RegisterBeforeHooks(hooks ...BeforeHookFn) IDeleter[T]
@@ -51,22 +69,39 @@ type Deleter[T any] struct {
 	collection *mongo.Collection
 	fields     []*field.Filed
 
-	filter    any
-	modelHook any
+	filter        any
+	modelHook     any
+	collation     *collation.Collation
+	defaultScopes []query.Scope
+	unscoped      bool
+	// allowFullCollection disables the ErrEmptyFilter guard
+	allowFullCollection bool
+	driverOpts          driveropt.Options
 
 	DBCallbacks *callback.Callback
 	BeforeHooks []BeforeHookFn
 	AfterHooks  []AfterHookFn
 }
 
+// Clone returns a copy of d. The fluent methods already return copies and the terminal methods don't modify d,
+// so a deleter can be declared once, e.g. at package level, and shared by goroutines.
+func (d *Deleter[T]) Clone() *Deleter[T] {
+	c := *d
+	c.BeforeHooks = append([]BeforeHookFn(nil), d.BeforeHooks...)
+	c.AfterHooks = append([]AfterHookFn(nil), d.AfterHooks...)
+	return &c
+}
+
 func (d *Deleter[T]) RegisterBeforeHooks(hooks ...BeforeHookFn) IDeleter[T] {
-	d.BeforeHooks = append(d.BeforeHooks, hooks...)
-	return d
+	c := d.Clone()
+	c.BeforeHooks = append(c.BeforeHooks, hooks...)
+	return c
 }
 
 func (d *Deleter[T]) RegisterAfterHooks(hooks ...AfterHookFn) IDeleter[T] {
-	d.AfterHooks = append(d.AfterHooks, hooks...)
-	return d
+	c := d.Clone()
+	c.AfterHooks = append(c.AfterHooks, hooks...)
+	return c
 }
 
 func (d *Deleter[T]) PreActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *OpContext, opType operation.OpType) error {
@@ -74,6 +109,8 @@ func (d *Deleter[T]) PreActionHandler(ctx context.Context, globalOpContext *oper
 	if err != nil {
 		return err
 	}
+	// the filter replaced by a callback is the one used by the hooks and the delete
+	opContext.Filter = globalOpContext.Filter
 	for _, beforeHook := range d.BeforeHooks {
 		err = beforeHook(ctx, opContext)
 		if err != nil {
@@ -99,25 +136,143 @@ func (d *Deleter[T]) PostActionHandler(ctx context.Context, globalOpContext *ope
 
 // Filter is used to set the filter of the query
 func (d *Deleter[T]) Filter(filter any) IDeleter[T] {
-	d.filter = filter
-	return d
+	c := d.Clone()
+	c.filter = filter
+	return c
+}
+
+// Where is used to add conditions to the filter, they are merged with the previous ones by query.Merge.
+// A condition can be a bson.D, a bson.M, a builder such as query.NewBuilder() or an example *T, see query.FromExample
+func (d *Deleter[T]) Where(conds ...any) IDeleter[T] {
+	c := d.Clone()
+	c.filter = query.Merge(append([]any{c.filter}, conds...)...)
+	return c
+}
+
+// OrWhere is used to set the filter to the previous filter or the conjunction of conds, see Where
+func (d *Deleter[T]) OrWhere(conds ...any) IDeleter[T] {
+	c := d.Clone()
+	c.filter = query.MergeOr(c.filter, query.Merge(conds...))
+	return c
+}
+
+// Not is used to add the negation of the conjunction of conds to the filter, with $nor, see Where
+func (d *Deleter[T]) Not(conds ...any) IDeleter[T] {
+	c := d.Clone()
+	if cond := query.Merge(conds...); len(cond) != 0 {
+		c.filter = query.Merge(c.filter, query.Nor(cond))
+	}
+	return c
+}
+
+// Scopes is used to add the conditions of scopes to the filter, see Where
+func (d *Deleter[T]) Scopes(scopes ...query.Scope) IDeleter[T] {
+	c := d.Clone()
+	c.filter = query.Merge(c.filter, query.ApplyScopes(scopes...))
+	return c
+}
+
+// DefaultScopes is used to set the scopes added to the filter of the delete methods unless Unscoped is called
+func (d *Deleter[T]) DefaultScopes(scopes ...query.Scope) *Deleter[T] {
+	c := d.Clone()
+	c.defaultScopes = scopes
+	return c
+}
+
+// Unscoped is used to skip the default scopes
+func (d *Deleter[T]) Unscoped() IDeleter[T] {
+	c := d.Clone()
+	c.unscoped = true
+	return c
+}
+
+// AllowFullCollection is used to allow the delete methods to be called with an empty filter, which matches all the documents.
+// Otherwise they return guard.ErrEmptyFilter, the default scopes don't count as a filter
+func (d *Deleter[T]) AllowFullCollection() IDeleter[T] {
+	c := d.Clone()
+	c.allowFullCollection = true
+	return c
+}
+
+// GuardEmptyFilter is used to enable or disable the guard returning guard.ErrEmptyFilter, enabled by default
+func (d *Deleter[T]) GuardEmptyFilter(enabled bool) *Deleter[T] {
+	c := d.Clone()
+	c.allowFullCollection = !enabled
+	return c
+}
+
+// Collation is used to set the collation of the delete methods
+func (d *Deleter[T]) Collation(collation *collation.Collation) IDeleter[T] {
+	c := d.Clone()
+	c.collation = collation
+	return c
+}
+
+// Hint is used to set the index of the delete methods, an index name or a specification such as sort.Asc("name")
+func (d *Deleter[T]) Hint(hint any) IDeleter[T] {
+	c := d.Clone()
+	if b, ok := hint.(interface{ Build() bson.D }); ok {
+		hint = b.Build()
+	}
+	c.driverOpts.Hint = hint
+	return c
+}
+
+// MaxTime is used to bound the duration of the delete methods by a context timeout
+func (d *Deleter[T]) MaxTime(maxTime time.Duration) IDeleter[T] {
+	c := d.Clone()
+	c.driverOpts.MaxTime = maxTime
+	return c
+}
+
+// Comment is used to set the comment of the delete methods
+func (d *Deleter[T]) Comment(comment any) IDeleter[T] {
+	c := d.Clone()
+	c.driverOpts.Comment = comment
+	return c
+}
+
+// Let is used to set the variables of the delete methods, which can be used as $$var in the filter with $expr
+func (d *Deleter[T]) Let(let any) IDeleter[T] {
+	c := d.Clone()
+	c.driverOpts.Let = let
+	return c
+}
+
+// WriteConcern is used to set the write concern of the delete methods
+func (d *Deleter[T]) WriteConcern(wc *writeconcern.WriteConcern) IDeleter[T] {
+	c := d.Clone()
+	c.driverOpts.SetWriteConcern(wc)
+	return c
 }
 
 func (d *Deleter[T]) ModelHook(modelHook any) IDeleter[T] {
-	d.modelHook = modelHook
-	return d
+	c := d.Clone()
+	c.modelHook = modelHook
+	return c
 }
 
 func (d *Deleter[T]) DeleteOne(ctx context.Context, opts ...options.Lister[options.DeleteOneOptions]) (*mongo.DeleteResult, error) {
+	if !d.allowFullCollection && guard.IsEmptyFilter(d.filter) {
+		return nil, guard.ErrEmptyFilter
+	}
 	currentTime := time.Now()
-	globalOpContext := operation.NewOpContext(d.collection, operation.WithFilter(d.filter), operation.WithMongoOptions(opts), operation.WithModelHook(d.modelHook), operation.WithFields(d.fields), operation.WithStartTime(currentTime))
-	opContext := NewOpContext(d.collection, d.filter, WithMongoOptions(opts), WithModelHook(d.modelHook), WithFields(d.fields), WithStartTime(currentTime))
+	filter := d.scopedFilter()
+	if d.collation != nil {
+		opts = append(opts, options.DeleteOne().SetCollation(d.collation.Options()))
+	}
+	opts = d.driverOpts.DeleteOne(opts)
+	ctx, cancel := d.driverOpts.Context(ctx)
+	defer cancel()
+	globalOpContext := operation.NewOpContext(d.collection, operation.WithFilter(filter), operation.WithMongoOptions(opts), operation.WithModelHook(d.modelHook), operation.WithFields(d.fields), operation.WithStartTime(currentTime))
+	opContext := NewOpContext(d.collection, filter, WithMongoOptions(opts), WithModelHook(d.modelHook), WithFields(d.fields), WithStartTime(currentTime))
 	err := d.PreActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeDelete)
 	if err != nil {
 		return nil, err
 	}
+	filter = opContext.Filter
 
-	result, err := d.collection.DeleteOne(ctx, d.filter, opts...)
+	result, err := d.driverOpts.Collection(d.collection).DeleteOne(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -133,15 +288,26 @@ func (d *Deleter[T]) DeleteOne(ctx context.Context, opts ...options.Lister[optio
 }
 
 func (d *Deleter[T]) DeleteMany(ctx context.Context, opts ...options.Lister[options.DeleteManyOptions]) (*mongo.DeleteResult, error) {
+	if !d.allowFullCollection && guard.IsEmptyFilter(d.filter) {
+		return nil, guard.ErrEmptyFilter
+	}
 	currentTime := time.Now()
-	globalOpContext := operation.NewOpContext(d.collection, operation.WithFilter(d.filter), operation.WithMongoOptions(opts), operation.WithModelHook(d.modelHook), operation.WithFields(d.fields), operation.WithStartTime(currentTime))
-	opContext := NewOpContext(d.collection, d.filter, WithMongoOptions(opts), WithModelHook(d.modelHook), WithFields(d.fields), WithStartTime(currentTime))
+	filter := d.scopedFilter()
+	if d.collation != nil {
+		opts = append(opts, options.DeleteMany().SetCollation(d.collation.Options()))
+	}
+	opts = d.driverOpts.DeleteMany(opts)
+	ctx, cancel := d.driverOpts.Context(ctx)
+	defer cancel()
+	globalOpContext := operation.NewOpContext(d.collection, operation.WithFilter(filter), operation.WithMongoOptions(opts), operation.WithModelHook(d.modelHook), operation.WithFields(d.fields), operation.WithStartTime(currentTime))
+	opContext := NewOpContext(d.collection, filter, WithMongoOptions(opts), WithModelHook(d.modelHook), WithFields(d.fields), WithStartTime(currentTime))
 	err := d.PreActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeDelete)
 	if err != nil {
 		return nil, err
 	}
+	filter = opContext.Filter
 
-	result, err := d.collection.DeleteMany(ctx, d.filter, opts...)
+	result, err := d.driverOpts.Collection(d.collection).DeleteMany(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +321,15 @@ func (d *Deleter[T]) DeleteMany(ctx context.Context, opts ...options.Lister[opti
 
 	return result, nil
 }
+
+// scopedFilter returns the filter with the conditions of the default scopes
+func (d *Deleter[T]) scopedFilter() any {
+	if d.unscoped || len(d.defaultScopes) == 0 {
+		return d.filter
+	}
+	return query.Merge(d.filter, query.ApplyScopes(d.defaultScopes...))
+}
+
 func (d *Deleter[T]) GetCollection() *mongo.Collection {
 	return d.collection
 }
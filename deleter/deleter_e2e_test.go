@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/guard"
 
 	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
 
@@ -92,14 +93,16 @@ func TestDeleter_e2e_DeleteOne(t *testing.T) {
 		wantError require.ErrorAssertionFunc
 	}{
 		{
-			name:      "error: nil filter",
-			before:    func(_ context.Context, _ *testing.T) {},
-			after:     func(_ context.Context, _ *testing.T) {},
-			filter:    nil,
-			ctx:       context.Background(),
-			opts:      []options.Lister[options.DeleteOneOptions]{options.DeleteOne().SetComment("test")},
-			want:      nil,
-			wantError: require.Error,
+			name:   "error: nil filter",
+			before: func(_ context.Context, _ *testing.T) {},
+			after:  func(_ context.Context, _ *testing.T) {},
+			filter: nil,
+			ctx:    context.Background(),
+			opts:   []options.Lister[options.DeleteOneOptions]{options.DeleteOne().SetComment("test")},
+			want:   nil,
+			wantError: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, guard.ErrEmptyFilter)
+			},
 		},
 		{
 			name: "deleted count: 0",
@@ -345,14 +348,16 @@ func TestDeleter_e2e_DeleteMany(t *testing.T) {
 		wantError require.ErrorAssertionFunc
 	}{
 		{
-			name:      "error: nil filter",
-			before:    func(_ context.Context, _ *testing.T) {},
-			after:     func(_ context.Context, _ *testing.T) {},
-			filter:    nil,
-			ctx:       context.Background(),
-			opts:      []options.Lister[options.DeleteManyOptions]{options.DeleteMany().SetComment("test")},
-			want:      nil,
-			wantError: require.Error,
+			name:   "error: nil filter",
+			before: func(_ context.Context, _ *testing.T) {},
+			after:  func(_ context.Context, _ *testing.T) {},
+			filter: nil,
+			ctx:    context.Background(),
+			opts:   []options.Lister[options.DeleteManyOptions]{options.DeleteMany().SetComment("test")},
+			want:   nil,
+			wantError: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, guard.ErrEmptyFilter)
+			},
 		},
 		{
 			name: "deleted count: 0",
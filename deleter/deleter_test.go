@@ -20,13 +20,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/collation"
 	deleter "github.com/chenmingyong0423/go-mongox/v2/deleter"
+	"github.com/chenmingyong0423/go-mongox/v2/guard"
 
 	mocks "github.com/chenmingyong0423/go-mongox/v2/mock"
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.uber.org/mock/gomock"
 )
 
@@ -49,6 +54,8 @@ func (tu *TestUser) DefaultUpdatedAt() {
 	tu.UpdatedAt = time.Now().Local()
 }
 
+var errStop = errors.New("stop")
+
 func TestDeleter_New(t *testing.T) {
 	mongoCollection := &mongo.Collection{}
 
@@ -185,3 +192,85 @@ func TestDeleter_DeleteMany(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleter_Collation(t *testing.T) {
+	var deleteOptions options.DeleteManyOptions
+	d := deleter.NewDeleter[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).AllowFullCollection().Collation(collation.New("fr")).RegisterBeforeHooks(func(ctx context.Context, opContext *deleter.OpContext, opts ...any) error {
+		for _, lister := range opContext.MongoOptions.([]options.Lister[options.DeleteManyOptions]) {
+			for _, set := range lister.List() {
+				assert.NoError(t, set(&deleteOptions))
+			}
+		}
+		return errStop
+	})
+
+	_, err := d.DeleteMany(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, &options.Collation{Locale: "fr"}, deleteOptions.Collation)
+}
+
+func TestDeleter_Where(t *testing.T) {
+	var filter any
+	d := deleter.NewDeleter[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).Where(bson.M{"status": 0}).Where(query.Lt("age", 18)).OrWhere(query.Exists("deleted_at", true)).
+		RegisterBeforeHooks(func(ctx context.Context, opContext *deleter.OpContext, opts ...any) error {
+			filter = opContext.Filter
+			return errStop
+		})
+
+	_, err := d.DeleteMany(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, query.Or(
+		bson.D{{Key: "status", Value: 0}, {Key: "age", Value: bson.D{{Key: "$lt", Value: 18}}}},
+		query.Exists("deleted_at", true),
+	), filter)
+}
+
+func TestDeleter_EmptyFilter(t *testing.T) {
+	testCases := []struct {
+		name    string
+		deleter deleter.IDeleter[any]
+		wantErr error
+	}{
+		{
+			name:    "nil filter",
+			deleter: deleter.NewDeleter[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil),
+			wantErr: guard.ErrEmptyFilter,
+		},
+		{
+			name:    "empty builder",
+			deleter: deleter.NewDeleter[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).Filter(query.NewBuilder()).Where(bson.M{}),
+			wantErr: guard.ErrEmptyFilter,
+		},
+		{
+			name:    "default scopes",
+			deleter: deleter.NewDeleter[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).DefaultScopes(func(b *query.Builder) *query.Builder { return b.Exists("deleted_at", false) }),
+			wantErr: guard.ErrEmptyFilter,
+		},
+		{
+			name:    "allow full collection",
+			deleter: deleter.NewDeleter[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).AllowFullCollection(),
+			wantErr: errStop,
+		},
+		{
+			name:    "guard disabled",
+			deleter: deleter.NewDeleter[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).GuardEmptyFilter(false),
+			wantErr: errStop,
+		},
+		{
+			name:    "filter",
+			deleter: deleter.NewDeleter[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).Filter(query.Id("1")),
+			wantErr: errStop,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := tc.deleter.RegisterBeforeHooks(func(ctx context.Context, opContext *deleter.OpContext, opts ...any) error {
+				return errStop
+			})
+			_, err := d.DeleteOne(context.Background())
+			assert.ErrorIs(t, err, tc.wantErr)
+			_, err = d.DeleteMany(context.Background())
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discriminator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const DefaultKey = "_type"
+
+var (
+	ErrUnknownType      = errors.New("mongox: unknown discriminator value")
+	ErrUnregisteredType = errors.New("mongox: type is not registered in the discriminator registry")
+)
+
+// Registry maps the values of a discriminator field to the concrete types stored in a collection,
+// so that the documents of a collection of an interface type can be decoded into the right type
+type Registry struct {
+	key    string
+	types  map[string]reflect.Type
+	values map[reflect.Type]string
+}
+
+// NewRegistry returns an empty registry of the discriminator field key, DefaultKey if empty
+func NewRegistry(key string) *Registry {
+	if key == "" {
+		key = DefaultKey
+	}
+	return &Registry{key: key, types: make(map[string]reflect.Type), values: make(map[reflect.Type]string)}
+}
+
+// Register maps value to the type of doc, a struct or a pointer to a struct, e.g. Register("click", Click{}).
+// It panics if doc isn't a struct or if value or the type are already registered.
+func (r *Registry) Register(value string, doc any) *Registry {
+	t := structType(reflect.TypeOf(doc))
+	if t == nil {
+		panic(fmt.Sprintf("mongox: discriminator value %q must be registered with a struct, got %T", value, doc))
+	}
+	if _, ok := r.types[value]; ok {
+		panic(fmt.Sprintf("mongox: discriminator value %q is already registered", value))
+	}
+	if _, ok := r.values[t]; ok {
+		panic(fmt.Sprintf("mongox: type %s is already registered", t))
+	}
+	r.types[value] = t
+	r.values[t] = value
+	return r
+}
+
+// Key returns the name of the discriminator field
+func (r *Registry) Key() string {
+	return r.key
+}
+
+// ValueOf returns the discriminator value of the type of doc, looking through pointers and interfaces
+func (r *Registry) ValueOf(doc any) (string, bool) {
+	v := reflect.ValueOf(doc)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "", false
+	}
+	return r.ValueFor(v.Type())
+}
+
+// ValueFor returns the discriminator value of t, a struct or a pointer to a struct
+func (r *Registry) ValueFor(t reflect.Type) (string, bool) {
+	value, ok := r.values[structType(t)]
+	return value, ok
+}
+
+// Filter returns the filter matching the documents of t, see ValueFor
+func (r *Registry) Filter(t reflect.Type) (bson.D, error) {
+	value, ok := r.ValueFor(t)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnregisteredType, t)
+	}
+	return bson.D{{Key: r.key, Value: value}}, nil
+}
+
+// Stamp returns insertDoc, the document inserted for doc, with the discriminator field set to the value of the type of doc.
+// insertDoc is returned unchanged if the type of doc isn't registered.
+func (r *Registry) Stamp(doc, insertDoc any) (any, error) {
+	value, ok := r.ValueOf(doc)
+	if !ok {
+		return insertDoc, nil
+	}
+	d, ok := insertDoc.(bson.D)
+	if !ok {
+		// the encoder can't marshal a pointer to an interface, e.g. the *T of a collection of an interface type
+		v := reflect.ValueOf(insertDoc)
+		for v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Interface {
+			v = v.Elem().Elem()
+		}
+		raw, err := bson.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if err = bson.Unmarshal(raw, &d); err != nil {
+			return nil, err
+		}
+	}
+	for i := range d {
+		if d[i].Key == r.key {
+			d[i].Value = value
+			return d, nil
+		}
+	}
+	return append(d, bson.E{Key: r.key, Value: value}), nil
+}
+
+// TypeOf returns the type registered for the discriminator value of raw
+func (r *Registry) TypeOf(raw bson.Raw) (reflect.Type, error) {
+	rawValue, err := raw.LookupErr(r.key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the document has no %s", ErrUnknownType, r.key)
+	}
+	value, ok := rawValue.StringValueOK()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, rawValue)
+	}
+	t, ok := r.types[value]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, value)
+	}
+	return t, nil
+}
+
+// Decode unmarshals raw into v, a pointer. If v points to an interface, a value of the type registered for the
+// discriminator value of raw is decoded and assigned to it as a pointer.
+func (r *Registry) Decode(raw bson.Raw, v any) error {
+	dest := reflect.ValueOf(v)
+	if dest.Kind() != reflect.Ptr || dest.IsNil() || dest.Elem().Kind() != reflect.Interface {
+		return bson.Unmarshal(raw, v)
+	}
+	dest = dest.Elem()
+
+	t, err := r.TypeOf(raw)
+	if err != nil {
+		return err
+	}
+	doc := reflect.New(t)
+	if err = bson.Unmarshal(raw, doc.Interface()); err != nil {
+		return err
+	}
+	if !doc.Type().AssignableTo(dest.Type()) {
+		return fmt.Errorf("mongox: type %s registered for %q doesn't implement %s", doc.Type(), r.values[t], dest.Type())
+	}
+	dest.Set(doc)
+	return nil
+}
+
+func structType(t reflect.Type) reflect.Type {
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
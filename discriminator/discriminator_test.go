@@ -0,0 +1,162 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discriminator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type event interface {
+	Name() string
+}
+
+type click struct {
+	X int `bson:"x"`
+}
+
+func (c *click) Name() string { return "click" }
+
+type view struct {
+	Page string `bson:"page"`
+}
+
+func (v view) Name() string { return "view" }
+
+type other struct{}
+
+func newRegistry() *Registry {
+	return NewRegistry("").Register("click", click{}).Register("view", &view{}).Register("other", other{})
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := newRegistry()
+	assert.Equal(t, DefaultKey, r.Key())
+	assert.Equal(t, "kind", NewRegistry("kind").Key())
+
+	assert.Panics(t, func() { r.Register("click", struct{}{}) })
+	assert.Panics(t, func() { r.Register("click2", &click{}) })
+	assert.Panics(t, func() { r.Register("int", 1) })
+	assert.Panics(t, func() { r.Register("nil", nil) })
+}
+
+func TestRegistry_ValueOf(t *testing.T) {
+	r := newRegistry()
+	var e event = &click{}
+
+	testCases := []struct {
+		name  string
+		doc   any
+		value string
+		ok    bool
+	}{
+		{name: "struct", doc: view{}, value: "view", ok: true},
+		{name: "pointer", doc: &click{}, value: "click", ok: true},
+		{name: "pointer to interface", doc: &e, value: "click", ok: true},
+		{name: "unregistered", doc: struct{}{}},
+		{name: "nil", doc: nil},
+		{name: "nil pointer", doc: (*click)(nil), value: "click", ok: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, ok := r.ValueOf(tc.doc)
+			assert.Equal(t, tc.value, value)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestRegistry_Filter(t *testing.T) {
+	r := newRegistry()
+	filter, err := r.Filter(reflect.TypeOf(&click{}))
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "_type", Value: "click"}}, filter)
+
+	_, err = r.Filter(reflect.TypeOf(1))
+	assert.ErrorIs(t, err, ErrUnregisteredType)
+}
+
+func TestRegistry_TypeOf(t *testing.T) {
+	r := newRegistry()
+	raw, err := bson.Marshal(bson.D{{Key: "_type", Value: "view"}})
+	require.NoError(t, err)
+	typ, err := r.TypeOf(raw)
+	require.NoError(t, err)
+	assert.Equal(t, reflect.TypeOf(view{}), typ)
+
+	raw, err = bson.Marshal(bson.D{{Key: "_type", Value: "unknown"}})
+	require.NoError(t, err)
+	_, err = r.TypeOf(raw)
+	assert.ErrorIs(t, err, ErrUnknownType)
+}
+
+func TestRegistry_Stamp(t *testing.T) {
+	r := newRegistry()
+	var e event = &click{X: 1}
+
+	testCases := []struct {
+		name      string
+		doc       any
+		insertDoc any
+		want      any
+	}{
+		{name: "struct", doc: view{Page: "home"}, insertDoc: view{Page: "home"}, want: bson.D{{Key: "page", Value: "home"}, {Key: "_type", Value: "view"}}},
+		{
+			name:      "encoded document",
+			doc:       &view{Page: "home"},
+			insertDoc: bson.D{{Key: "_type", Value: "wrong"}, {Key: "page", Value: "home"}},
+			want:      bson.D{{Key: "_type", Value: "view"}, {Key: "page", Value: "home"}},
+		},
+		{name: "pointer to interface", doc: &e, insertDoc: &e, want: bson.D{{Key: "x", Value: int32(1)}, {Key: "_type", Value: "click"}}},
+		{name: "unregistered", doc: &struct{ A int }{A: 1}, insertDoc: "unchanged", want: "unchanged"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.Stamp(tc.doc, tc.insertDoc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRegistry_Decode(t *testing.T) {
+	r := newRegistry()
+	marshal := func(d bson.D) bson.Raw {
+		raw, err := bson.Marshal(d)
+		require.NoError(t, err)
+		return raw
+	}
+
+	var e event
+	require.NoError(t, r.Decode(marshal(bson.D{{Key: "_type", Value: "click"}, {Key: "x", Value: 1}}), &e))
+	assert.Equal(t, &click{X: 1}, e)
+
+	require.NoError(t, r.Decode(marshal(bson.D{{Key: "_type", Value: "view"}, {Key: "page", Value: "home"}}), &e))
+	assert.Equal(t, &view{Page: "home"}, e)
+
+	var c click
+	require.NoError(t, r.Decode(marshal(bson.D{{Key: "x", Value: 2}}), &c))
+	assert.Equal(t, click{X: 2}, c)
+
+	assert.ErrorIs(t, r.Decode(marshal(bson.D{{Key: "_type", Value: "unknown"}}), &e), ErrUnknownType)
+	assert.ErrorIs(t, r.Decode(marshal(bson.D{{Key: "x", Value: 1}}), &e), ErrUnknownType)
+	assert.ErrorIs(t, r.Decode(marshal(bson.D{{Key: "_type", Value: 1}}), &e), ErrUnknownType)
+	assert.EqualError(t, r.Decode(marshal(bson.D{{Key: "_type", Value: "other"}}), &e),
+		"mongox: type *discriminator.other registered for \"other\" doesn't implement discriminator.event")
+}
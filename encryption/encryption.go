@@ -0,0 +1,383 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// BinarySubtype is the bson binary subtype used to store encrypted values
+const BinarySubtype byte = 0x80
+
+const (
+	version   byte = 1
+	nonceSize      = 12
+)
+
+var (
+	ErrInvalidCiphertext = errors.New("mongox: invalid ciphertext")
+	ErrInvalidResult     = errors.New("mongox: result must be a pointer to a slice")
+	// ErrEncryptedPath is returned for the update paths going through or into a nested encrypted field,
+	// as the encrypted fields are only encrypted as a whole, at the top level of the documents
+	ErrEncryptedPath = errors.New("mongox: encrypted fields can't be updated through a dotted path")
+)
+
+// Cipher encrypts and decrypts the fields tagged with `mongox:"encrypt"` using AES-GCM.
+//
+// Randomized fields use a random nonce, so the same plaintext never produces the same ciphertext.
+// Deterministic fields derive the nonce from the plaintext, which allows equality queries
+// through Eq and In. Deterministic values are always encrypted with the active key,
+// so documents written before a key rotation have to be re-encrypted to stay queryable.
+type Cipher struct {
+	provider KeyProvider
+}
+
+func NewCipher(provider KeyProvider) *Cipher {
+	return &Cipher{provider: provider}
+}
+
+// EncryptValue encrypts a single value
+func (c *Cipher) EncryptValue(ctx context.Context, value any, encryptType field.EncryptType) (bson.Binary, error) {
+	t, data, err := bson.MarshalValue(value)
+	if err != nil {
+		return bson.Binary{}, err
+	}
+	return c.encrypt(ctx, bson.RawValue{Type: t, Value: data}, encryptType)
+}
+
+// DecryptValue decrypts a value produced by EncryptValue
+func (c *Cipher) DecryptValue(ctx context.Context, bin bson.Binary) (bson.RawValue, error) {
+	if bin.Subtype != BinarySubtype {
+		return bson.RawValue{}, ErrInvalidCiphertext
+	}
+	data := bin.Data
+	// version | mode | key id length | key id | nonce | ciphertext
+	if len(data) < 3 || data[0] != version {
+		return bson.RawValue{}, ErrInvalidCiphertext
+	}
+	idLen := int(data[2])
+	headerLen := 3 + idLen
+	if len(data) < headerLen+nonceSize+1 {
+		return bson.RawValue{}, ErrInvalidCiphertext
+	}
+	key, err := c.provider.Key(ctx, string(data[3:headerLen]))
+	if err != nil {
+		return bson.RawValue{}, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return bson.RawValue{}, err
+	}
+	nonce := data[headerLen : headerLen+nonceSize]
+	plaintext, err := aead.Open(nil, nonce, data[headerLen+nonceSize:], data[:headerLen])
+	if err != nil {
+		return bson.RawValue{}, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	if len(plaintext) == 0 {
+		return bson.RawValue{}, ErrInvalidCiphertext
+	}
+	return bson.RawValue{Type: bson.Type(plaintext[0]), Value: plaintext[1:]}, nil
+}
+
+// Eq builds an equality query for a deterministically encrypted field
+func (c *Cipher) Eq(ctx context.Context, key string, value any) (bson.D, error) {
+	bin, err := c.EncryptValue(ctx, value, field.EncryptDeterministic)
+	if err != nil {
+		return nil, err
+	}
+	return query.Eq(key, bin), nil
+}
+
+// In builds an $in query for a deterministically encrypted field
+func (c *Cipher) In(ctx context.Context, key string, values ...any) (bson.D, error) {
+	bins := make([]bson.Binary, 0, len(values))
+	for _, value := range values {
+		bin, err := c.EncryptValue(ctx, value, field.EncryptDeterministic)
+		if err != nil {
+			return nil, err
+		}
+		bins = append(bins, bin)
+	}
+	return query.In(key, bins...), nil
+}
+
+// EncryptDocument marshals doc and encrypts the values of the encrypted fields.
+// The returned document can be passed to the driver in place of doc.
+func (c *Cipher) EncryptDocument(ctx context.Context, doc any, fields []*field.Filed) (bson.D, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	elements, err := bson.Raw(raw).Elements()
+	if err != nil {
+		return nil, err
+	}
+	encryptedFields := EncryptedFields(fields)
+	result := make(bson.D, 0, len(elements))
+	for _, element := range elements {
+		key, value := element.Key(), element.Value()
+		if encryptType, ok := encryptedFields[key]; ok && value.Type != bson.TypeNull {
+			bin, err := c.encrypt(ctx, value, encryptType)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, bson.E{Key: key, Value: bin})
+			continue
+		}
+		result = append(result, bson.E{Key: key, Value: value})
+	}
+	return result, nil
+}
+
+// DecryptDocument decrypts the values of the encrypted fields in raw
+func (c *Cipher) DecryptDocument(ctx context.Context, raw bson.Raw, fields []*field.Filed) (bson.Raw, error) {
+	encryptedFields := EncryptedFields(fields)
+	if len(encryptedFields) == 0 {
+		return raw, nil
+	}
+	elements, err := raw.Elements()
+	if err != nil {
+		return nil, err
+	}
+	result := make(bson.D, 0, len(elements))
+	for _, element := range elements {
+		key, value := element.Key(), element.Value()
+		if _, ok := encryptedFields[key]; ok {
+			if subtype, data, isBinary := value.BinaryOK(); isBinary && subtype == BinarySubtype {
+				value, err = c.DecryptValue(ctx, bson.Binary{Subtype: subtype, Data: data})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		result = append(result, bson.E{Key: key, Value: value})
+	}
+	return bson.Marshal(result)
+}
+
+// Decode decrypts raw and unmarshals it into v
+func (c *Cipher) Decode(ctx context.Context, raw bson.Raw, fields []*field.Filed, v any) error {
+	decrypted, err := c.DecryptDocument(ctx, raw, fields)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(decrypted, v)
+}
+
+// DecodeCursor behaves like mongo.Cursor.All and decrypts every document before decoding it.
+// results must be a pointer to a slice.
+func (c *Cipher) DecodeCursor(ctx context.Context, cursor *mongo.Cursor, fields []*field.Filed, results any) error {
+	resultsVal := reflect.ValueOf(results)
+	if resultsVal.Kind() != reflect.Ptr || resultsVal.Elem().Kind() != reflect.Slice {
+		return ErrInvalidResult
+	}
+	sliceVal := resultsVal.Elem()
+	elemType := sliceVal.Type().Elem()
+	sliceVal = sliceVal.Slice(0, 0)
+	for cursor.Next(ctx) {
+		elem := reflect.New(elemType)
+		if err := c.Decode(ctx, cursor.Current, fields, elem.Interface()); err != nil {
+			return err
+		}
+		sliceVal = reflect.Append(sliceVal, elem.Elem())
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	resultsVal.Elem().Set(sliceVal)
+	return nil
+}
+
+// EncryptUpdates returns a copy of updates with the values assigned to encrypted fields by $set and $setOnInsert encrypted,
+// updates is left unchanged
+func (c *Cipher) EncryptUpdates(ctx context.Context, updates any, fields []*field.Filed) (any, error) {
+	m, ok := updates.(bson.M)
+	if !ok || m == nil {
+		return updates, nil
+	}
+	encryptedFields := EncryptedFields(fields)
+	encrypted := make(bson.M, len(m))
+	for op, values := range m {
+		encrypted[op] = values
+	}
+	for _, op := range []string{"$set", "$setOnInsert"} {
+		var err error
+		switch values := m[op].(type) {
+		case bson.M:
+			encryptedValues := make(bson.M, len(values))
+			for key, value := range values {
+				if encryptedValues[key], err = c.encryptField(ctx, fields, encryptedFields, key, value); err != nil {
+					return nil, err
+				}
+			}
+			encrypted[op] = encryptedValues
+		case bson.D:
+			encryptedValues := make(bson.D, len(values))
+			for i, e := range values {
+				encryptedValues[i].Key = e.Key
+				if encryptedValues[i].Value, err = c.encryptField(ctx, fields, encryptedFields, e.Key, e.Value); err != nil {
+					return nil, err
+				}
+			}
+			encrypted[op] = encryptedValues
+		}
+	}
+	return encrypted, nil
+}
+
+// EncryptedFields returns the mongo field names of the encrypted fields, including the inlined ones
+func EncryptedFields(fields []*field.Filed) map[string]field.EncryptType {
+	result := make(map[string]field.EncryptType)
+	for _, fd := range fields {
+		if fd.InlinedFields != nil {
+			for k, v := range EncryptedFields(fd.InlinedFields) {
+				result[k] = v
+			}
+		} else if fd.Encrypt != 0 {
+			result[fd.MongoField] = fd.Encrypt
+		}
+	}
+	return result
+}
+
+// HasEncryptedFields reports whether any of the fields is encrypted
+func HasEncryptedFields(fields []*field.Filed) bool {
+	for _, fd := range fields {
+		if fd.Encrypt != 0 || HasEncryptedFields(fd.InlinedFields) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cipher) encryptField(ctx context.Context, fields []*field.Filed, encryptedFields map[string]field.EncryptType, key string, value any) (any, error) {
+	if strings.Contains(key, ".") && encryptedPath(fields, strings.Split(key, ".")) {
+		return nil, fmt.Errorf("%w: %s", ErrEncryptedPath, key)
+	}
+	encryptType, ok := encryptedFields[key]
+	if !ok || value == nil {
+		return value, nil
+	}
+	if bin, isBinary := value.(bson.Binary); isBinary && bin.Subtype == BinarySubtype {
+		return value, nil
+	}
+	return c.EncryptValue(ctx, value, encryptType)
+}
+
+// encryptedPath reports whether the path, split on the dots, goes through or into an encrypted field
+func encryptedPath(fields []*field.Filed, path []string) bool {
+	fd := lookupField(fields, path[0])
+	if fd == nil {
+		return false
+	}
+	if fd.Encrypt != 0 {
+		return true
+	}
+	path = path[1:]
+	t := fd.FieldType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		if len(path) == 0 {
+			return false
+		}
+		// skip the index or the positional operator of the elements
+		path = path[1:]
+		for t = t.Elem(); t.Kind() == reflect.Ptr; t = t.Elem() {
+		}
+	}
+	if len(path) == 0 || t.Kind() != reflect.Struct {
+		return false
+	}
+	return encryptedPath(field.ParseFields(reflect.New(t).Interface()), path)
+}
+
+// lookupField returns the field, including the inlined ones, whose mongo field name is name
+func lookupField(fields []*field.Filed, name string) *field.Filed {
+	for _, fd := range fields {
+		if fd.InlinedFields != nil {
+			if found := lookupField(fd.InlinedFields, name); found != nil {
+				return found
+			}
+		} else if fd.MongoField == name {
+			return fd
+		}
+	}
+	return nil
+}
+
+func (c *Cipher) encrypt(ctx context.Context, value bson.RawValue, encryptType field.EncryptType) (bson.Binary, error) {
+	keyID, key, err := c.provider.ActiveKey(ctx)
+	if err != nil {
+		return bson.Binary{}, err
+	}
+	if len(keyID) == 0 || len(keyID) > 255 {
+		return bson.Binary{}, ErrInvalidKeyID
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return bson.Binary{}, err
+	}
+
+	plaintext := make([]byte, 0, len(value.Value)+1)
+	plaintext = append(plaintext, byte(value.Type))
+	plaintext = append(plaintext, value.Value...)
+
+	header := make([]byte, 0, 3+len(keyID))
+	header = append(header, version, byte(encryptType), byte(len(keyID)))
+	header = append(header, keyID...)
+
+	nonce := make([]byte, nonceSize)
+	if encryptType == field.EncryptDeterministic {
+		// the nonce is a MAC of the plaintext keyed with a sub key, never with the encryption key itself
+		subKey := sha256.Sum256(append([]byte("mongox:deterministic:"), key...))
+		mac := hmac.New(sha256.New, subKey[:])
+		mac.Write(header)
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err = rand.Read(nonce); err != nil {
+		return bson.Binary{}, err
+	}
+
+	data := make([]byte, 0, len(header)+nonceSize+len(plaintext)+aead.Overhead())
+	data = append(data, header...)
+	data = append(data, nonce...)
+	data = aead.Seal(data, nonce, plaintext, header)
+	return bson.Binary{Subtype: BinarySubtype, Data: data}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,252 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type Base struct {
+	ID    bson.ObjectID `bson:"_id,omitempty"`
+	Token string        `bson:"token" mongox:"encrypt"`
+}
+
+type user struct {
+	Base  `bson:",inline"`
+	Name  string  `bson:"name"`
+	Email string  `bson:"email" mongox:"encrypt:deterministic"`
+	Age   int     `bson:"age" mongox:"encrypt"`
+	Phone *string `bson:"phone" mongox:"encrypt"`
+}
+
+func newTestCipher(t *testing.T) (*Cipher, *KeyRing) {
+	keyRing, err := NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	return NewCipher(keyRing), keyRing
+}
+
+func TestCipher_EncryptValue(t *testing.T) {
+	c, keyRing := newTestCipher(t)
+	ctx := context.Background()
+
+	randomized1, err := c.EncryptValue(ctx, "chenmingyong", field.EncryptRandomized)
+	require.NoError(t, err)
+	randomized2, err := c.EncryptValue(ctx, "chenmingyong", field.EncryptRandomized)
+	require.NoError(t, err)
+	assert.NotEqual(t, randomized1, randomized2)
+	assert.Equal(t, BinarySubtype, randomized1.Subtype)
+
+	deterministic1, err := c.EncryptValue(ctx, "chenmingyong", field.EncryptDeterministic)
+	require.NoError(t, err)
+	deterministic2, err := c.EncryptValue(ctx, "chenmingyong", field.EncryptDeterministic)
+	require.NoError(t, err)
+	assert.Equal(t, deterministic1, deterministic2)
+
+	value, err := c.DecryptValue(ctx, randomized1)
+	require.NoError(t, err)
+	assert.Equal(t, "chenmingyong", value.StringValue())
+
+	// values encrypted with an old key are still readable after a rotation
+	require.NoError(t, keyRing.Rotate("k2", bytes.Repeat([]byte{2}, 32)))
+	value, err = c.DecryptValue(ctx, deterministic1)
+	require.NoError(t, err)
+	assert.Equal(t, "chenmingyong", value.StringValue())
+	rotated, err := c.EncryptValue(ctx, "chenmingyong", field.EncryptDeterministic)
+	require.NoError(t, err)
+	assert.NotEqual(t, deterministic1, rotated)
+
+	// tampered ciphertext
+	tampered := bson.Binary{Subtype: BinarySubtype, Data: append([]byte{}, rotated.Data...)}
+	tampered.Data[len(tampered.Data)-1] ^= 0xff
+	_, err = c.DecryptValue(ctx, tampered)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = c.DecryptValue(ctx, bson.Binary{Subtype: 0x00, Data: rotated.Data})
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = NewCipher(&KeyRing{keys: map[string][]byte{}}).DecryptValue(ctx, rotated)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestCipher_Document(t *testing.T) {
+	c, _ := newTestCipher(t)
+	ctx := context.Background()
+	fields := field.ParseFields(&user{})
+
+	phone := "123456"
+	u := &user{Base: Base{ID: bson.NewObjectID(), Token: "token"}, Name: "chenmingyong", Email: "a@b.c", Age: 24, Phone: &phone}
+	doc, err := c.EncryptDocument(ctx, u, fields)
+	require.NoError(t, err)
+	require.Len(t, doc, 6)
+	for _, e := range doc {
+		switch e.Key {
+		case "_id", "name":
+			_, ok := e.Value.(bson.RawValue)
+			assert.True(t, ok, e.Key)
+		default:
+			bin, ok := e.Value.(bson.Binary)
+			assert.True(t, ok, e.Key)
+			assert.Equal(t, BinarySubtype, bin.Subtype)
+		}
+	}
+
+	eq, err := c.Eq(ctx, "email", "a@b.c")
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "email", Value: bson.D{{Key: "$eq", Value: doc[3].Value}}}}, eq)
+
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	got := &user{}
+	require.NoError(t, c.Decode(ctx, raw, fields, got))
+	assert.Equal(t, u, got)
+
+	// null values are left untouched, the zero _id is omitted
+	doc, err = c.EncryptDocument(ctx, &user{}, fields)
+	require.NoError(t, err)
+	assert.Equal(t, bson.TypeNull, doc[4].Value.(bson.RawValue).Type)
+
+	cursor, err := mongo.NewCursorFromDocuments([]any{raw, raw}, nil, nil)
+	require.NoError(t, err)
+	users := make([]*user, 0)
+	require.NoError(t, c.DecodeCursor(ctx, cursor, fields, &users))
+	assert.Equal(t, []*user{u, u}, users)
+
+	assert.ErrorIs(t, c.DecodeCursor(ctx, cursor, fields, users), ErrInvalidResult)
+}
+
+func TestCipher_EncryptUpdates(t *testing.T) {
+	c, _ := newTestCipher(t)
+	ctx := context.Background()
+	fields := field.ParseFields(&user{})
+
+	updates := bson.M{
+		"$set":         bson.M{"name": "chenmingyong", "email": "a@b.c"},
+		"$setOnInsert": bson.D{{Key: "token", Value: "token"}, {Key: "phone", Value: nil}},
+		"$inc":         bson.M{"age": 1},
+	}
+	encrypted, err := c.EncryptUpdates(ctx, updates, fields)
+	require.NoError(t, err)
+	assert.Equal(t, "a@b.c", updates["$set"].(bson.M)["email"])
+	assert.Equal(t, "token", updates["$setOnInsert"].(bson.D)[0].Value)
+	updates = encrypted.(bson.M)
+
+	set := updates["$set"].(bson.M)
+	assert.Equal(t, "chenmingyong", set["name"])
+	eq, err := c.Eq(ctx, "email", "a@b.c")
+	require.NoError(t, err)
+	assert.Equal(t, eq[0].Value.(bson.D)[0].Value, set["email"])
+
+	setOnInsert := updates["$setOnInsert"].(bson.D)
+	token, ok := setOnInsert[0].Value.(bson.Binary)
+	require.True(t, ok)
+	value, err := c.DecryptValue(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "token", value.StringValue())
+	assert.Nil(t, setOnInsert[1].Value)
+	assert.Equal(t, bson.M{"age": 1}, updates["$inc"])
+
+	// already encrypted values are not encrypted twice
+	encrypted, err = c.EncryptUpdates(ctx, updates, fields)
+	require.NoError(t, err)
+	assert.Equal(t, token, encrypted.(bson.M)["$setOnInsert"].(bson.D)[0].Value)
+
+	encrypted, err = c.EncryptUpdates(ctx, bson.D{}, fields)
+	assert.NoError(t, err)
+	assert.Equal(t, bson.D{}, encrypted)
+}
+
+func TestCipher_EncryptUpdates_EncryptedPath(t *testing.T) {
+	type address struct {
+		City   string `bson:"city"`
+		Street string `bson:"street" mongox:"encrypt"`
+	}
+	type customer struct {
+		user      `bson:",inline"`
+		Address   address    `bson:"address"`
+		Addresses []*address `bson:"addresses"`
+	}
+	c, _ := newTestCipher(t)
+	fields := field.ParseFields(&customer{})
+
+	testCases := []struct {
+		name    string
+		updates bson.M
+		wantErr error
+	}{
+		{name: "into an encrypted field", updates: bson.M{"$set": bson.M{"email.domain": "mongox.dev"}}, wantErr: ErrEncryptedPath},
+		{name: "inlined encrypted field", updates: bson.M{"$setOnInsert": bson.D{{Key: "token.id", Value: 1}}}, wantErr: ErrEncryptedPath},
+		{name: "nested encrypted field", updates: bson.M{"$set": bson.M{"address.street": "main"}}, wantErr: ErrEncryptedPath},
+		{name: "element of a slice", updates: bson.M{"$set": bson.M{"addresses.0.street": "main"}}, wantErr: ErrEncryptedPath},
+		{name: "positional operator", updates: bson.M{"$set": bson.M{"addresses.$.street": "main"}}, wantErr: ErrEncryptedPath},
+		{name: "nested plain field", updates: bson.M{"$set": bson.M{"address.city": "paris", "addresses.0.city": "paris"}}},
+		{name: "unknown path", updates: bson.M{"$set": bson.M{"meta.email": "a@b.c"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := c.EncryptUpdates(context.Background(), tc.updates, fields)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestKeyRing(t *testing.T) {
+	_, err := NewKeyRing("", bytes.Repeat([]byte{1}, 32))
+	assert.ErrorIs(t, err, ErrInvalidKeyID)
+	_, err = NewKeyRing("k1", []byte("short"))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	keyRing, err := NewKeyRing("k1", bytes.Repeat([]byte{1}, 16))
+	require.NoError(t, err)
+	assert.ErrorIs(t, keyRing.Add("k1", bytes.Repeat([]byte{1}, 16)), ErrKeyIDConflict)
+	require.NoError(t, keyRing.Add("k2", bytes.Repeat([]byte{2}, 24)))
+
+	id, _, err := keyRing.ActiveKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "k1", id)
+
+	require.NoError(t, keyRing.Rotate("k3", bytes.Repeat([]byte{3}, 32)))
+	id, key, err := keyRing.ActiveKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "k3", id)
+	assert.Equal(t, bytes.Repeat([]byte{3}, 32), key)
+
+	_, err = keyRing.Key(context.Background(), "k4")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestHasEncryptedFields(t *testing.T) {
+	assert.True(t, HasEncryptedFields(field.ParseFields(&user{})))
+	assert.True(t, HasEncryptedFields(field.ParseFields(&struct {
+		Base `bson:",inline"`
+	}{})))
+	assert.False(t, HasEncryptedFields(field.ParseFields(&struct {
+		Name string `bson:"name"`
+	}{})))
+	assert.Equal(t, map[string]field.EncryptType{
+		"token": field.EncryptRandomized,
+		"email": field.EncryptDeterministic,
+		"age":   field.EncryptRandomized,
+		"phone": field.EncryptRandomized,
+	}, EncryptedFields(field.ParseFields(&user{})))
+}
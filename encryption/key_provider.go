@@ -0,0 +1,125 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrKeyNotFound   = errors.New("mongox: encryption key not found")
+	ErrInvalidKey    = errors.New("mongox: encryption key must be 16, 24 or 32 bytes")
+	ErrInvalidKeyID  = errors.New("mongox: encryption key id must be between 1 and 255 bytes")
+	ErrNoActiveKey   = errors.New("mongox: no active encryption key")
+	ErrKeyIDConflict = errors.New("mongox: encryption key id already registered")
+)
+
+// KeyProvider supplies the keys used to encrypt and decrypt field values.
+// The id of the key used for encryption is stored alongside the ciphertext,
+// so keys that are no longer active must still be resolvable by Key to read old documents.
+type KeyProvider interface {
+	// ActiveKey returns the key used to encrypt new values
+	ActiveKey(ctx context.Context) (id string, key []byte, err error)
+	// Key returns the key registered with the given id
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+var _ KeyProvider = (*KeyRing)(nil)
+
+// KeyRing is an in-memory KeyProvider which supports key rotation.
+// It is safe for concurrent use.
+type KeyRing struct {
+	mu       sync.RWMutex
+	activeID string
+	keys     map[string][]byte
+}
+
+// NewKeyRing creates a KeyRing whose active key is the given one
+func NewKeyRing(id string, key []byte) (*KeyRing, error) {
+	if err := checkKey(id, key); err != nil {
+		return nil, err
+	}
+	return &KeyRing{
+		activeID: id,
+		keys:     map[string][]byte{id: copyKey(key)},
+	}, nil
+}
+
+// Add registers a key which can be used to decrypt existing values without activating it
+func (k *KeyRing) Add(id string, key []byte) error {
+	if err := checkKey(id, key); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("%w: %s", ErrKeyIDConflict, id)
+	}
+	k.keys[id] = copyKey(key)
+	return nil
+}
+
+// Rotate registers a new key and makes it the active one.
+// Previously registered keys are kept so existing documents can still be decrypted.
+func (k *KeyRing) Rotate(id string, key []byte) error {
+	if err := k.Add(id, key); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.activeID = id
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *KeyRing) ActiveKey(_ context.Context) (string, []byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[k.activeID]
+	if !ok {
+		return "", nil, ErrNoActiveKey
+	}
+	return k.activeID, key, nil
+}
+
+func (k *KeyRing) Key(_ context.Context, id string) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	return key, nil
+}
+
+func checkKey(id string, key []byte) error {
+	if len(id) == 0 || len(id) > 255 {
+		return ErrInvalidKeyID
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	default:
+		return ErrInvalidKey
+	}
+}
+
+func copyKey(key []byte) []byte {
+	k := make([]byte, len(key))
+	copy(k, key)
+	return k
+}
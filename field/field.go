@@ -15,7 +15,9 @@
 package field
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -28,15 +30,62 @@ type Filed struct {
 	FieldType      reflect.Type
 	AutoCreateTime TimeType
 	AutoUpdateTime TimeType
+	Encrypt        EncryptType
+	ValidateRules  []ValidateRule
+	Default        *DefaultValue
+	Ref            *Ref
 
 	InlinedFields []*Filed
 }
 
+// DefaultValue is the value of the default tag, e.g. `mongox:"default:active"`
+type DefaultValue struct {
+	// Value is the literal parsed into the type of the field, or into its element type for pointers
+	Value any
+	// Now means the default value is the current time
+	Now bool
+}
+
+// Get returns the default value, currentTime is used for `default:now`
+func (d *DefaultValue) Get(currentTime time.Time) any {
+	if d.Now {
+		return currentTime
+	}
+	return d.Value
+}
+
+// Ref describes the documents referenced by a field, e.g. `mongox:"ref:users,localField:author_id"`.
+// The field is filled by Finder.Preload with the documents of Collection whose ForeignField matches LocalField.
+type Ref struct {
+	Collection string
+	// LocalField is the mongo field name of the document holding the reference(s)
+	LocalField string
+	// ForeignField is the mongo field name of the referenced documents, _id by default
+	ForeignField string
+}
+
+// ValidateRule is a rule parsed from the validate tag, e.g. `validate:"required,min=1"`
+type ValidateRule struct {
+	Name  string
+	Param string
+}
+
 type (
 	// TimeType MONGOX time type
 	TimeType int64
 )
 
+type (
+	// EncryptType MONGOX client-side encryption mode
+	EncryptType int64
+)
+
+// Mongox encryption types
+const (
+	EncryptRandomized    EncryptType = 1
+	EncryptDeterministic EncryptType = 2
+)
+
 // Mongox time types
 const (
 	UnixTime        TimeType = 1
@@ -50,26 +99,46 @@ const (
 	UpdatedAt      = "UpdatedAt"
 	AutoCreateTime = "autoCreateTime"
 	AutoUpdateTime = "autoUpdateTime"
+	Encrypt        = "encrypt"
+	Default        = "default"
+	RefTag         = "ref"
+	LocalField     = "localField"
+	ForeignField   = "foreignField"
+	// DefaultNow is the literal of the default tag which stands for the current time
+	DefaultNow = "now"
 )
 
 var (
 	timeType = reflect.TypeOf(time.Time{})
 )
 
+// ParseFields parses the fields of doc, which must be a struct or a pointer to a struct.
+// The default value of a field is dropped if its literal can't be parsed into the field type,
+// use ParseFieldsStrict to get the error instead.
 func ParseFields[T any](doc T) []*Filed {
-	docType := reflect.TypeOf(doc)
+	fields, _ := parseFields(reflect.TypeOf(doc))
+	return fields
+}
+
+// ParseFieldsStrict is like ParseFields but returns an error if the tags of a field are invalid
+func ParseFieldsStrict[T any](doc T) ([]*Filed, error) {
+	return parseFields(reflect.TypeOf(doc))
+}
+
+func parseFields(docType reflect.Type) ([]*Filed, error) {
 	if docType == nil {
-		return nil
+		return nil, nil
 	}
 	if docType.Kind() == reflect.Ptr {
 		docType = docType.Elem()
 	}
 	if docType.Kind() != reflect.Struct {
-		return nil
+		return nil, nil
 	}
 	numField := docType.NumField()
 	fields := make([]*Filed, 0, numField)
 
+	var firstErr error
 	for i := 0; i < numField; i++ {
 		structField := docType.Field(i)
 		fd := &Filed{Name: structField.Name, FieldType: structField.Type}
@@ -77,16 +146,26 @@ func ParseFields[T any](doc T) []*Filed {
 		bsonTag := structField.Tag.Get("bson")
 		if structField.Anonymous {
 			if bsonTag == ",inline" {
-				fields = append(fields, &Filed{Name: structField.Name, FieldType: structField.Type, InlinedFields: ParseFields(reflect.New(structField.Type).Elem().Interface())})
+				inlinedFields, err := parseFields(structField.Type)
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				fields = append(fields, &Filed{Name: structField.Name, FieldType: structField.Type, InlinedFields: inlinedFields})
 				continue
 			}
 		}
 
 		fd.MongoField = getMongoField(bsonTag, structField.Name)
 
+		if validateTag := structField.Tag.Get("validate"); len(validateTag) > 0 {
+			fd.ValidateRules = parseValidateTag(validateTag)
+		}
+
 		tag := structField.Tag.Get("mongox")
 		if len(tag) > 0 {
-			parseTag(tag, fd)
+			if err := parseTag(tag, fd); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("mongox: field %s.%s: %w", docType.Name(), structField.Name, err)
+			}
 		} else if structField.Name == CreatedAt {
 			parseDefaultTimeType(structField, fd, func(timeType TimeType) {
 				fd.AutoCreateTime = timeType
@@ -100,7 +179,7 @@ func ParseFields[T any](doc T) []*Filed {
 		fields = append(fields, fd)
 	}
 
-	return fields
+	return fields, firstErr
 }
 
 func parseDefaultTimeType(structField reflect.StructField, fd *Filed, set func(timeType TimeType)) {
@@ -126,18 +205,47 @@ func getMongoField(bsonTag string, defaultValue string) string {
 	return split[0]
 }
 
-func parseTag(tag string, fd *Filed) {
+func parseTag(tag string, fd *Filed) error {
 	split := strings.Split(tag, ",")
+	ref := &Ref{}
 	for _, s := range split {
 		switch {
+		case strings.HasPrefix(s, RefTag+":"):
+			ref.Collection = strings.TrimPrefix(s, RefTag+":")
+		case strings.HasPrefix(s, LocalField+":"):
+			ref.LocalField = strings.TrimPrefix(s, LocalField+":")
+		case strings.HasPrefix(s, ForeignField+":"):
+			ref.ForeignField = strings.TrimPrefix(s, ForeignField+":")
+		case strings.HasPrefix(s, Default+":"):
+			defaultValue, err := parseDefaultValue(strings.TrimPrefix(s, Default+":"), fd.FieldType)
+			if err != nil {
+				return err
+			}
+			fd.Default = defaultValue
 		case s == "autoID":
 			fd.AutoID = true
 		case strings.HasPrefix(s, AutoCreateTime):
 			fd.AutoCreateTime = parseTimeType(s)
 		case strings.HasPrefix(s, AutoUpdateTime):
 			fd.AutoUpdateTime = parseTimeType(s)
+		case strings.HasPrefix(s, Encrypt):
+			encryptType, err := parseEncryptType(s)
+			if err != nil {
+				return err
+			}
+			fd.Encrypt = encryptType
 		}
 	}
+	if *ref != (Ref{}) {
+		if ref.Collection == "" || ref.LocalField == "" {
+			return fmt.Errorf("ref requires both the collection and the localField, got %q", tag)
+		}
+		if ref.ForeignField == "" {
+			ref.ForeignField = "_id"
+		}
+		fd.Ref = ref
+	}
+	return nil
 }
 
 func parseTimeType(tag string) TimeType {
@@ -156,3 +264,81 @@ func parseTimeType(tag string) TimeType {
 	}
 	return UnixSecond
 }
+
+// parseEncryptType parses the encrypt tag, randomized by default. An unknown mode is an error rather than
+// a field silently stored in plaintext.
+func parseEncryptType(tag string) (EncryptType, error) {
+	switch tag {
+	case Encrypt, Encrypt + ":randomized":
+		return EncryptRandomized, nil
+	case Encrypt + ":deterministic":
+		return EncryptDeterministic, nil
+	}
+	return 0, fmt.Errorf("invalid encryption mode %q, expected randomized or deterministic", tag)
+}
+
+func parseValidateTag(tag string) []ValidateRule {
+	split := strings.Split(tag, ",")
+	rules := make([]ValidateRule, 0, len(split))
+	for _, s := range split {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		name, param := s, ""
+		if idx := strings.Index(s, "="); idx >= 0 {
+			name, param = s[:idx], s[idx+1:]
+		}
+		rules = append(rules, ValidateRule{Name: name, Param: param})
+	}
+	return rules
+}
+
+func parseDefaultValue(literal string, fieldType reflect.Type) (*DefaultValue, error) {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType == timeType {
+		if literal == DefaultNow {
+			return &DefaultValue{Now: true}, nil
+		}
+		t, err := time.Parse(time.RFC3339, literal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default value %q for %s: %w", literal, fieldType, err)
+		}
+		return &DefaultValue{Value: t}, nil
+	}
+
+	value := reflect.New(fieldType).Elem()
+	var err error
+	switch fieldType.Kind() {
+	case reflect.String:
+		value.SetString(literal)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(literal); err == nil {
+			value.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(literal, 10, fieldType.Bits()); err == nil {
+			value.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(literal, 10, fieldType.Bits()); err == nil {
+			value.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(literal, fieldType.Bits()); err == nil {
+			value.SetFloat(f)
+		}
+	default:
+		return nil, fmt.Errorf("default value is not supported for %s", fieldType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid default value %q for %s: %w", literal, fieldType, err)
+	}
+	return &DefaultValue{Value: value.Interface()}, nil
+}
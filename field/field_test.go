@@ -323,6 +323,103 @@ func TestParseFields(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "encrypt tag",
+			doc: struct {
+				Email   string `bson:"email" mongox:"encrypt:deterministic"`
+				Phone   string `bson:"phone" mongox:"encrypt"`
+				Address string `bson:"address" mongox:"encrypt:randomized"`
+				Note    string `bson:"note" mongox:"encrypt:unknown"`
+			}{},
+			want: []*Filed{
+				{
+					Name:       "Email",
+					MongoField: "email",
+					FieldType:  reflect.TypeOf(""),
+					Encrypt:    EncryptDeterministic,
+				},
+				{
+					Name:       "Phone",
+					MongoField: "phone",
+					FieldType:  reflect.TypeOf(""),
+					Encrypt:    EncryptRandomized,
+				},
+				{
+					Name:       "Address",
+					MongoField: "address",
+					FieldType:  reflect.TypeOf(""),
+					Encrypt:    EncryptRandomized,
+				},
+				{
+					Name:       "Note",
+					MongoField: "note",
+					FieldType:  reflect.TypeOf(""),
+				},
+			},
+		},
+		{
+			name: "validate tag",
+			doc: struct {
+				Name   string `bson:"name" validate:"required, min=2,max=8,"`
+				Status string `bson:"status" validate:"oneof=active banned"`
+			}{},
+			want: []*Filed{
+				{
+					Name:       "Name",
+					MongoField: "name",
+					FieldType:  reflect.TypeOf(""),
+					ValidateRules: []ValidateRule{
+						{Name: "required"},
+						{Name: "min", Param: "2"},
+						{Name: "max", Param: "8"},
+					},
+				},
+				{
+					Name:          "Status",
+					MongoField:    "status",
+					FieldType:     reflect.TypeOf(""),
+					ValidateRules: []ValidateRule{{Name: "oneof", Param: "active banned"}},
+				},
+			},
+		},
+		{
+			name: "default tag",
+			doc: struct {
+				Status    string     `bson:"status" mongox:"default:active"`
+				Age       int8       `bson:"age" mongox:"default:18"`
+				Score     *float64   `bson:"score" mongox:"default:1.5"`
+				Enabled   bool       `bson:"enabled" mongox:"default:true"`
+				Count     uint       `bson:"count" mongox:"default:0"`
+				CreatedAt *time.Time `bson:"created_at" mongox:"default:now"`
+				StartAt   time.Time  `bson:"start_at" mongox:"default:2025-01-01T00:00:00Z"`
+				Invalid   int        `bson:"invalid" mongox:"default:abc"`
+			}{},
+			want: []*Filed{
+				{Name: "Status", MongoField: "status", FieldType: reflect.TypeOf(""), Default: &DefaultValue{Value: "active"}},
+				{Name: "Age", MongoField: "age", FieldType: reflect.TypeOf(int8(0)), Default: &DefaultValue{Value: int8(18)}},
+				{Name: "Score", MongoField: "score", FieldType: reflect.TypeOf(new(float64)), Default: &DefaultValue{Value: 1.5}},
+				{Name: "Enabled", MongoField: "enabled", FieldType: reflect.TypeOf(false), Default: &DefaultValue{Value: true}},
+				{Name: "Count", MongoField: "count", FieldType: reflect.TypeOf(uint(0)), Default: &DefaultValue{Value: uint(0)}},
+				{Name: "CreatedAt", MongoField: "created_at", FieldType: reflect.TypeOf(new(time.Time)), Default: &DefaultValue{Now: true}},
+				{Name: "StartAt", MongoField: "start_at", FieldType: reflect.TypeOf(time.Time{}), Default: &DefaultValue{Value: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}},
+				{Name: "Invalid", MongoField: "invalid", FieldType: reflect.TypeOf(0)},
+			},
+		},
+		{
+			name: "ref tag",
+			doc: struct {
+				AuthorID  string    `bson:"author_id"`
+				Author    *struct{} `bson:"-" mongox:"ref:users,localField:author_id"`
+				Comments  []string  `bson:"-" mongox:"ref:comments,localField:_id,foreignField:post_id"`
+				Reviewers []string  `bson:"-" mongox:"ref:users"`
+			}{},
+			want: []*Filed{
+				{Name: "AuthorID", MongoField: "author_id", FieldType: reflect.TypeOf("")},
+				{Name: "Author", MongoField: "-", FieldType: reflect.TypeOf(&struct{}{}), Ref: &Ref{Collection: "users", LocalField: "author_id", ForeignField: "_id"}},
+				{Name: "Comments", MongoField: "-", FieldType: reflect.TypeOf([]string{}), Ref: &Ref{Collection: "comments", LocalField: "_id", ForeignField: "post_id"}},
+				{Name: "Reviewers", MongoField: "-", FieldType: reflect.TypeOf([]string{})},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -331,3 +428,82 @@ func TestParseFields(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFieldsStrict(t *testing.T) {
+	type inlined struct {
+		Age int `bson:"age" mongox:"default:abc"`
+	}
+	testCases := []struct {
+		name    string
+		doc     any
+		wantErr string
+	}{
+		{
+			name: "valid",
+			doc: &struct {
+				Status string `bson:"status" mongox:"default:active"`
+			}{},
+		},
+		{
+			name: "invalid number",
+			doc: &struct {
+				Age int8 `bson:"age" mongox:"default:300"`
+			}{},
+			wantErr: `mongox: field .Age: invalid default value "300" for int8`,
+		},
+		{
+			name: "now for a non time field",
+			doc: &struct {
+				CreatedAt int64 `bson:"created_at" mongox:"default:now"`
+			}{},
+			wantErr: `mongox: field .CreatedAt: invalid default value "now" for int64`,
+		},
+		{
+			name: "unsupported type",
+			doc: &struct {
+				Tags []string `bson:"tags" mongox:"default:a"`
+			}{},
+			wantErr: "mongox: field .Tags: default value is not supported for []string",
+		},
+		{
+			name: "ref without localField",
+			doc: &struct {
+				Author *struct{} `bson:"-" mongox:"ref:users"`
+			}{},
+			wantErr: `mongox: field .Author: ref requires both the collection and the localField, got "ref:users"`,
+		},
+		{
+			name: "encryption modes",
+			doc: &struct {
+				Email string `bson:"email" mongox:"encrypt"`
+				Phone string `bson:"phone" mongox:"encrypt:deterministic"`
+				SSN   string `bson:"ssn" mongox:"encrypt:randomized"`
+			}{},
+		},
+		{
+			name: "unknown encryption mode",
+			doc: &struct {
+				Email string `bson:"email" mongox:"encrypt:determinstic"`
+			}{},
+			wantErr: `mongox: field .Email: invalid encryption mode "encrypt:determinstic", expected randomized or deterministic`,
+		},
+		{
+			name: "inlined struct",
+			doc: &struct {
+				inlined `bson:",inline"`
+			}{},
+			wantErr: `mongox: field inlined.Age: invalid default value "abc" for int`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := ParseFieldsStrict(tc.doc)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				require.NotEmpty(t, fields)
+				return
+			}
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package finder
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var ErrUnsupportedFinder = errors.New("mongox: FindAs and FindOneAs require a *finder.Finder")
+
+// FindAs is like Find but decodes the documents into R, e.g. a struct with a subset of the fields of T.
+// The projection set by Project is used, otherwise it is derived from R by query.ProjectionOf.
+// The hooks run with the []*R as the Doc of the operation.OpContext; the tracker and the preloads of f are not used.
+func FindAs[R any, T any](ctx context.Context, f IFinder[T], opts ...options.Lister[options.FindOptions]) ([]*R, error) {
+	fd, ok := f.(*Finder[T])
+	if !ok {
+		return nil, ErrUnsupportedFinder
+	}
+	if err := fd.buildErr(); err != nil {
+		return nil, err
+	}
+	currentTime := time.Now()
+	filter := fd.scopedFilter()
+	opts = fd.findOptions(opts, projectionAs[R](fd))
+	ctx, cancel := fd.driverOpts.Context(ctx)
+	defer cancel()
+
+	globalOpContext := operation.NewOpContext(fd.Collection, operation.WithFilter(filter), operation.WithMongoOptions(opts), operation.WithModelHook(fd.modelHook), operation.WithStartTime(currentTime), operation.WithFields(fd.fields))
+	opContext := NewOpContext(fd.Collection, filter, WithMongoOptions[T](opts), WithModelHook[T](fd.modelHook), WithStartTime[T](currentTime), WithFields[T](fd.fields))
+	err := fd.PreActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeFind)
+	if err != nil {
+		return nil, err
+	}
+	filter = opContext.Filter
+
+	cursor, err := fd.driverOpts.Collection(fd.Collection).Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	r := make([]*R, 0)
+	if fd.cipher != nil {
+		err = fd.cipher.DecodeCursor(ctx, cursor, fd.fields, &r)
+	} else {
+		err = cursor.All(ctx, &r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	globalOpContext.Result = cursor
+	globalOpContext.Doc = r
+	opContext.Result = cursor
+	err = fd.PostActionHandler(ctx, globalOpContext, opContext, operation.OpTypeAfterFind)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// FindOneAs is like FindOne but decodes the document into R, see FindAs
+func FindOneAs[R any, T any](ctx context.Context, f IFinder[T], opts ...options.Lister[options.FindOneOptions]) (*R, error) {
+	fd, ok := f.(*Finder[T])
+	if !ok {
+		return nil, ErrUnsupportedFinder
+	}
+	if err := fd.buildErr(); err != nil {
+		return nil, err
+	}
+	currentTime := time.Now()
+	filter := fd.scopedFilter()
+	opts = fd.findOneOptions(opts, projectionAs[R](fd))
+	ctx, cancel := fd.driverOpts.Context(ctx)
+	defer cancel()
+
+	globalOpContext := operation.NewOpContext(fd.Collection, operation.WithFilter(filter), operation.WithMongoOptions(opts), operation.WithModelHook(fd.modelHook), operation.WithStartTime(currentTime), operation.WithFields(fd.fields))
+	opContext := NewOpContext(fd.Collection, filter, WithMongoOptions[T](opts), WithModelHook[T](fd.modelHook), WithStartTime[T](currentTime), WithFields[T](fd.fields))
+	err := fd.PreActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeFind)
+	if err != nil {
+		return nil, err
+	}
+	filter = opContext.Filter
+
+	result := fd.driverOpts.Collection(fd.Collection).FindOne(ctx, filter, opts...)
+	r := new(R)
+	if fd.cipher != nil {
+		var raw bson.Raw
+		if raw, err = result.Raw(); err != nil {
+			return nil, err
+		}
+		err = fd.cipher.Decode(ctx, raw, fd.fields, r)
+	} else {
+		err = result.Decode(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	globalOpContext.Result = result
+	globalOpContext.Doc = r
+	opContext.Result = result
+	err = fd.PostActionHandler(ctx, globalOpContext, opContext, operation.OpTypeAfterFind)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func projectionAs[R any, T any](f *Finder[T]) any {
+	if f.projection != nil {
+		return f.projection
+	}
+	// a nil bson.D must not be returned as a non-nil any
+	if projection := query.ProjectionOf[R](); projection != nil {
+		return projection
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package finder
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/guard"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
+)
+
+const (
+	DefaultChunkSize   = 1000
+	DefaultConcurrency = 4
+)
+
+var ErrInvalidIDs = errors.New("mongox: ids must be a slice or an array")
+
+// FindByIDsOptions controls how FindByIDs splits the ids into queries
+type FindByIDsOptions struct {
+	// ChunkSize is the maximum number of ids of a query, DefaultChunkSize if not positive
+	ChunkSize int
+	// Concurrency is the maximum number of queries running at the same time, DefaultConcurrency if not positive
+	Concurrency int
+}
+
+// FindByIDs finds the documents whose _id is in ids, a slice or an array, combined with the filter of the finder.
+// The ids are split into chunks queried concurrently, the documents are returned in the order of ids,
+// duplicated ids are only returned once and the ids without document are returned in missing.
+// The sort, skip and limit of the finder are ignored, the hooks run for every chunk.
+func (f *Finder[T]) FindByIDs(ctx context.Context, ids any, opts ...*FindByIDsOptions) (docs []*T, missing []any, err error) {
+	idValues, err := uniqueIDs(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(idValues) == 0 {
+		return []*T{}, []any{}, nil
+	}
+	chunkSize, concurrency := DefaultChunkSize, DefaultConcurrency
+	if len(opts) > 0 && opts[0] != nil {
+		if opts[0].ChunkSize > 0 {
+			chunkSize = opts[0].ChunkSize
+		}
+		if opts[0].Concurrency > 0 {
+			concurrency = opts[0].Concurrency
+		}
+	}
+
+	chunks := make([][]any, 0, (len(idValues)+chunkSize-1)/chunkSize)
+	for start := 0; start < len(idValues); start += chunkSize {
+		end := start + chunkSize
+		if end > len(idValues) {
+			end = len(idValues)
+		}
+		chunks = append(chunks, idValues[start:end])
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		found    = make([]*T, 0, len(idValues))
+		sem      = make(chan struct{}, concurrency)
+	)
+	for _, chunk := range chunks {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(chunk []any) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result, err := f.chunkFinder(chunk).Find(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			found = append(found, result...)
+		}(chunk)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[string]*T, len(found))
+	fieldsOf := make(map[reflect.Type][]*field.Filed)
+	for _, doc := range found {
+		v, fields, ok := f.docStruct(doc, fieldsOf)
+		if !ok {
+			continue
+		}
+		id, ok := utils.IDValue(v, fields)
+		if !ok {
+			continue
+		}
+		key, err := utils.BsonKey(id.Interface())
+		if err != nil {
+			return nil, nil, err
+		}
+		byID[key] = doc
+	}
+	docs = make([]*T, 0, len(found))
+	missing = make([]any, 0)
+	for _, id := range idValues {
+		key, err := utils.BsonKey(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if doc, ok := byID[key]; ok {
+			docs = append(docs, doc)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return docs, missing, nil
+}
+
+// chunkFinder returns a copy of f querying the ids, so that the chunks can be queried concurrently
+func (f *Finder[T]) chunkFinder(ids []any) *Finder[T] {
+	c := f.Clone()
+	c.FilterObj = query.In("_id", ids...)
+	if !guard.IsEmptyFilter(f.FilterObj) {
+		c.FilterObj = query.And(f.FilterObj, c.FilterObj)
+	}
+	c.sort = nil
+	c.skip, c.limit = 0, 0
+	return c
+}
+
+// uniqueIDs returns the elements of ids without duplicates, in their original order
+func uniqueIDs(ids any) ([]any, error) {
+	v := reflect.ValueOf(ids)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, ErrInvalidIDs
+	}
+	result := make([]any, 0, v.Len())
+	seen := make(map[string]struct{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		id := v.Index(i).Interface()
+		key, err := utils.BsonKey(id)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, id)
+	}
+	return result, nil
+}
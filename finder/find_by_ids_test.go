@@ -0,0 +1,118 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package finder
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func Test_uniqueIDs(t *testing.T) {
+	id1, id2 := bson.NewObjectID(), bson.NewObjectID()
+	testCases := []struct {
+		name    string
+		ids     any
+		want    []any
+		wantErr error
+	}{
+		{name: "not a slice", ids: "id", wantErr: ErrInvalidIDs},
+		{name: "nil", ids: nil, wantErr: ErrInvalidIDs},
+		{name: "empty", ids: []string{}, want: []any{}},
+		{name: "object ids", ids: []bson.ObjectID{id2, id1, id2}, want: []any{id2, id1}},
+		{name: "array", ids: [3]int{3, 1, 3}, want: []any{3, 1}},
+		// the integral numbers are matched by value whatever their type
+		{name: "mixed types", ids: []any{1, "1", int32(1), int64(1), 1.0, 1}, want: []any{1, "1"}},
+		{name: "ints", ids: []int{2, 1, 2}, want: []any{2, 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := uniqueIDs(tc.ids)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFinder_chunkFinder(t *testing.T) {
+	f := NewFinder[any](&mongo.Collection{}, nil, nil)
+	assert.Equal(t, query.In[any]("_id", 1, 2), f.chunkFinder([]any{1, 2}).FilterObj)
+
+	f = f.Filter(query.Eq("name", "chenmingyong")).(*Finder[any])
+	chunk := f.chunkFinder([]any{1, 2})
+	assert.Equal(t, query.And(query.Eq("name", "chenmingyong"), query.In[any]("_id", 1, 2)), chunk.FilterObj)
+	assert.Equal(t, query.Eq("name", "chenmingyong"), f.FilterObj)
+	assert.Same(t, f.Collection, chunk.Collection)
+
+	notDeleted := func(b *query.Builder) *query.Builder { return b.Eq("deleted", false) }
+	f = f.Sort(bson.D{{Key: "name", Value: 1}}).Skip(1).Limit(1).(*Finder[any]).DefaultScopes(notDeleted)
+	chunk = f.chunkFinder([]any{1, 2})
+	assert.Nil(t, chunk.sort)
+	assert.Zero(t, chunk.skip)
+	assert.Zero(t, chunk.limit)
+	assert.Len(t, chunk.defaultScopes, 1)
+	assert.Equal(t, int64(1), f.limit)
+}
+
+func TestFinder_FindByIDs(t *testing.T) {
+	f := NewFinder[any](&mongo.Collection{}, nil, nil)
+
+	docs, missing, err := f.FindByIDs(context.Background(), []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*any{}, docs)
+	assert.Equal(t, []any{}, missing)
+
+	_, _, err = f.FindByIDs(context.Background(), "id")
+	assert.ErrorIs(t, err, ErrInvalidIDs)
+}
+
+func TestFinder_docStruct(t *testing.T) {
+	type click struct {
+		Button string        `bson:"button"`
+		ID     bson.ObjectID `bson:"_id"`
+	}
+	id := bson.NewObjectID()
+	fieldsOf := make(map[reflect.Type][]*field.Filed)
+
+	// the id of the document of an interface T is found with the fields of its concrete type
+	var event any = &click{ID: id}
+	v, fields, ok := NewFinder[any](&mongo.Collection{}, nil, nil).docStruct(&event, fieldsOf)
+	require.True(t, ok)
+	assert.Equal(t, field.ParseFields(click{}), fields)
+	assert.Len(t, fieldsOf, 1)
+	value, ok := utils.IDValue(v, fields)
+	require.True(t, ok)
+	assert.Equal(t, id, value.Interface())
+
+	var empty any
+	_, _, ok = NewFinder[any](&mongo.Collection{}, nil, nil).docStruct(&empty, fieldsOf)
+	assert.False(t, ok)
+
+	// the fields of the finder are used for a struct T
+	finderFields := field.ParseFields(click{})
+	v, fields, ok = NewFinder[click](&mongo.Collection{}, nil, finderFields).docStruct(&click{ID: id}, fieldsOf)
+	require.True(t, ok)
+	assert.Equal(t, reflect.TypeOf(click{}), v.Type())
+	assert.Equal(t, finderFields, fields)
+}
@@ -16,36 +16,68 @@ package finder
 
 import (
 	"context"
+	"reflect"
 	"time"
 
 	"github.com/chenmingyong0423/go-mongox/v2/bsonx"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/projection"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/discriminator"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
 	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/driveropt"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
+	"github.com/chenmingyong0423/go-mongox/v2/tracker"
 
 	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/collation"
 	"go.mongodb.org/mongo-driver/v2/bson"
 
 	"github.com/chenmingyong0423/go-mongox/v2/operation"
 
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 )
 
 //go:generate mockgen -source=finder.go -destination=../mock/finder.mock.go -package=mocks
 type IFinder[T any] interface {
 	FindOne(ctx context.Context, opts ...options.Lister[options.FindOneOptions]) (*T, error)
 	Find(ctx context.Context, opts ...options.Lister[options.FindOptions]) ([]*T, error)
+	FindByIDs(ctx context.Context, ids any, opts ...*FindByIDsOptions) (docs []*T, missing []any, err error)
 	Count(ctx context.Context, opts ...options.Lister[options.CountOptions]) (int64, error)
 	Distinct(ctx context.Context, fieldName string, opts ...options.Lister[options.DistinctOptions]) *mongo.DistinctResult
 	DistinctWithParse(ctx context.Context, fieldName string, result any, opts ...options.Lister[options.DistinctOptions]) error
 	Filter(filter any) IFinder[T]
+	Where(conds ...any) IFinder[T]
+	WhereExample(example *T, includeZero ...string) IFinder[T]
+	OrWhere(conds ...any) IFinder[T]
+	Not(conds ...any) IFinder[T]
+	Scopes(scopes ...query.Scope) IFinder[T]
+	Unscoped() IFinder[T]
 	FindOneAndUpdate(ctx context.Context, opts ...options.Lister[options.FindOneAndUpdateOptions]) (*T, error)
 	Limit(limit int64) IFinder[T]
+	Preload(paths ...string) IFinder[T]
 	ModelHook(modelHook any) IFinder[T]
 	RegisterAfterHooks(hooks ...AfterHookFn[T]) IFinder[T]
 	RegisterBeforeHooks(hooks ...BeforeHookFn[T]) IFinder[T]
 	Skip(skip int64) IFinder[T]
 	Sort(sort any) IFinder[T]
 	Updates(update any) IFinder[T]
+	Project(projection any) IFinder[T]
+	Collation(collation *collation.Collation) IFinder[T]
+	Hint(hint any) IFinder[T]
+	MaxTime(maxTime time.Duration) IFinder[T]
+	Comment(comment any) IFinder[T]
+	BatchSize(batchSize int32) IFinder[T]
+	AllowDiskUse(allowDiskUse bool) IFinder[T]
+	Let(let any) IFinder[T]
+	ReadPreference(rp *readpref.ReadPref) IFinder[T]
+	ReadConcern(rc *readconcern.ReadConcern) IFinder[T]
+	WriteConcern(wc *writeconcern.WriteConcern) IFinder[T]
+	Projection(builder *projection.Builder) IFinder[T]
 	PostActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *OpContext[T], opTypes ...operation.OpType) (err error)
 	PreActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *OpContext[T], opTypes ...operation.OpType) (err error)
 	GetCollection() *mongo.Collection
@@ -63,57 +95,268 @@ type Finder[T any] struct {
 	updates    any
 	modelHook  any
 
-	fields      []*field.Filed
-	DBCallbacks *callback.Callback
-	BeforeHooks []BeforeHookFn[T]
-	AfterHooks  []AfterHookFn[T]
+	fields  []*field.Filed
+	cipher  *encryption.Cipher
+	tracker *tracker.Tracker[T]
+	// discriminator decodes the documents into their concrete types when T is an interface
+	discriminator *discriminator.Registry
+	DBCallbacks   *callback.Callback
+	BeforeHooks   []BeforeHookFn[T]
+	AfterHooks    []AfterHookFn[T]
+
+	skip, limit   int64
+	sort          any
+	projection    any
+	projectionErr error
+	collation     *collation.Collation
+	driverOpts    driveropt.Options
+	preloads      []string
+
+	defaultScopes []query.Scope
+	unscoped      bool
+
+	// ofType is the discriminator condition added by OfType, ofTypeErr its error returned by the find methods
+	ofType    bson.D
+	ofTypeErr error
+}
+
+// Clone returns a copy of f. The fluent methods already return copies and the terminal methods don't modify f,
+// so a finder can be declared once, e.g. at package level, and shared by goroutines.
+func (f *Finder[T]) Clone() *Finder[T] {
+	c := *f
+	c.BeforeHooks = append([]BeforeHookFn[T](nil), f.BeforeHooks...)
+	c.AfterHooks = append([]AfterHookFn[T](nil), f.AfterHooks...)
+	c.preloads = append([]string(nil), f.preloads...)
+	return &c
+}
+
+// Cipher is used to decrypt the fields tagged with `mongox:"encrypt"` after the documents are found
+func (f *Finder[T]) Cipher(cipher *encryption.Cipher) *Finder[T] {
+	c := f.Clone()
+	c.cipher = cipher
+	return c
+}
+
+// Tracker is used to snapshot the documents found by FindOne and Find, so that their changes can be computed later.
+// The tracker carried by the context of the call, see tracker.NewContext, takes precedence.
+func (f *Finder[T]) Tracker(tracker *tracker.Tracker[T]) *Finder[T] {
+	c := f.Clone()
+	c.tracker = tracker
+	return c
+}
+
+func (f *Finder[T]) trackerFor(ctx context.Context) *tracker.Tracker[T] {
+	if t := tracker.FromContext[T](ctx); t != nil {
+		return t
+	}
+	return f.tracker
+}
 
-	skip, limit int64
-	sort        any
+// Discriminator is used to decode the documents into the types registered for their discriminator value when T is an interface
+func (f *Finder[T]) Discriminator(registry *discriminator.Registry) *Finder[T] {
+	c := f.Clone()
+	c.discriminator = registry
+	return c
 }
 
 func (f *Finder[T]) RegisterBeforeHooks(hooks ...BeforeHookFn[T]) IFinder[T] {
-	f.BeforeHooks = append(f.BeforeHooks, hooks...)
-	return f
+	c := f.Clone()
+	c.BeforeHooks = append(c.BeforeHooks, hooks...)
+	return c
 }
 
 // RegisterAfterHooks is used to set the after hooks of the query
 // If you register the hook for FindOne, the opContext.Docs will be nil
 // If you register the hook for Find, the opContext.Doc will be nil
 func (f *Finder[T]) RegisterAfterHooks(hooks ...AfterHookFn[T]) IFinder[T] {
-	f.AfterHooks = append(f.AfterHooks, hooks...)
-	return f
+	c := f.Clone()
+	c.AfterHooks = append(c.AfterHooks, hooks...)
+	return c
 }
 
 // Filter is used to set the filter of the query
 func (f *Finder[T]) Filter(filter any) IFinder[T] {
-	f.FilterObj = filter
-	return f
+	c := f.Clone()
+	c.FilterObj = filter
+	return c
+}
+
+// Where is used to add conditions to the filter, they are merged with the previous ones by query.Merge.
+// A condition can be a bson.D, a bson.M, a builder such as query.NewBuilder() or an example *T, see query.FromExample
+func (f *Finder[T]) Where(conds ...any) IFinder[T] {
+	c := f.Clone()
+	c.FilterObj = query.Merge(append([]any{c.FilterObj}, conds...)...)
+	return c
+}
+
+// WhereExample is used to set the filter of the query to the equality filter built from the non-zero fields of example,
+// see query.FromExample
+func (f *Finder[T]) WhereExample(example *T, includeZero ...string) IFinder[T] {
+	c := f.Clone()
+	c.FilterObj = query.FromExample(example, includeZero...)
+	return c
+}
+
+// OrWhere is used to set the filter to the previous filter or the conjunction of conds, see Where
+func (f *Finder[T]) OrWhere(conds ...any) IFinder[T] {
+	c := f.Clone()
+	c.FilterObj = query.MergeOr(c.FilterObj, query.Merge(conds...))
+	return c
+}
+
+// Not is used to add the negation of the conjunction of conds to the filter, with $nor, see Where
+func (f *Finder[T]) Not(conds ...any) IFinder[T] {
+	c := f.Clone()
+	if cond := query.Merge(conds...); len(cond) != 0 {
+		c.FilterObj = query.Merge(c.FilterObj, query.Nor(cond))
+	}
+	return c
+}
+
+// Scopes is used to add the conditions of scopes to the filter, see Where
+func (f *Finder[T]) Scopes(scopes ...query.Scope) IFinder[T] {
+	c := f.Clone()
+	c.FilterObj = query.Merge(c.FilterObj, query.ApplyScopes(scopes...))
+	return c
+}
+
+// DefaultScopes is used to set the scopes added to the filter of the find methods, Count and Distinct unless Unscoped is called
+func (f *Finder[T]) DefaultScopes(scopes ...query.Scope) *Finder[T] {
+	c := f.Clone()
+	c.defaultScopes = scopes
+	return c
+}
+
+// Unscoped is used to skip the default scopes
+func (f *Finder[T]) Unscoped() IFinder[T] {
+	c := f.Clone()
+	c.unscoped = true
+	return c
 }
 
 func (f *Finder[T]) Limit(limit int64) IFinder[T] {
-	f.limit = limit
-	return f
+	c := f.Clone()
+	c.limit = limit
+	return c
 }
 
 func (f *Finder[T]) Skip(skip int64) IFinder[T] {
-	f.skip = skip
-	return f
+	c := f.Clone()
+	c.skip = skip
+	return c
 }
 
+// Sort is used to set the sort of FindOne and Find, a bson document or a builder such as sort.Asc("name")
 func (f *Finder[T]) Sort(sort any) IFinder[T] {
-	f.sort = sort
-	return f
+	c := f.Clone()
+	if b, ok := sort.(interface{ Build() bson.D }); ok {
+		// the builder may be modified afterwards
+		sort = append(bson.D(nil), b.Build()...)
+	}
+	c.sort = sort
+	return c
+}
+
+// Collation is used to set the collation of the find methods, Count and Distinct
+func (f *Finder[T]) Collation(collation *collation.Collation) IFinder[T] {
+	c := f.Clone()
+	c.collation = collation
+	return c
+}
+
+// Hint is used to set the index of the find methods, Count and Distinct, an index name or a specification such as sort.Asc("name")
+func (f *Finder[T]) Hint(hint any) IFinder[T] {
+	c := f.Clone()
+	if b, ok := hint.(interface{ Build() bson.D }); ok {
+		hint = append(bson.D(nil), b.Build()...)
+	}
+	c.driverOpts.Hint = hint
+	return c
+}
+
+// MaxTime is used to bound the duration of the find methods, Count and Distinct by a context timeout
+func (f *Finder[T]) MaxTime(maxTime time.Duration) IFinder[T] {
+	c := f.Clone()
+	c.driverOpts.MaxTime = maxTime
+	return c
+}
+
+// Comment is used to set the comment of the find methods, Count and Distinct
+func (f *Finder[T]) Comment(comment any) IFinder[T] {
+	c := f.Clone()
+	c.driverOpts.Comment = comment
+	return c
+}
+
+// BatchSize is used to set the number of documents of each batch returned by the server to Find
+func (f *Finder[T]) BatchSize(batchSize int32) IFinder[T] {
+	c := f.Clone()
+	c.driverOpts.BatchSize = &batchSize
+	return c
+}
+
+// AllowDiskUse is used to allow Find to write temporary data to disk, e.g. for a large sort
+func (f *Finder[T]) AllowDiskUse(allowDiskUse bool) IFinder[T] {
+	c := f.Clone()
+	c.driverOpts.AllowDiskUse = &allowDiskUse
+	return c
+}
+
+// Let is used to set the variables of Find and FindOneAndUpdate, which can be used as $$var in the filter with $expr
+func (f *Finder[T]) Let(let any) IFinder[T] {
+	c := f.Clone()
+	c.driverOpts.Let = let
+	return c
+}
+
+// ReadPreference is used to set the read preference of the find methods, Count and Distinct
+func (f *Finder[T]) ReadPreference(rp *readpref.ReadPref) IFinder[T] {
+	c := f.Clone()
+	c.driverOpts.SetReadPreference(rp)
+	return c
+}
+
+// ReadConcern is used to set the read concern of the find methods, Count and Distinct
+func (f *Finder[T]) ReadConcern(rc *readconcern.ReadConcern) IFinder[T] {
+	c := f.Clone()
+	c.driverOpts.SetReadConcern(rc)
+	return c
+}
+
+// WriteConcern is used to set the write concern of FindOneAndUpdate
+func (f *Finder[T]) WriteConcern(wc *writeconcern.WriteConcern) IFinder[T] {
+	c := f.Clone()
+	c.driverOpts.SetWriteConcern(wc)
+	return c
+}
+
+// Project is used to set the projection of FindOne, Find and FindOneAndUpdate, see FindAs for decoding it into another type
+func (f *Finder[T]) Project(projection any) IFinder[T] {
+	c := f.Clone()
+	c.projection = projection
+	c.projectionErr = nil
+	return c
+}
+
+// Projection is like Project with the projection built by builder,
+// its validation error (see projection.Validate) is returned by the find methods
+func (f *Finder[T]) Projection(builder *projection.Builder) IFinder[T] {
+	c := f.Clone()
+	c.projection = builder.Build()
+	c.projectionErr = builder.Err()
+	return c
 }
 
 func (f *Finder[T]) Updates(update any) IFinder[T] {
-	f.updates = update
-	return f
+	c := f.Clone()
+	c.updates = update
+	return c
 }
 
 func (f *Finder[T]) ModelHook(modelHook any) IFinder[T] {
-	f.modelHook = modelHook
-	return f
+	c := f.Clone()
+	c.modelHook = modelHook
+	return c
 }
 
 func (f *Finder[T]) PreActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *OpContext[T], opTypes ...operation.OpType) (err error) {
@@ -123,6 +366,8 @@ func (f *Finder[T]) PreActionHandler(ctx context.Context, globalOpContext *opera
 			return
 		}
 	}
+	// the callbacks can replace the filter, e.g. to sanitize it, the hooks and the operation use the new one
+	opContext.Filter = globalOpContext.Filter
 	for _, beforeHook := range f.BeforeHooks {
 		err = beforeHook(ctx, opContext)
 		if err != nil {
@@ -149,25 +394,38 @@ func (f *Finder[T]) PostActionHandler(ctx context.Context, globalOpContext *oper
 }
 
 func (f *Finder[T]) FindOne(ctx context.Context, opts ...options.Lister[options.FindOneOptions]) (*T, error) {
-	currentTime := time.Now()
-	if f.sort != nil {
-		opts = append(opts, options.FindOne().SetSort(f.sort))
+	if err := f.buildErr(); err != nil {
+		return nil, err
 	}
+	currentTime := time.Now()
+	filter := f.scopedFilter()
+	opts = f.findOneOptions(opts, f.projection)
+	ctx, cancel := f.driverOpts.Context(ctx)
+	defer cancel()
 
 	t := new(T)
 
-	globalOpContext := operation.NewOpContext(f.Collection, operation.WithFilter(f.FilterObj), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook), operation.WithStartTime(currentTime), operation.WithFields(f.fields))
-	opContext := NewOpContext(f.Collection, f.FilterObj, WithMongoOptions[T](opts), WithModelHook[T](f.modelHook), WithStartTime[T](currentTime), WithFields[T](f.fields))
+	globalOpContext := operation.NewOpContext(f.Collection, operation.WithFilter(filter), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook), operation.WithStartTime(currentTime), operation.WithFields(f.fields))
+	opContext := NewOpContext(f.Collection, filter, WithMongoOptions[T](opts), WithModelHook[T](f.modelHook), WithStartTime[T](currentTime), WithFields[T](f.fields))
 	err := f.PreActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeFind)
 	if err != nil {
 		return nil, err
 	}
+	filter = opContext.Filter
 
-	result := f.Collection.FindOne(ctx, f.FilterObj, opts...)
-	err = result.Decode(t)
+	result := f.driverOpts.Collection(f.Collection).FindOne(ctx, filter, opts...)
+	err = f.decodeResult(ctx, result, t)
 	if err != nil {
 		return nil, err
 	}
+	if tracker := f.trackerFor(ctx); tracker != nil {
+		if err = tracker.Track(t); err != nil {
+			return nil, err
+		}
+	}
+	if err = f.preload(ctx, t); err != nil {
+		return nil, err
+	}
 
 	globalOpContext.Result = result
 	globalOpContext.Doc = t
@@ -182,34 +440,39 @@ func (f *Finder[T]) FindOne(ctx context.Context, opts ...options.Lister[options.
 }
 
 func (f *Finder[T]) Find(ctx context.Context, opts ...options.Lister[options.FindOptions]) ([]*T, error) {
-	currentTime := time.Now()
-
-	if f.sort != nil {
-		opts = append(opts, options.Find().SetSort(f.sort))
-	}
-	if f.skip != 0 {
-		opts = append(opts, options.Find().SetSkip(f.skip))
-	}
-	if f.limit != 0 {
-		opts = append(opts, options.Find().SetLimit(f.limit))
+	if err := f.buildErr(); err != nil {
+		return nil, err
 	}
+	currentTime := time.Now()
+	filter := f.scopedFilter()
+	opts = f.findOptions(opts, f.projection)
+	ctx, cancel := f.driverOpts.Context(ctx)
+	defer cancel()
 
 	t := make([]*T, 0)
 
-	globalOpContext := operation.NewOpContext(f.Collection, operation.WithFilter(f.FilterObj), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook), operation.WithStartTime(currentTime), operation.WithFields(f.fields))
-	opContext := NewOpContext(f.Collection, f.FilterObj, WithMongoOptions[T](opts), WithModelHook[T](f.modelHook), WithStartTime[T](currentTime), WithFields[T](f.fields))
+	globalOpContext := operation.NewOpContext(f.Collection, operation.WithFilter(filter), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook), operation.WithStartTime(currentTime), operation.WithFields(f.fields))
+	opContext := NewOpContext(f.Collection, filter, WithMongoOptions[T](opts), WithModelHook[T](f.modelHook), WithStartTime[T](currentTime), WithFields[T](f.fields))
 	err := f.PreActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeFind)
 	if err != nil {
 		return nil, err
 	}
+	filter = opContext.Filter
 
-	cursor, err := f.Collection.Find(ctx, f.FilterObj, opts...)
+	cursor, err := f.driverOpts.Collection(f.Collection).Find(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
-	err = cursor.All(ctx, &t)
-	if err != nil {
+	if err = f.decodeCursor(ctx, cursor, &t); err != nil {
+		return nil, err
+	}
+	if tracker := f.trackerFor(ctx); tracker != nil {
+		if err = tracker.Track(t...); err != nil {
+			return nil, err
+		}
+	}
+	if err = f.preload(ctx, t...); err != nil {
 		return nil, err
 	}
 
@@ -226,17 +489,36 @@ func (f *Finder[T]) Find(ctx context.Context, opts ...options.Lister[options.Fin
 }
 
 func (f *Finder[T]) Count(ctx context.Context, opts ...options.Lister[options.CountOptions]) (int64, error) {
-	return f.Collection.CountDocuments(ctx, f.FilterObj, opts...)
+	if f.ofTypeErr != nil {
+		return 0, f.ofTypeErr
+	}
+	if f.collation != nil {
+		opts = append(opts, options.Count().SetCollation(f.collation.Options()))
+	}
+	opts = f.driverOpts.Count(opts)
+	ctx, cancel := f.driverOpts.Context(ctx)
+	defer cancel()
+	return f.driverOpts.Collection(f.Collection).CountDocuments(ctx, f.scopedFilter(), opts...)
 }
 
 func (f *Finder[T]) Distinct(ctx context.Context, fieldName string, opts ...options.Lister[options.DistinctOptions]) *mongo.DistinctResult {
-	return f.Collection.Distinct(ctx, fieldName, f.FilterObj, opts...)
+	if f.collation != nil {
+		opts = append(opts, options.Distinct().SetCollation(f.collation.Options()))
+	}
+	opts = f.driverOpts.Distinct(opts)
+	ctx, cancel := f.driverOpts.Context(ctx)
+	defer cancel()
+	var filter any = errFilter{err: f.ofTypeErr}
+	if f.ofTypeErr == nil {
+		filter = f.scopedFilter()
+	}
+	return f.driverOpts.Collection(f.Collection).Distinct(ctx, fieldName, filter, opts...)
 }
 
 // DistinctWithParse is used to parse the result of Distinct
 // result must be a pointer
 func (f *Finder[T]) DistinctWithParse(ctx context.Context, fieldName string, result any, opts ...options.Lister[options.DistinctOptions]) error {
-	distinctResult := f.Collection.Distinct(ctx, fieldName, f.FilterObj, opts...)
+	distinctResult := f.Distinct(ctx, fieldName, opts...)
 	if distinctResult.Err() != nil {
 		return distinctResult.Err()
 	}
@@ -248,24 +530,44 @@ func (f *Finder[T]) DistinctWithParse(ctx context.Context, fieldName string, res
 }
 
 func (f *Finder[T]) FindOneAndUpdate(ctx context.Context, opts ...options.Lister[options.FindOneAndUpdateOptions]) (*T, error) {
+	if err := f.buildErr(); err != nil {
+		return nil, err
+	}
 	currentTime := time.Now()
+	filter := f.scopedFilter()
 	t := new(T)
+	if f.projection != nil {
+		opts = append(opts, options.FindOneAndUpdate().SetProjection(f.projection))
+	}
+	if f.collation != nil {
+		opts = append(opts, options.FindOneAndUpdate().SetCollation(f.collation.Options()))
+	}
+	opts = f.driverOpts.FindOneAndUpdate(opts)
+	ctx, cancel := f.driverOpts.Context(ctx)
+	defer cancel()
 
-	updates := bsonx.ToBsonM(f.updates)
-	if len(updates) != 0 {
-		f.updates = updates
+	updates := f.updates
+	if m := bsonx.ToBsonM(f.updates); len(m) != 0 {
+		updates = utils.CopyUpdates(m)
 	}
 
-	globalOpContext := operation.NewOpContext(f.Collection, operation.WithFilter(f.FilterObj), operation.WithUpdates(f.updates), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook), operation.WithStartTime(currentTime), operation.WithFields(f.fields))
-	opContext := NewOpContext(f.Collection, f.FilterObj, WithUpdates[T](f.updates), WithMongoOptions[T](opts), WithModelHook[T](f.modelHook), WithStartTime[T](currentTime), WithFields[T](f.fields))
+	globalOpContext := operation.NewOpContext(f.Collection, operation.WithFilter(filter), operation.WithUpdates(updates), operation.WithMongoOptions(opts), operation.WithModelHook(f.modelHook), operation.WithStartTime(currentTime), operation.WithFields(f.fields))
+	opContext := NewOpContext(f.Collection, filter, WithUpdates[T](updates), WithMongoOptions[T](opts), WithModelHook[T](f.modelHook), WithStartTime[T](currentTime), WithFields[T](f.fields))
 
 	err := f.PreActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeFind, operation.OpTypeBeforeUpdate)
 	if err != nil {
 		return nil, err
 	}
+	filter = opContext.Filter
 
-	result := f.Collection.FindOneAndUpdate(ctx, f.FilterObj, f.updates, opts...)
-	err = result.Decode(t)
+	if f.cipher != nil {
+		if updates, err = f.cipher.EncryptUpdates(ctx, updates, f.fields); err != nil {
+			return nil, err
+		}
+	}
+
+	result := f.driverOpts.Collection(f.Collection).FindOneAndUpdate(ctx, filter, updates, opts...)
+	err = f.decodeResult(ctx, result, t)
 	if err != nil {
 		return nil, err
 	}
@@ -282,6 +584,146 @@ func (f *Finder[T]) FindOneAndUpdate(ctx context.Context, opts ...options.Lister
 	return t, nil
 }
 
+// findOneOptions appends the options set on the finder to opts
+func (f *Finder[T]) findOneOptions(opts []options.Lister[options.FindOneOptions], projection any) []options.Lister[options.FindOneOptions] {
+	if f.sort != nil {
+		opts = append(opts, options.FindOne().SetSort(f.sort))
+	}
+	if f.skip != 0 {
+		opts = append(opts, options.FindOne().SetSkip(f.skip))
+	}
+	if projection != nil {
+		opts = append(opts, options.FindOne().SetProjection(projection))
+	}
+	if f.collation != nil {
+		opts = append(opts, options.FindOne().SetCollation(f.collation.Options()))
+	}
+	return f.driverOpts.FindOne(opts)
+}
+
+// findOptions appends the options set on the finder to opts
+func (f *Finder[T]) findOptions(opts []options.Lister[options.FindOptions], projection any) []options.Lister[options.FindOptions] {
+	if f.sort != nil {
+		opts = append(opts, options.Find().SetSort(f.sort))
+	}
+	if f.skip != 0 {
+		opts = append(opts, options.Find().SetSkip(f.skip))
+	}
+	if f.limit != 0 {
+		opts = append(opts, options.Find().SetLimit(f.limit))
+	}
+	if projection != nil {
+		opts = append(opts, options.Find().SetProjection(projection))
+	}
+	if f.collation != nil {
+		opts = append(opts, options.Find().SetCollation(f.collation.Options()))
+	}
+	return f.driverOpts.Find(opts)
+}
+
+// scopedFilter returns the filter with the discriminator condition of OfType and the conditions of the default scopes
+func (f *Finder[T]) scopedFilter() any {
+	filter := f.FilterObj
+	if f.ofType != nil {
+		filter = query.Merge(filter, f.ofType)
+	}
+	if f.unscoped || len(f.defaultScopes) == 0 {
+		return filter
+	}
+	return query.Merge(filter, query.ApplyScopes(f.defaultScopes...))
+}
+
+// buildErr returns the error of the fluent calls, e.g. of an invalid projection, returned by the find methods
+func (f *Finder[T]) buildErr() error {
+	if f.projectionErr != nil {
+		return f.projectionErr
+	}
+	return f.ofTypeErr
+}
+
+// errFilter fails to be marshaled with err, so that the methods returning a driver result such as Distinct return err
+type errFilter struct {
+	err error
+}
+
+func (f errFilter) MarshalBSON() ([]byte, error) {
+	return nil, f.err
+}
+
 func (f *Finder[T]) GetCollection() *mongo.Collection {
 	return f.Collection
 }
+
+func (f *Finder[T]) decodeResult(ctx context.Context, result *mongo.SingleResult, t *T) error {
+	if f.cipher == nil && !f.polymorphic() {
+		return result.Decode(t)
+	}
+	raw, err := result.Raw()
+	if err != nil {
+		return err
+	}
+	return f.decodeRaw(ctx, raw, t)
+}
+
+// decodeRaw decrypts raw and decodes it into t, the polymorphic documents being decrypted with the fields of their type
+func (f *Finder[T]) decodeRaw(ctx context.Context, raw bson.Raw, t *T) error {
+	if !f.polymorphic() {
+		return f.cipher.Decode(ctx, raw, f.fields, t)
+	}
+	if f.cipher != nil {
+		typ, err := f.discriminator.TypeOf(raw)
+		if err != nil {
+			return err
+		}
+		if raw, err = f.cipher.DecryptDocument(ctx, raw, field.ParseFields(reflect.New(typ).Interface())); err != nil {
+			return err
+		}
+	}
+	return f.discriminator.Decode(raw, t)
+}
+
+func (f *Finder[T]) decodeCursor(ctx context.Context, cursor *mongo.Cursor, t *[]*T) error {
+	if f.polymorphic() {
+		for cursor.Next(ctx) {
+			doc := new(T)
+			if err := f.decodeRaw(ctx, cursor.Current, doc); err != nil {
+				return err
+			}
+			*t = append(*t, doc)
+		}
+		return cursor.Err()
+	}
+	if f.cipher != nil {
+		return f.cipher.DecodeCursor(ctx, cursor, f.fields, t)
+	}
+	return cursor.All(ctx, t)
+}
+
+// docStruct returns the struct doc points to with its fields, which are the ones of the concrete type of the
+// document for an interface T. fieldsOf caches the fields of the concrete types.
+func (f *Finder[T]) docStruct(doc *T, fieldsOf map[reflect.Type][]*field.Filed) (reflect.Value, []*field.Filed, bool) {
+	v := reflect.ValueOf(doc)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, nil, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, nil, false
+	}
+	if reflect.TypeOf((*T)(nil)).Elem().Kind() != reflect.Interface {
+		return v, f.fields, true
+	}
+	fields, ok := fieldsOf[v.Type()]
+	if !ok {
+		fields = field.ParseFields(reflect.New(v.Type()).Interface())
+		fieldsOf[v.Type()] = fields
+	}
+	return v, fields, true
+}
+
+// polymorphic reports whether the documents are decoded by the discriminator registry
+func (f *Finder[T]) polymorphic() bool {
+	return f.discriminator != nil && reflect.TypeOf((*T)(nil)).Elem().Kind() == reflect.Interface
+}
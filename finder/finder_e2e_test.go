@@ -17,6 +17,7 @@
 package finder_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -30,6 +31,7 @@ import (
 	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
 
 	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
 	xfinder "github.com/chenmingyong0423/go-mongox/v2/finder"
 	"github.com/chenmingyong0423/go-mongox/v2/operation"
 
@@ -37,11 +39,13 @@ import (
 
 	"github.com/chenmingyong0423/go-mongox/v2/bsonx"
 
+	"github.com/chenmingyong0423/go-mongox/v2/builder/projection"
 	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
 	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
 )
 
@@ -1502,3 +1506,282 @@ func TestFinder_e2e_FindOneAndUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestFinder_e2e_Where(t *testing.T) {
+	collection := getCollection(t)
+	finder := xfinder.NewFinder[TestUser](collection, callback.InitializeCallbacks(), field.ParseFields(TestUser{}))
+
+	ctx := context.Background()
+	insertManyResult, err := collection.InsertMany(ctx, []any{
+		TestUser{Name: "Mingyong Chen", Age: 18},
+		TestUser{Name: "chenmingyong", Age: 24},
+	})
+	require.NoError(t, err)
+	require.Len(t, insertManyResult.InsertedIDs, 2)
+	defer func() {
+		deleteResult, err := collection.DeleteMany(ctx, query.In("_id", insertManyResult.InsertedIDs...))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleteResult.DeletedCount)
+	}()
+
+	user, err := finder.WhereExample(&TestUser{Name: "chenmingyong"}).FindOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "chenmingyong", user.Name)
+	require.Equal(t, int64(24), user.Age)
+
+	users, err := finder.WhereExample(&TestUser{}, "name").Find(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 0)
+}
+
+func TestFinder_e2e_FindByIDs(t *testing.T) {
+	collection := getCollection(t)
+	finder := xfinder.NewFinder[TestUser](collection, callback.InitializeCallbacks(), field.ParseFields(TestUser{}))
+
+	ctx := context.Background()
+	docs := make([]any, 0, 10)
+	for i := 0; i < 10; i++ {
+		docs = append(docs, TestUser{ID: bson.NewObjectID(), Name: fmt.Sprintf("user-%d", i), Age: int64(i)})
+	}
+	insertManyResult, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteMany(ctx, query.In("_id", insertManyResult.InsertedIDs...))
+		require.NoError(t, err)
+	}()
+
+	unknown := bson.NewObjectID()
+	ids := []bson.ObjectID{docs[7].(TestUser).ID, unknown, docs[2].(TestUser).ID, docs[5].(TestUser).ID, docs[2].(TestUser).ID}
+	found, missing, err := finder.FindByIDs(ctx, ids, &xfinder.FindByIDsOptions{ChunkSize: 2, Concurrency: 2})
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	require.Equal(t, "user-7", found[0].Name)
+	require.Equal(t, "user-2", found[1].Name)
+	require.Equal(t, "user-5", found[2].Name)
+	require.Equal(t, []any{unknown}, missing)
+
+	// the filter of the finder still applies
+	found, missing, err = finder.Filter(query.Gt("name", "user-4")).FindByIDs(ctx, ids)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, []any{unknown, docs[2].(TestUser).ID}, missing)
+
+	// the int ids match the _id stored as int64
+	type numbered struct {
+		ID   int64  `bson:"_id"`
+		Name string `bson:"name"`
+	}
+	_, err = collection.InsertMany(ctx, []any{bson.M{"_id": int64(1), "name": "one"}, bson.M{"_id": int64(2), "name": "two"}})
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteMany(ctx, query.In("_id", int64(1), int64(2)))
+		require.NoError(t, err)
+	}()
+	numberedFound, missing, err := xfinder.NewFinder[numbered](collection, callback.InitializeCallbacks(), field.ParseFields(numbered{})).
+		FindByIDs(ctx, []int{2, 3, 1})
+	require.NoError(t, err)
+	require.Equal(t, []*numbered{{ID: 2, Name: "two"}, {ID: 1, Name: "one"}}, numberedFound)
+	require.Equal(t, []any{3}, missing)
+}
+
+func TestFinder_e2e_Preload(t *testing.T) {
+	type company struct {
+		ID   bson.ObjectID `bson:"_id"`
+		Name string        `bson:"name"`
+	}
+	type author struct {
+		ID        bson.ObjectID `bson:"_id"`
+		Name      string        `bson:"name"`
+		CompanyID bson.ObjectID `bson:"company_id"`
+		Company   *company      `bson:"-" mongox:"ref:test_company,localField:company_id"`
+	}
+	type comment struct {
+		ID     bson.ObjectID `bson:"_id"`
+		PostID bson.ObjectID `bson:"post_id"`
+		Body   string        `bson:"body"`
+	}
+	type post struct {
+		ID        bson.ObjectID   `bson:"_id"`
+		Title     string          `bson:"title"`
+		AuthorID  bson.ObjectID   `bson:"author_id"`
+		Author    *author         `bson:"-" mongox:"ref:test_author,localField:author_id"`
+		EditorIDs []bson.ObjectID `bson:"editor_ids"`
+		Editors   []author        `bson:"-" mongox:"ref:test_author,localField:editor_ids"`
+		Comments  []*comment      `bson:"-" mongox:"ref:test_comment,localField:_id,foreignField:post_id"`
+	}
+
+	ctx := context.Background()
+	db := getCollection(t).Database()
+	c := company{ID: bson.NewObjectID(), Name: "mongox"}
+	a1 := author{ID: bson.NewObjectID(), Name: "a1", CompanyID: c.ID}
+	a2 := author{ID: bson.NewObjectID(), Name: "a2"}
+	p1 := post{ID: bson.NewObjectID(), Title: "p1", AuthorID: a1.ID, EditorIDs: []bson.ObjectID{a2.ID, a1.ID}}
+	p2 := post{ID: bson.NewObjectID(), Title: "p2", AuthorID: a2.ID}
+	inserts := map[string][]any{
+		"test_company": {c},
+		"test_author":  {a1, a2},
+		"test_post":    {p1, p2},
+		"test_comment": {comment{ID: bson.NewObjectID(), PostID: p1.ID, Body: "c1"}, comment{ID: bson.NewObjectID(), PostID: p1.ID, Body: "c2"}},
+	}
+	for name, docs := range inserts {
+		_, err := db.Collection(name).InsertMany(ctx, docs)
+		require.NoError(t, err)
+	}
+	defer func() {
+		for name := range inserts {
+			require.NoError(t, db.Collection(name).Drop(ctx))
+		}
+	}()
+
+	finder := xfinder.NewFinder[post](db.Collection("test_post"), callback.InitializeCallbacks(), field.ParseFields(post{}))
+	posts, err := finder.Preload("Author.Company", "Editors", "Comments").Sort(bson.D{{Key: "title", Value: 1}}).Find(ctx)
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+
+	require.NotNil(t, posts[0].Author)
+	require.Equal(t, "a1", posts[0].Author.Name)
+	require.NotNil(t, posts[0].Author.Company)
+	require.Equal(t, "mongox", posts[0].Author.Company.Name)
+	require.Len(t, posts[0].Editors, 2)
+	require.Equal(t, "a2", posts[0].Editors[0].Name)
+	require.Equal(t, "a1", posts[0].Editors[1].Name)
+	require.Len(t, posts[0].Comments, 2)
+
+	require.Equal(t, "a2", posts[1].Author.Name)
+	require.Nil(t, posts[1].Author.Company)
+	require.Empty(t, posts[1].Editors)
+	require.Empty(t, posts[1].Comments)
+
+	one, err := xfinder.NewFinder[post](db.Collection("test_post"), callback.InitializeCallbacks(), field.ParseFields(post{})).
+		Filter(query.Id(p2.ID)).Preload("Author").FindOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "a2", one.Author.Name)
+}
+
+func TestFinder_e2e_PreloadEncrypted(t *testing.T) {
+	type member struct {
+		ID    int64  `bson:"_id"`
+		Email string `bson:"email" mongox:"encrypt:randomized"`
+	}
+	type team struct {
+		ID        bson.ObjectID `bson:"_id"`
+		MemberIDs []int         `bson:"member_ids"`
+		Members   []*member     `bson:"-" mongox:"ref:test_member,localField:member_ids"`
+	}
+
+	ctx := context.Background()
+	db := getCollection(t).Database()
+	keyRing, err := encryption.NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	cipher := encryption.NewCipher(keyRing)
+	for _, m := range []member{{ID: 1, Email: "a@mongox.dev"}, {ID: 2, Email: "b@mongox.dev"}} {
+		doc, err := cipher.EncryptDocument(ctx, m, field.ParseFields(member{}))
+		require.NoError(t, err)
+		_, err = db.Collection("test_member").InsertOne(ctx, doc)
+		require.NoError(t, err)
+	}
+	tm := team{ID: bson.NewObjectID(), MemberIDs: []int{2, 1}}
+	_, err = db.Collection("test_team").InsertOne(ctx, tm)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Collection("test_member").Drop(ctx))
+		require.NoError(t, db.Collection("test_team").Drop(ctx))
+	}()
+
+	// the int references match the int64 _id and the emails are decrypted
+	found, err := xfinder.NewFinder[team](db.Collection("test_team"), callback.InitializeCallbacks(), field.ParseFields(team{})).
+		Cipher(cipher).Filter(query.Id(tm.ID)).Preload("Members").FindOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*member{{ID: 2, Email: "b@mongox.dev"}, {ID: 1, Email: "a@mongox.dev"}}, found.Members)
+}
+
+func TestFinder_e2e_FindAs(t *testing.T) {
+	type userName struct {
+		Name string `bson:"name"`
+	}
+	collection := getCollection(t)
+	finder := xfinder.NewFinder[TestUser](collection, callback.InitializeCallbacks(), field.ParseFields(TestUser{}))
+
+	ctx := context.Background()
+	insertManyResult, err := collection.InsertMany(ctx, []any{
+		TestUser{Name: "Mingyong Chen", Age: 18},
+		TestUser{Name: "chenmingyong", Age: 24},
+	})
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteMany(ctx, query.In("_id", insertManyResult.InsertedIDs...))
+		require.NoError(t, err)
+	}()
+
+	var hookResult any
+	names, err := xfinder.FindAs[userName](ctx, finder.Sort(bson.M{"name": 1}).RegisterAfterHooks(func(ctx context.Context, opContext *xfinder.OpContext[TestUser], opts ...any) error {
+		hookResult = opContext.Result
+		return nil
+	}))
+	require.NoError(t, err)
+	require.Equal(t, []*userName{{Name: "Mingyong Chen"}, {Name: "chenmingyong"}}, names)
+	require.NotNil(t, hookResult)
+
+	// an explicit projection wins over the one derived from the result type
+	finder = xfinder.NewFinder[TestUser](collection, callback.InitializeCallbacks(), field.ParseFields(TestUser{}))
+	raw, err := xfinder.FindOneAs[bson.M](ctx, finder.Filter(query.Eq("name", "chenmingyong")).Project(bson.M{"name": 1, "_id": 0}))
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"name": "chenmingyong"}, *raw)
+
+	// Project applies to the documents of T too
+	user, err := finder.Project(bson.M{"name": 1}).FindOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "chenmingyong", user.Name)
+	require.Zero(t, user.Age)
+}
+
+func TestFinder_e2e_Projection(t *testing.T) {
+	collection := getCollection(t)
+	finder := xfinder.NewFinder[TestUser](collection, callback.InitializeCallbacks(), field.ParseFields(TestUser{}))
+
+	ctx := context.Background()
+	insertOneResult, err := collection.InsertOne(ctx, TestUser{Name: "chenmingyong", Age: 24})
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteOne(ctx, query.Id(insertOneResult.InsertedID))
+		require.NoError(t, err)
+	}()
+
+	user, err := finder.Filter(query.Id(insertOneResult.InsertedID)).Projection(projection.NewBuilder().Include("name").Exclude("_id")).FindOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &TestUser{Name: "chenmingyong"}, user)
+
+	users, err := finder.Projection(projection.NewBuilder().Exclude("name")).Find(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Empty(t, users[0].Name)
+	require.Equal(t, insertOneResult.InsertedID, users[0].ID)
+
+	_, err = finder.Projection(projection.NewBuilder().Include("name").Exclude("age")).Find(ctx)
+	require.ErrorIs(t, err, projection.ErrMixedProjection)
+}
+
+func TestFinder_e2e_DriverOptions(t *testing.T) {
+	collection := getCollection(t)
+	finder := xfinder.NewFinder[TestUser](collection, callback.InitializeCallbacks(), field.ParseFields(TestUser{}))
+
+	ctx := context.Background()
+	insertManyResult, err := collection.InsertMany(ctx, []TestUser{{Name: "chenmingyong", Age: 24}, {Name: "burt", Age: 25}})
+	require.NoError(t, err)
+	defer func() {
+		_, err := collection.DeleteMany(ctx, query.In("_id", insertManyResult.InsertedIDs...))
+		require.NoError(t, err)
+	}()
+
+	user, err := finder.Filter(query.In("_id", insertManyResult.InsertedIDs...)).Sort(bson.D{{Key: "name", Value: 1}}).Skip(1).
+		Hint("_id_").Comment("e2e").MaxTime(time.Minute).ReadPreference(readpref.Primary()).ReadConcern(readconcern.Majority()).FindOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "chenmingyong", user.Name)
+
+	users, err := finder.BatchSize(1).AllowDiskUse(true).Find(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+
+	_, err = finder.Hint("unknown_index").Find(ctx)
+	require.Error(t, err)
+}
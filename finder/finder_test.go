@@ -20,12 +20,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/chenmingyong0423/go-mongox/v2/builder/projection"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/sort"
+	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/collation"
 	"github.com/chenmingyong0423/go-mongox/v2/finder"
 	mocks "github.com/chenmingyong0423/go-mongox/v2/mock"
 	"go.mongodb.org/mongo-driver/v2/bson"
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.uber.org/mock/gomock"
 )
 
@@ -270,3 +276,186 @@ func TestFindOneAndUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestFindAs_UnsupportedFinder(t *testing.T) {
+	type user struct {
+		Name string `bson:"name"`
+	}
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	f := mocks.NewMockIFinder[user](ctl)
+
+	docs, err := finder.FindAs[user](context.Background(), finder.IFinder[user](f))
+	assert.Nil(t, docs)
+	assert.ErrorIs(t, err, finder.ErrUnsupportedFinder)
+
+	doc, err := finder.FindOneAs[user](context.Background(), finder.IFinder[user](f))
+	assert.Nil(t, doc)
+	assert.ErrorIs(t, err, finder.ErrUnsupportedFinder)
+}
+
+func TestFinder_Projection(t *testing.T) {
+	mixed := projection.NewBuilder().Include("name").Exclude("age")
+	f := finder.NewFinder[any](&mongo.Collection{}, nil, nil).Projection(mixed)
+
+	_, err := f.FindOne(context.Background())
+	assert.ErrorIs(t, err, projection.ErrMixedProjection)
+	_, err = f.Find(context.Background())
+	assert.ErrorIs(t, err, projection.ErrMixedProjection)
+	_, err = f.FindOneAndUpdate(context.Background())
+	assert.ErrorIs(t, err, projection.ErrMixedProjection)
+	_, err = finder.FindAs[any](context.Background(), finder.IFinder[any](f))
+	assert.ErrorIs(t, err, projection.ErrMixedProjection)
+	_, _, err = f.FindByIDs(context.Background(), []int{1})
+	assert.ErrorIs(t, err, projection.ErrMixedProjection)
+}
+
+func TestFinder_SortAndCollation(t *testing.T) {
+	errStop := errors.New("stop")
+	var findOptions options.FindOptions
+	sortBuilder := sort.Asc("name").Desc("age")
+	f := finder.NewFinder[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).
+		Sort(sortBuilder).Collation(collation.CaseInsensitive("en"))
+	// the finder keeps the sort it was given
+	sortBuilder.Desc("name")
+	f = f.RegisterBeforeHooks(func(ctx context.Context, opContext *finder.OpContext[any], opts ...any) error {
+		for _, lister := range opContext.MongoOptions.([]options.Lister[options.FindOptions]) {
+			for _, set := range lister.List() {
+				assert.NoError(t, set(&findOptions))
+			}
+		}
+		return errStop
+	})
+
+	_, err := f.Find(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, bson.D{{Key: "name", Value: 1}, {Key: "age", Value: -1}}, findOptions.Sort)
+	assert.Equal(t, &options.Collation{Locale: "en", Strength: 2}, findOptions.Collation)
+}
+
+func TestFinder_DriverOptions(t *testing.T) {
+	errStop := errors.New("stop")
+	hint := sort.Asc("name")
+	f := finder.NewFinder[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).
+		Hint(hint).Comment("report").BatchSize(10).AllowDiskUse(true).Let(bson.D{{Key: "x", Value: 1}}).Skip(5).MaxTime(time.Minute)
+	hint.Desc("name")
+
+	var findOptions options.FindOptions
+	f = f.RegisterBeforeHooks(func(ctx context.Context, opContext *finder.OpContext[any], opts ...any) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		switch mongoOptions := opContext.MongoOptions.(type) {
+		case []options.Lister[options.FindOptions]:
+			for _, lister := range mongoOptions {
+				for _, set := range lister.List() {
+					assert.NoError(t, set(&findOptions))
+				}
+			}
+		case []options.Lister[options.FindOneOptions]:
+			var findOneOptions options.FindOneOptions
+			for _, lister := range mongoOptions {
+				for _, set := range lister.List() {
+					assert.NoError(t, set(&findOneOptions))
+				}
+			}
+			assert.Equal(t, int64(5), *findOneOptions.Skip)
+			assert.Equal(t, bson.D{{Key: "name", Value: 1}}, findOneOptions.Hint)
+			assert.Equal(t, "report", *findOneOptions.Comment.(*any))
+		}
+		return errStop
+	})
+
+	_, err := f.Find(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, bson.D{{Key: "name", Value: 1}}, findOptions.Hint)
+	assert.Equal(t, "report", *findOptions.Comment.(*any))
+	assert.Equal(t, int32(10), *findOptions.BatchSize)
+	assert.True(t, *findOptions.AllowDiskUse)
+	assert.Equal(t, bson.D{{Key: "x", Value: 1}}, findOptions.Let)
+	assert.Equal(t, int64(5), *findOptions.Skip)
+
+	_, err = f.FindOne(context.Background())
+	assert.ErrorIs(t, err, errStop)
+}
+
+func TestFinder_Where(t *testing.T) {
+	type TestUser struct {
+		Name   string `bson:"name"`
+		Status int    `bson:"status"`
+	}
+	f := finder.NewFinder[TestUser](&mongo.Collection{}, callback.InitializeCallbacks(), nil)
+	filterOf := func(f finder.IFinder[TestUser]) any { return f.(*finder.Finder[TestUser]).FilterObj }
+
+	where := f.Where(&TestUser{Name: "chenmingyong"}).Where(query.Gt("age", 18), nil)
+	assert.Equal(t, bson.D{{Key: "name", Value: "chenmingyong"}, {Key: "age", Value: bson.D{{Key: "$gt", Value: 18}}}}, filterOf(where))
+
+	assert.Equal(t, query.Or(
+		bson.D{{Key: "name", Value: "chenmingyong"}, {Key: "age", Value: bson.D{{Key: "$gt", Value: 18}}}},
+		bson.D{{Key: "status", Value: 1}},
+	), filterOf(where.OrWhere(bson.M{"status": 1})))
+
+	assert.Equal(t, bson.D{
+		{Key: "status", Value: bson.D{{Key: "$eq", Value: 1}}},
+		{Key: "$nor", Value: []any{bson.D{{Key: "name", Value: bson.D{{Key: "$eq", Value: "burt"}}}}}},
+	}, filterOf(f.Filter(query.Eq("status", 1)).Not(query.NewBuilder().Eq("name", "burt")).Not()))
+	assert.Equal(t, bson.D{}, f.FilterObj)
+}
+
+func TestFinder_WhereExample(t *testing.T) {
+	type TestUser struct {
+		Name   string `bson:"name"`
+		Status int    `bson:"status"`
+	}
+	f := finder.NewFinder[TestUser](&mongo.Collection{}, callback.InitializeCallbacks(), nil).Filter(query.Eq("status", 1))
+
+	// the example replaces the filter
+	where := f.WhereExample(&TestUser{Name: "chenmingyong"})
+	assert.Equal(t, bson.D{{Key: "name", Value: "chenmingyong"}}, where.(*finder.Finder[TestUser]).FilterObj)
+	where = f.WhereExample(&TestUser{}, "status")
+	assert.Equal(t, bson.D{{Key: "status", Value: 0}}, where.(*finder.Finder[TestUser]).FilterObj)
+	assert.Equal(t, query.Eq("status", 1), f.(*finder.Finder[TestUser]).FilterObj)
+}
+
+func TestFinder_Scopes(t *testing.T) {
+	active := func(b *query.Builder) *query.Builder { return b.Eq("status", "active") }
+	notDeleted := func(b *query.Builder) *query.Builder { return b.Exists("deleted_at", false) }
+	errStop := errors.New("stop")
+
+	var filters []any
+	f := finder.NewFinder[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).DefaultScopes(notDeleted).
+		Scopes(active).RegisterBeforeHooks(func(ctx context.Context, opContext *finder.OpContext[any], opts ...any) error {
+		filters = append(filters, opContext.Filter)
+		return errStop
+	})
+
+	_, err := f.Find(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	_, err = f.Unscoped().FindOne(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []any{
+		bson.D{{Key: "status", Value: bson.D{{Key: "$eq", Value: "active"}}}, {Key: "deleted_at", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "status", Value: bson.D{{Key: "$eq", Value: "active"}}}},
+	}, filters)
+}
+
+func TestFinder_Clone(t *testing.T) {
+	errStop := errors.New("stop")
+	base := finder.NewFinder[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).Filter(query.Eq("status", 1)).Limit(10)
+
+	var calls int
+	derived := base.Where(query.Gt("age", 18)).Skip(5).RegisterBeforeHooks(func(ctx context.Context, opContext *finder.OpContext[any], opts ...any) error {
+		calls++
+		return errStop
+	})
+	_, err := derived.Find(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+
+	b := base.(*finder.Finder[any])
+	assert.Equal(t, query.Eq("status", 1), b.FilterObj)
+	assert.Empty(t, b.BeforeHooks)
+
+	clone := b.Clone()
+	clone.BeforeHooks = append(clone.BeforeHooks, nil)
+	assert.Empty(t, b.BeforeHooks)
+}
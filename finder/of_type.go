@@ -0,0 +1,35 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package finder
+
+import (
+	"errors"
+	"reflect"
+)
+
+var ErrNoDiscriminator = errors.New("mongox: OfType requires a finder with a discriminator registry")
+
+// OfType returns a copy of f restricted to the documents of type C by adding the discriminator condition of C to its filter,
+// e.g. OfType[Click](events.Finder()).Find(ctx). The condition is kept when the filter is set afterwards.
+// The find methods return an error if f has no discriminator registry or C isn't registered in it.
+func OfType[C any, T any](f *Finder[T]) *Finder[T] {
+	c := f.Clone()
+	if f.discriminator == nil {
+		c.ofTypeErr = ErrNoDiscriminator
+		return c
+	}
+	c.ofType, c.ofTypeErr = f.discriminator.Filter(reflect.TypeOf((*C)(nil)).Elem())
+	return c
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package finder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/discriminator"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type ofTypeEvent interface{}
+
+type ofTypeSecret struct {
+	Kind  string `bson:"kind"`
+	Email string `bson:"email" mongox:"encrypt:randomized"`
+}
+
+func TestOfType(t *testing.T) {
+	type click struct{}
+	type view struct{}
+	registry := discriminator.NewRegistry("kind").Register("click", click{})
+
+	testCases := []struct {
+		name   string
+		filter any
+		want   any
+	}{
+		{name: "empty filter", filter: bson.D{}, want: bson.D{{Key: "kind", Value: "click"}}},
+		{name: "nil filter", filter: nil, want: bson.D{{Key: "kind", Value: "click"}}},
+		{
+			name:   "filter",
+			filter: query.Eq("x", 1),
+			want:   query.Merge(query.Eq("x", 1), bson.D{{Key: "kind", Value: "click"}}),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// the condition is kept whether the filter is set before or after OfType
+			f := NewFinder[any](&mongo.Collection{}, nil, nil).Discriminator(registry)
+			assert.Equal(t, tc.want, OfType[click](f.Filter(tc.filter).(*Finder[any])).scopedFilter())
+			assert.Equal(t, tc.want, OfType[click](f).Filter(tc.filter).(*Finder[any]).scopedFilter())
+			assert.Equal(t, bson.D{}, f.FilterObj)
+		})
+	}
+
+	// the errors are returned by the find methods
+	errStop := errors.New("stop")
+	testErrs := []struct {
+		name    string
+		finder  *Finder[any]
+		wantErr error
+	}{
+		{name: "no registry", finder: OfType[click](NewFinder[any](&mongo.Collection{}, nil, nil)), wantErr: ErrNoDiscriminator},
+		{name: "unregistered type", finder: OfType[view](NewFinder[any](&mongo.Collection{}, nil, nil).Discriminator(registry)), wantErr: discriminator.ErrUnregisteredType},
+	}
+	for _, tc := range testErrs {
+		t.Run(tc.name, func(t *testing.T) {
+			f := tc.finder.RegisterBeforeHooks(func(ctx context.Context, opContext *OpContext[any], opts ...any) error {
+				return errStop
+			})
+			_, err := f.FindOne(context.Background())
+			assert.ErrorIs(t, err, tc.wantErr)
+			_, err = f.Find(context.Background())
+			assert.ErrorIs(t, err, tc.wantErr)
+			_, err = f.Count(context.Background())
+			assert.ErrorIs(t, err, tc.wantErr)
+			_, err = FindAs[click](context.Background(), f)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.ErrorContains(t, f.Distinct(context.Background(), "x").Err(), tc.wantErr.Error())
+		})
+	}
+}
+
+func TestFinder_decodeRaw(t *testing.T) {
+	keyRing, err := encryption.NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	cipher := encryption.NewCipher(keyRing)
+	encrypted, err := cipher.EncryptDocument(context.Background(), ofTypeSecret{Kind: "secret", Email: "a@mongox.dev"}, field.ParseFields(ofTypeSecret{}))
+	require.NoError(t, err)
+	raw, err := bson.Marshal(encrypted)
+	require.NoError(t, err)
+
+	// the polymorphic documents are decrypted with the fields of their registered type
+	registry := discriminator.NewRegistry("kind").Register("secret", ofTypeSecret{})
+	f := NewFinder[ofTypeEvent](&mongo.Collection{}, nil, nil).Discriminator(registry).Cipher(cipher)
+	var doc ofTypeEvent
+	require.NoError(t, f.decodeRaw(context.Background(), raw, &doc))
+	assert.Equal(t, &ofTypeSecret{Kind: "secret", Email: "a@mongox.dev"}, doc)
+}
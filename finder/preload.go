@@ -0,0 +1,299 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package finder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var ErrInvalidPreload = errors.New("mongox: invalid preload")
+
+// Preload fills the fields tagged with `mongox:"ref:<collection>,localField:<field>"` once the documents are found
+// by FindOne or Find. The fields are named by their Go names, nested references are separated by dots, e.g. "Author.Company".
+//
+// The referenced documents of all the found documents are fetched by a single $in query per reference. The field is
+//   - a pointer to or a struct for one-to-one references, set to the first matching document
+//   - a slice of pointers or structs for one-to-many references, set to all the matching documents
+//
+// The referenced documents are queried like Find does: the callbacks run, e.g. the model hooks of the referenced type,
+// and their encrypted fields are decrypted by the cipher of the finder. The default scopes of the finder are those of
+// its own collection, they are not applied to the referenced collections.
+//
+// For an interface T, a path applies to the concrete types of the documents which have the reference.
+//
+// The tagged fields are usually also tagged with `bson:"-"` so that they are not persisted.
+func (f *Finder[T]) Preload(paths ...string) IFinder[T] {
+	c := f.Clone()
+	c.preloads = append(c.preloads, paths...)
+	return c
+}
+
+func (f *Finder[T]) preload(ctx context.Context, docs ...*T) error {
+	if len(f.preloads) == 0 {
+		return nil
+	}
+	// the documents of an interface T are grouped by concrete type, the types having their own fields
+	var groups []preloadGroup
+	indexes := make(map[reflect.Type]int)
+	fieldsOf := make(map[reflect.Type][]*field.Filed)
+	for _, doc := range docs {
+		v, fields, ok := f.docStruct(doc, fieldsOf)
+		if !ok {
+			continue
+		}
+		idx, ok := indexes[v.Type()]
+		if !ok {
+			idx = len(groups)
+			indexes[v.Type()] = idx
+			groups = append(groups, preloadGroup{fields: fields})
+		}
+		groups[idx].docs = append(groups[idx].docs, v)
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	db := f.Collection.Database()
+	for _, path := range f.preloads {
+		segments := strings.Split(path, ".")
+		preloaded := false
+		for _, group := range groups {
+			// a path only applies to the types which have the reference
+			if _, fd := lookupField(group.fields, isRef(segments[0])); fd == nil {
+				continue
+			}
+			if err := f.preloadPath(ctx, db, group.docs, group.fields, segments); err != nil {
+				return err
+			}
+			preloaded = true
+		}
+		if !preloaded {
+			return fmt.Errorf("%w: %s is not a field tagged with ref", ErrInvalidPreload, segments[0])
+		}
+	}
+	return nil
+}
+
+// preloadGroup is a set of documents of the same type
+type preloadGroup struct {
+	docs   []reflect.Value
+	fields []*field.Filed
+}
+
+func (f *Finder[T]) preloadPath(ctx context.Context, db *mongo.Database, docs []reflect.Value, fields []*field.Filed, path []string) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	refIndex, refField := lookupField(fields, isRef(path[0]))
+	if refField == nil {
+		return fmt.Errorf("%w: %s is not a field tagged with ref", ErrInvalidPreload, path[0])
+	}
+	ref := refField.Ref
+	localIndex, localField := lookupField(fields, func(fd *field.Filed) bool { return fd.MongoField == ref.LocalField })
+	if localField == nil {
+		return fmt.Errorf("%w: local field %s of %s not found", ErrInvalidPreload, ref.LocalField, path[0])
+	}
+	elemType, many, ok := refElemType(refField.FieldType)
+	if !ok {
+		return fmt.Errorf("%w: %s must be a struct, a pointer to a struct or a slice of them", ErrInvalidPreload, path[0])
+	}
+
+	values := make([]any, 0, len(docs))
+	seen := make(map[string]struct{}, len(docs))
+	for _, doc := range docs {
+		for _, value := range localValues(doc.FieldByIndex(localIndex)) {
+			key, err := utils.BsonKey(value)
+			if err != nil {
+				return err
+			}
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				values = append(values, value)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	refFields := field.ParseFields(reflect.New(elemType).Interface())
+	refs, err := f.findRefs(ctx, db.Collection(ref.Collection), refFields, ref.ForeignField, values, elemType)
+	if err != nil {
+		return err
+	}
+
+	if len(path) > 1 {
+		nested := make([]reflect.Value, refs.Len())
+		for i := range nested {
+			nested[i] = refs.Index(i).Elem()
+		}
+		if err = f.preloadPath(ctx, db, nested, refFields, path[1:]); err != nil {
+			return err
+		}
+	}
+
+	byKey := make(map[string][]reflect.Value, refs.Len())
+	for i := 0; i < refs.Len(); i++ {
+		keys, err := utils.LookupKeys(refs.Index(i).Interface(), ref.ForeignField)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			byKey[key] = append(byKey[key], refs.Index(i))
+		}
+	}
+
+	for _, doc := range docs {
+		var matched []reflect.Value
+		for _, value := range localValues(doc.FieldByIndex(localIndex)) {
+			key, err := utils.BsonKey(value)
+			if err != nil {
+				return err
+			}
+			matched = append(matched, byKey[key]...)
+		}
+		setRefField(doc.FieldByIndex(refIndex), matched, many)
+	}
+	return nil
+}
+
+// findRefs returns the documents of coll, pointers to elemType, whose foreignField is in values
+func (f *Finder[T]) findRefs(ctx context.Context, coll *mongo.Collection, fields []*field.Filed, foreignField string, values []any, elemType reflect.Type) (reflect.Value, error) {
+	filter := query.In(foreignField, values...)
+	if f.cipher != nil && encryption.EncryptedFields(fields)[foreignField] == field.EncryptDeterministic {
+		var err error
+		if filter, err = f.cipher.In(ctx, foreignField, values...); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	globalOpContext := operation.NewOpContext(coll, operation.WithFilter(filter), operation.WithStartTime(time.Now()), operation.WithFields(fields))
+	if err := f.DBCallbacks.Execute(ctx, globalOpContext, operation.OpTypeBeforeFind); err != nil {
+		return reflect.Value{}, err
+	}
+	cursor, err := f.driverOpts.Collection(coll).Find(ctx, globalOpContext.Filter)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	defer cursor.Close(ctx)
+	results := reflect.New(reflect.SliceOf(reflect.PointerTo(elemType)))
+	if f.cipher != nil {
+		err = f.cipher.DecodeCursor(ctx, cursor, fields, results.Interface())
+	} else {
+		err = cursor.All(ctx, results.Interface())
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	globalOpContext.Result = cursor
+	globalOpContext.Doc = results.Elem().Interface()
+	if err = f.DBCallbacks.Execute(ctx, globalOpContext, operation.OpTypeAfterFind); err != nil {
+		return reflect.Value{}, err
+	}
+	return results.Elem(), nil
+}
+
+// isRef matches the field named name tagged with ref
+func isRef(name string) func(fd *field.Filed) bool {
+	return func(fd *field.Filed) bool { return fd.Name == name && fd.Ref != nil }
+}
+
+// lookupField returns the index path of the first field, including the inlined ones, accepted by match
+func lookupField(fields []*field.Filed, match func(fd *field.Filed) bool) ([]int, *field.Filed) {
+	for idx, fd := range fields {
+		if fd.InlinedFields != nil {
+			if index, found := lookupField(fd.InlinedFields, match); found != nil {
+				return append([]int{idx}, index...), found
+			}
+			continue
+		}
+		if match(fd) {
+			return []int{idx}, fd
+		}
+	}
+	return nil, nil
+}
+
+// refElemType returns the struct type of the referenced documents and whether the field holds many of them
+func refElemType(t reflect.Type) (reflect.Type, bool, bool) {
+	many := false
+	if t.Kind() == reflect.Slice {
+		many = true
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, many, t.Kind() == reflect.Struct
+}
+
+// localValues returns the non-zero references held by v, which is either a single value or a slice of them
+func localValues(v reflect.Value) []any {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		values := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, localValues(v.Index(i))...)
+		}
+		return values
+	}
+	if v.IsZero() {
+		return nil
+	}
+	return []any{v.Interface()}
+}
+
+// setRefField assigns the matched documents, pointers to structs, to dest
+func setRefField(dest reflect.Value, matched []reflect.Value, many bool) {
+	if !many {
+		if len(matched) == 0 {
+			dest.Set(reflect.Zero(dest.Type()))
+			return
+		}
+		if dest.Kind() == reflect.Ptr {
+			dest.Set(matched[0])
+		} else {
+			dest.Set(matched[0].Elem())
+		}
+		return
+	}
+	slice := reflect.MakeSlice(dest.Type(), 0, len(matched))
+	for _, m := range matched {
+		if dest.Type().Elem().Kind() == reflect.Ptr {
+			slice = reflect.Append(slice, m)
+		} else {
+			slice = reflect.Append(slice, m.Elem())
+		}
+	}
+	dest.Set(slice)
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package finder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type preloadAuthor struct {
+	ID   bson.ObjectID `bson:"_id"`
+	Name string        `bson:"name"`
+}
+
+type PreloadBase struct {
+	AuthorID bson.ObjectID  `bson:"author_id"`
+	Author   *preloadAuthor `bson:"-" mongox:"ref:users,localField:author_id"`
+}
+
+type preloadPost struct {
+	PreloadBase `bson:",inline"`
+	ID          bson.ObjectID   `bson:"_id"`
+	TagIDs      []string        `bson:"tag_ids"`
+	Tags        []preloadAuthor `bson:"-" mongox:"ref:tags,localField:tag_ids"`
+	Title       string          `bson:"title"`
+	Invalid     string          `bson:"-" mongox:"ref:users,localField:unknown"`
+}
+
+func TestFinder_Preload(t *testing.T) {
+	testCases := []struct {
+		name    string
+		paths   []string
+		wantErr string
+	}{
+		{name: "not a field", paths: []string{"Unknown"}, wantErr: "mongox: invalid preload: Unknown is not a field tagged with ref"},
+		{name: "not a ref", paths: []string{"Title"}, wantErr: "mongox: invalid preload: Title is not a field tagged with ref"},
+		{name: "unknown local field", paths: []string{"Invalid"}, wantErr: "mongox: invalid preload: local field unknown of Invalid not found"},
+		{name: "no references", paths: []string{"Author.Company"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewFinder[preloadPost](&mongo.Collection{}, nil, field.ParseFields(preloadPost{})).Preload(tc.paths...).(*Finder[preloadPost])
+			if tc.wantErr == "" {
+				// the documents without references don't query the referenced collection
+				require.NoError(t, f.preload(context.Background(), &preloadPost{}))
+				return
+			}
+			err := f.preload(context.Background(), &preloadPost{})
+			assert.ErrorIs(t, err, ErrInvalidPreload)
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+type preloadComment struct {
+	ID bson.ObjectID `bson:"_id"`
+}
+
+func TestFinder_Preload_Polymorphic(t *testing.T) {
+	// the paths apply to the concrete types of the documents of an interface T which have the reference
+	f := NewFinder[any](&mongo.Collection{}, nil, nil).Preload("Author").(*Finder[any])
+	var post, comment any = &preloadPost{}, &preloadComment{}
+	require.NoError(t, f.preload(context.Background(), &post, &comment))
+
+	err := f.preload(context.Background(), &comment)
+	assert.EqualError(t, err, "mongox: invalid preload: Author is not a field tagged with ref")
+}
+
+type preloadSecret struct {
+	ID    int64  `bson:"_id"`
+	Email string `bson:"email" mongox:"encrypt:deterministic"`
+}
+
+func TestFinder_findRefs(t *testing.T) {
+	client, err := mongo.Connect(options.Client())
+	require.NoError(t, err)
+	defer func() { _ = client.Disconnect(context.Background()) }()
+	coll := client.Database("db-test").Collection("users")
+
+	errStop := errors.New("stop")
+	var opCtx *operation.OpContext
+	callbacks := callback.InitializeCallbacks()
+	callbacks.Register(operation.OpTypeBeforeFind, "record", func(ctx context.Context, c *operation.OpContext, opts ...any) error {
+		opCtx = c
+		return errStop
+	})
+	f := NewFinder[preloadPost](coll, callbacks, field.ParseFields(preloadPost{}))
+
+	// the callbacks run with the referenced collection, filter and fields
+	fields := field.ParseFields(preloadSecret{})
+	_, err = f.findRefs(context.Background(), coll, fields, "_id", []any{1, 2}, reflect.TypeOf(preloadSecret{}))
+	assert.ErrorIs(t, err, errStop)
+	require.NotNil(t, opCtx)
+	assert.Same(t, coll, opCtx.Col)
+	assert.Equal(t, query.In[any]("_id", 1, 2), opCtx.Filter)
+	assert.Equal(t, fields, opCtx.Fields)
+
+	// the deterministically encrypted foreign fields are queried by their ciphertexts
+	keyRing, err := encryption.NewKeyRing("k1", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	cipher := encryption.NewCipher(keyRing)
+	want, err := cipher.In(context.Background(), "email", "a@b.c")
+	require.NoError(t, err)
+	_, err = f.Cipher(cipher).findRefs(context.Background(), coll, fields, "email", []any{"a@b.c"}, reflect.TypeOf(preloadSecret{}))
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, want, opCtx.Filter)
+}
+
+func Test_lookupField(t *testing.T) {
+	fields := field.ParseFields(preloadPost{})
+
+	index, fd := lookupField(fields, func(fd *field.Filed) bool { return fd.Name == "Author" })
+	require.NotNil(t, fd)
+	assert.Equal(t, []int{0, 1}, index)
+	assert.Equal(t, &field.Ref{Collection: "users", LocalField: "author_id", ForeignField: "_id"}, fd.Ref)
+
+	index, fd = lookupField(fields, func(fd *field.Filed) bool { return fd.MongoField == "tag_ids" })
+	require.NotNil(t, fd)
+	assert.Equal(t, []int{2}, index)
+
+	_, fd = lookupField(fields, func(fd *field.Filed) bool { return fd.Name == "Unknown" })
+	assert.Nil(t, fd)
+}
+
+func Test_refElemType(t *testing.T) {
+	authorType := reflect.TypeOf(preloadAuthor{})
+	testCases := []struct {
+		name     string
+		t        reflect.Type
+		wantMany bool
+		wantOK   bool
+	}{
+		{name: "struct", t: authorType, wantOK: true},
+		{name: "pointer", t: reflect.PointerTo(authorType), wantOK: true},
+		{name: "slice", t: reflect.SliceOf(authorType), wantMany: true, wantOK: true},
+		{name: "slice of pointers", t: reflect.SliceOf(reflect.PointerTo(authorType)), wantMany: true, wantOK: true},
+		{name: "string", t: reflect.TypeOf(""), wantOK: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			elemType, many, ok := refElemType(tc.t)
+			assert.Equal(t, tc.wantOK, ok)
+			if ok {
+				assert.Equal(t, authorType, elemType)
+				assert.Equal(t, tc.wantMany, many)
+			}
+		})
+	}
+}
+
+func Test_localValues(t *testing.T) {
+	id := bson.NewObjectID()
+	testCases := []struct {
+		name  string
+		value any
+		want  []any
+	}{
+		{name: "object id", value: id, want: []any{id}},
+		{name: "zero object id", value: bson.ObjectID{}, want: nil},
+		{name: "nil pointer", value: (*string)(nil), want: nil},
+		{name: "pointer", value: utils.ToPtr("a"), want: []any{"a"}},
+		{name: "slice", value: []string{"a", "", "b"}, want: []any{"a", "b"}},
+		{name: "bytes", value: []byte("a"), want: []any{[]byte("a")}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, localValues(reflect.ValueOf(tc.value)))
+		})
+	}
+}
+
+func Test_setRefField(t *testing.T) {
+	a1, a2 := &preloadAuthor{Name: "a1"}, &preloadAuthor{Name: "a2"}
+	matched := []reflect.Value{reflect.ValueOf(a1), reflect.ValueOf(a2)}
+
+	var one *preloadAuthor
+	setRefField(reflect.ValueOf(&one).Elem(), matched, false)
+	assert.Same(t, a1, one)
+	setRefField(reflect.ValueOf(&one).Elem(), nil, false)
+	assert.Nil(t, one)
+
+	var value preloadAuthor
+	setRefField(reflect.ValueOf(&value).Elem(), matched, false)
+	assert.Equal(t, *a1, value)
+
+	var pointers []*preloadAuthor
+	setRefField(reflect.ValueOf(&pointers).Elem(), matched, true)
+	assert.Equal(t, []*preloadAuthor{a1, a2}, pointers)
+
+	var values []preloadAuthor
+	setRefField(reflect.ValueOf(&values).Elem(), nil, true)
+	assert.Equal(t, []preloadAuthor{}, values)
+}
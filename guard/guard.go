@@ -0,0 +1,185 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package guard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	// ErrEmptyFilter is returned by the update and delete methods of the updaters and deleters called with an empty filter,
+	// unless AllowFullCollection is called
+	ErrEmptyFilter = errors.New("mongox: empty filter, call AllowFullCollection to write the whole collection")
+	// ErrUnindexedFilter is returned by the plugin of IndexedFilter for the filters without an indexed field
+	ErrUnindexedFilter = errors.New("mongox: the filter has no indexed field")
+)
+
+// IsEmptyFilter reports whether filter matches all the documents: nil, an empty document or builder,
+// or an example without non-zero fields, see query.Merge
+func IsEmptyFilter(filter any) bool {
+	return len(query.Merge(filter)) == 0
+}
+
+// IndexLookup returns the indexed fields of coll, i.e. the first fields of its indexes.
+// A field ending with $**, e.g. "attrs.$**" or "$**", is a wildcard index on the fields with this prefix.
+type IndexLookup func(ctx context.Context, coll *mongo.Collection) ([]string, error)
+
+// ServerIndexes is the IndexLookup listing the indexes of the collection on the server
+func ServerIndexes(ctx context.Context, coll *mongo.Collection) ([]string, error) {
+	specs, err := coll.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		elements, err := spec.KeysDocument.Elements()
+		if err != nil {
+			return nil, err
+		}
+		if len(elements) > 0 {
+			fields = append(fields, elements[0].Key())
+		}
+	}
+	return fields, nil
+}
+
+// IndexedFilter returns a plugin that returns ErrUnindexedFilter for the filters without an indexed field,
+// which would scan the whole collection. A filter is indexed if one of its fields or one of its $and clauses is indexed,
+// or if all its $or clauses are indexed, $text queries are always indexed. The empty filters are left to ErrEmptyFilter.
+// The indexed fields are looked up once per collection by lookup, ServerIndexes if it's nil.
+// The plugin is registered for the operations to check, e.g.
+//
+//	db.RegisterPlugin("guard:indexed_filter", guard.IndexedFilter(nil), operation.OpTypeBeforeDelete)
+func IndexedFilter(lookup IndexLookup) callback.CbFn {
+	if lookup == nil {
+		lookup = ServerIndexes
+	}
+	var (
+		mu      sync.Mutex
+		indexes = make(map[string][]string)
+	)
+	indexedFields := func(ctx context.Context, coll *mongo.Collection) ([]string, error) {
+		ns := namespace(coll)
+		mu.Lock()
+		defer mu.Unlock()
+		if fields, ok := indexes[ns]; ok {
+			return fields, nil
+		}
+		fields, err := lookup(ctx, coll)
+		if err != nil {
+			return nil, err
+		}
+		indexes[ns] = fields
+		return fields, nil
+	}
+
+	return func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+		filter := query.Merge(opCtx.Filter)
+		if len(filter) == 0 {
+			return nil
+		}
+		fields, err := indexedFields(ctx, opCtx.Col)
+		if err != nil {
+			return err
+		}
+		if !isIndexed(filter, fields) {
+			return fmt.Errorf("%w: %s on %s", ErrUnindexedFilter, filterKeys(filter), namespace(opCtx.Col))
+		}
+		return nil
+	}
+}
+
+// isIndexed reports whether filter has an indexed field
+func isIndexed(filter bson.D, fields []string) bool {
+	for _, e := range filter {
+		switch e.Key {
+		case query.TextOp:
+			return true
+		case query.AndOp:
+			for _, clause := range clauses(e.Value) {
+				if isIndexed(query.Merge(clause), fields) {
+					return true
+				}
+			}
+		case query.OrOp:
+			or := clauses(e.Value)
+			indexed := len(or) > 0
+			for _, clause := range or {
+				indexed = indexed && isIndexed(query.Merge(clause), fields)
+			}
+			if indexed {
+				return true
+			}
+		default:
+			if !strings.HasPrefix(e.Key, "$") && isIndexedField(e.Key, fields) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isIndexedField(key string, fields []string) bool {
+	for _, f := range fields {
+		if prefix := strings.TrimSuffix(f, "$**"); prefix != f {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if f == key {
+			return true
+		}
+	}
+	return false
+}
+
+// clauses returns the items of the value of a logical operator
+func clauses(value any) []any {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil
+	}
+	items := make([]any, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items
+}
+
+func filterKeys(filter bson.D) []string {
+	keys := make([]string, len(filter))
+	for i, e := range filter {
+		keys[i] = e.Key
+	}
+	return keys
+}
+
+func namespace(coll *mongo.Collection) string {
+	if db := coll.Database(); db != nil {
+		return db.Name() + "." + coll.Name()
+	}
+	return coll.Name()
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package guard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+func newCollection(t *testing.T) *mongo.Collection {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
+		Username:   "test",
+		Password:   "test",
+		AuthSource: "db-test",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, client.Ping(context.Background(), readpref.Primary()))
+
+	return client.Database("db-test").Collection("test_guard")
+}
+
+func TestIndexedFilter_e2e(t *testing.T) {
+	ctx := context.Background()
+	collection := newCollection(t)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "email", Value: 1}, {Key: "name", Value: 1}}})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, collection.Drop(ctx))
+	}()
+
+	fields, err := ServerIndexes(ctx, collection)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"_id", "email"}, fields)
+
+	plugin := IndexedFilter(nil)
+	require.NoError(t, plugin(ctx, operation.NewOpContext(collection, operation.WithFilter(query.Eq("email", "burt@example.com")))))
+	require.ErrorIs(t, plugin(ctx, operation.NewOpContext(collection, operation.WithFilter(query.Eq("name", "burt")))), ErrUnindexedFilter)
+}
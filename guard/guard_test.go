@@ -0,0 +1,97 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package guard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestIsEmptyFilter(t *testing.T) {
+	type user struct {
+		Name string `bson:"name"`
+	}
+	testCases := []struct {
+		name   string
+		filter any
+		want   bool
+	}{
+		{name: "nil", filter: nil, want: true},
+		{name: "empty bson.D", filter: bson.D{}, want: true},
+		{name: "empty bson.M", filter: bson.M{}, want: true},
+		{name: "empty builder", filter: query.NewBuilder(), want: true},
+		{name: "empty $and", filter: bson.D{{Key: "$and", Value: bson.A{}}}, want: true},
+		{name: "zero example", filter: &user{}, want: true},
+		{name: "bson.D", filter: query.Id(1), want: false},
+		{name: "bson.M", filter: bson.M{"name": "burt"}, want: false},
+		{name: "example", filter: &user{Name: "burt"}, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, IsEmptyFilter(tc.filter))
+		})
+	}
+}
+
+func TestIndexedFilter(t *testing.T) {
+	var lookups int
+	plugin := IndexedFilter(func(ctx context.Context, coll *mongo.Collection) ([]string, error) {
+		lookups++
+		return []string{"_id", "email", "attrs.$**"}, nil
+	})
+
+	testCases := []struct {
+		name    string
+		filter  any
+		wantErr error
+	}{
+		{name: "empty filter", filter: nil},
+		{name: "indexed field", filter: bson.M{"email": "burt@example.com", "age": 18}},
+		{name: "wildcard index", filter: query.Eq("attrs.color", "red")},
+		{name: "indexed $and clause", filter: query.And(query.Gt("age", 18), query.Id(1))},
+		{name: "indexed $or clauses", filter: query.Or(query.Id(1), bson.M{"email": "burt@example.com"})},
+		{name: "$text", filter: bson.D{{Key: "$text", Value: bson.D{{Key: "$search", Value: "burt"}}}}},
+		{name: "unindexed field", filter: query.Gt("age", 18), wantErr: ErrUnindexedFilter},
+		{name: "prefix of an index field", filter: query.Eq("attrs", "red"), wantErr: ErrUnindexedFilter},
+		{name: "unindexed $or clause", filter: query.Or(query.Id(1), query.Gt("age", 18)), wantErr: ErrUnindexedFilter},
+		{name: "$nor", filter: query.Nor(query.Id(1)), wantErr: ErrUnindexedFilter},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opCtx := operation.NewOpContext(&mongo.Collection{}, operation.WithFilter(tc.filter))
+			err := plugin(context.Background(), opCtx)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.wantErr)
+			}
+		})
+	}
+	assert.Equal(t, 1, lookups)
+}
+
+func TestIndexedFilter_LookupError(t *testing.T) {
+	plugin := IndexedFilter(func(ctx context.Context, coll *mongo.Collection) ([]string, error) {
+		return nil, assert.AnError
+	})
+	err := plugin(context.Background(), operation.NewOpContext(&mongo.Collection{}, operation.WithFilter(query.Id(1))))
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package guard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+)
+
+type untrustedKey struct{}
+
+// Untrusted returns a copy of ctx marking the operations executed with it as built from untrusted input,
+// e.g. in the middleware of the REST handlers, so that their filters are sanitized by the plugin of Sanitizer
+func Untrusted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, untrustedKey{}, true)
+}
+
+// IsUntrusted reports whether ctx is marked by Untrusted
+func IsUntrusted(ctx context.Context) bool {
+	untrusted, _ := ctx.Value(untrustedKey{}).(bool)
+	return untrusted
+}
+
+// Sanitizer returns a plugin checking the filters of the operations executed with a context marked by Untrusted
+// against policy, see query.Sanitize. With query.StripUnsafe the filter of the operation is replaced by the sanitized one,
+// and query.ErrUnsafeFilter is returned if none of its conditions is safe.
+// Note that the whole filter is checked, including the default scopes and the conditions added by the code,
+// so their operators must be allowed by policy too. The plugin is registered for the before operation types, e.g.
+//
+//	db.RegisterPlugin("guard:sanitizer", guard.Sanitizer(policy), operation.OpTypeBeforeAny)
+func Sanitizer(policy *query.SanitizePolicy) callback.CbFn {
+	return func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+		if !IsUntrusted(ctx) {
+			return nil
+		}
+		filter, err := query.Sanitize(opCtx.Filter, policy)
+		if err != nil {
+			return err
+		}
+		if policy == nil || policy.Mode != query.StripUnsafe {
+			return nil
+		}
+		if len(filter) == 0 && !IsEmptyFilter(opCtx.Filter) {
+			return fmt.Errorf("%w: all the conditions were stripped", query.ErrUnsafeFilter)
+		}
+		opCtx.Filter = filter
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package guard_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/deleter"
+	"github.com/chenmingyong0423/go-mongox/v2/guard"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestSanitizer(t *testing.T) {
+	injected := map[string]any{"email": "burt@example.com", "password": map[string]any{"$ne": nil}}
+	testCases := []struct {
+		name   string
+		ctx    context.Context
+		policy *query.SanitizePolicy
+		filter any
+
+		wantFilter any
+		wantErr    error
+	}{
+		{
+			name:       "trusted",
+			ctx:        context.Background(),
+			filter:     injected,
+			wantFilter: injected,
+		},
+		{
+			name:    "rejected",
+			ctx:     guard.Untrusted(context.Background()),
+			filter:  injected,
+			wantErr: query.ErrUnsafeFilter,
+		},
+		{
+			name:       "safe",
+			ctx:        guard.Untrusted(context.Background()),
+			policy:     &query.SanitizePolicy{Mode: query.StripUnsafe},
+			filter:     bson.M{"email": "burt@example.com"},
+			wantFilter: bson.D{{Key: "email", Value: "burt@example.com"}},
+		},
+		{
+			name:       "stripped",
+			ctx:        guard.Untrusted(context.Background()),
+			policy:     &query.SanitizePolicy{Mode: query.StripUnsafe},
+			filter:     injected,
+			wantFilter: bson.D{{Key: "email", Value: "burt@example.com"}},
+		},
+		{
+			name:    "all stripped",
+			ctx:     guard.Untrusted(context.Background()),
+			policy:  &query.SanitizePolicy{Mode: query.StripUnsafe},
+			filter:  bson.M{"password": bson.M{"$ne": nil}},
+			wantErr: query.ErrUnsafeFilter,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opCtx := operation.NewOpContext(&mongo.Collection{}, operation.WithFilter(tc.filter))
+			err := guard.Sanitizer(tc.policy)(tc.ctx, opCtx)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantFilter, opCtx.Filter)
+		})
+	}
+}
+
+func TestSanitizer_Deleter(t *testing.T) {
+	callbacks := callback.InitializeCallbacks()
+	callbacks.Register(operation.OpTypeBeforeDelete, "guard:sanitizer", guard.Sanitizer(&query.SanitizePolicy{Mode: query.StripUnsafe}))
+
+	errStop := errors.New("stop")
+	var filter any
+	_, err := deleter.NewDeleter[any](&mongo.Collection{}, callbacks, nil).
+		Filter(bson.M{"_id": "1", "owner": bson.M{"$ne": ""}}).
+		RegisterBeforeHooks(func(ctx context.Context, opContext *deleter.OpContext, opts ...any) error {
+			filter = opContext.Filter
+			return errStop
+		}).
+		DeleteOne(guard.Untrusted(context.Background()))
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, bson.D{{Key: "_id", Value: "1"}}, filter)
+}
@@ -45,6 +45,13 @@ func Execute(ctx context.Context, opCtx *operation.OpContext, opType operation.O
 			return nil
 		}
 	case operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert:
+		// a replacement document is completed as a whole
+		if valueOf := reflect.ValueOf(opCtx.Updates); valueOf.Kind() == reflect.Ptr && !valueOf.IsNil() && valueOf.Elem().Kind() == reflect.Struct {
+			return beforeReplace(valueOf.Elem(), opCtx.StartTime, opCtx.Fields)
+		}
+		if opType == operation.OpTypeBeforeUpsert {
+			return beforeUpsert(opCtx.Updates, opCtx.Filter, opCtx.StartTime, opCtx.Fields)
+		}
 		return execute(ctx, opCtx.Updates, opType, opCtx.StartTime, opCtx.Fields, opts...)
 	}
 	return nil
@@ -20,6 +20,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/chenmingyong0423/go-mongox/v2/field"
 	"github.com/chenmingyong0423/go-mongox/v2/operation"
 	"github.com/stretchr/testify/assert"
 
@@ -188,6 +189,14 @@ func TestExecute(t *testing.T) {
 			opts:    nil,
 			wantErr: nil,
 		},
+		{
+			name:    "replacement",
+			ctx:     context.Background(),
+			opCtx:   operation.NewOpContext(nil, operation.WithUpdates(&model{}), operation.WithFields(field.ParseFields(&model{}))),
+			opType:  operation.OpTypeBeforeUpdate,
+			opts:    nil,
+			wantErr: nil,
+		},
 	}
 
 	for _, tc := range testCases {
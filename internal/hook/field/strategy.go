@@ -16,8 +16,10 @@ package field
 
 import (
 	"reflect"
+	"strings"
 	"time"
 
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
 	"github.com/chenmingyong0423/go-mongox/v2/field"
 
 	"github.com/chenmingyong0423/go-mongox/v2/operation"
@@ -27,7 +29,6 @@ import (
 var strategies = map[operation.OpType]func(dest any, currentTime time.Time, fields []*field.Filed, opts ...any) error{
 	operation.OpTypeBeforeInsert: beforeInsert,
 	operation.OpTypeBeforeUpdate: beforeUpdate,
-	operation.OpTypeBeforeUpsert: beforeUpsert,
 }
 
 func beforeInsert(dest any, currentTime time.Time, fields []*field.Filed, _ ...any) error {
@@ -55,12 +56,36 @@ func processFields4Insert(dest reflect.Value, currentTime time.Time, fields []*f
 				}
 			} else {
 				handleTimeField(value, fd, currentTime)
+				handleDefaultField(value, fd, currentTime)
 			}
 		}
 	}
 	return nil
 }
 
+// beforeReplace completes a replacement document like an inserted one, except that its autoUpdateTime fields
+// are always set to the current time
+func beforeReplace(dest reflect.Value, currentTime time.Time, fields []*field.Filed) error {
+	if err := processFields4Insert(dest, currentTime, fields); err != nil {
+		return err
+	}
+	setUpdateTimeFields(dest, currentTime, fields)
+	return nil
+}
+
+func setUpdateTimeFields(dest reflect.Value, currentTime time.Time, fields []*field.Filed) {
+	for idx, fd := range fields {
+		value := dest.Field(idx)
+		switch {
+		case fd.InlinedFields != nil:
+			setUpdateTimeFields(value, currentTime, fd.InlinedFields)
+		case fd.AutoUpdateTime != 0:
+			value.Set(reflect.Zero(value.Type()))
+			setTimeField(value, fd.AutoUpdateTime, currentTime, fd.FieldType)
+		}
+	}
+}
+
 // 设置时间字段
 func handleTimeField(dest reflect.Value, fd *field.Filed, currentTime time.Time) {
 	switch {
@@ -71,6 +96,21 @@ func handleTimeField(dest reflect.Value, fd *field.Filed, currentTime time.Time)
 	}
 }
 
+// 设置默认值
+func handleDefaultField(dest reflect.Value, fd *field.Filed, currentTime time.Time) {
+	if fd.Default == nil || !dest.IsZero() {
+		return
+	}
+	value := reflect.ValueOf(fd.Default.Get(currentTime))
+	if dest.Kind() == reflect.Ptr {
+		ptr := reflect.New(dest.Type().Elem())
+		ptr.Elem().Set(value.Convert(dest.Type().Elem()))
+		dest.Set(ptr)
+		return
+	}
+	dest.Set(value.Convert(dest.Type()))
+}
+
 // 设置具体的时间值
 func setTimeField(dest reflect.Value, timeType field.TimeType, currentTime time.Time, fieldType reflect.Type) {
 	if !dest.IsZero() {
@@ -118,7 +158,9 @@ func beforeUpdate(dest any, currentTime time.Time, fields []*field.Filed, _ ...a
 	return nil
 }
 
-func beforeUpsert(dest any, currentTime time.Time, fields []*field.Filed, _ ...any) error {
+// beforeUpsert completes the updates of an upsert, the fields added to $setOnInsert skip the paths
+// already assigned by the updates or by the equality conditions of filter, which the server would reject as a conflict
+func beforeUpsert(dest any, filter any, currentTime time.Time, fields []*field.Filed) error {
 	updates, exist := dest.(bson.M)
 	if !exist || updates == nil {
 		return nil
@@ -142,6 +184,17 @@ func beforeUpsert(dest any, currentTime time.Time, fields []*field.Filed, _ ...a
 	}
 
 	idAndCreateFields := findAdditionalFields(currentTime, fields, findUpsertFields)
+	for k, v := range findAdditionalFields(currentTime, fields, findDefaultFields) {
+		if _, ok := idAndCreateFields[k]; !ok {
+			idAndCreateFields[k] = v
+		}
+	}
+	assigned := append(updatedPaths(updates), equalityPaths(query.Merge(filter))...)
+	for k := range idAndCreateFields {
+		if conflicts(k, assigned) {
+			delete(idAndCreateFields, k)
+		}
+	}
 	if len(idAndCreateFields) > 0 {
 		if updates["$setOnInsert"] == nil {
 			updates["$setOnInsert"] = bson.M{}
@@ -188,6 +241,91 @@ func findUpsertFields(fd *field.Filed, currentTime time.Time) (string, any) {
 	return "", nil
 }
 
+func findDefaultFields(fd *field.Filed, currentTime time.Time) (string, any) {
+	if fd.Default != nil {
+		return fd.MongoField, fd.Default.Get(currentTime)
+	}
+	return "", nil
+}
+
+// updatedPaths returns the paths assigned by the operators of updates, e.g. profile.age for {$inc: {"profile.age": 1}}
+func updatedPaths(updates bson.M) []string {
+	var paths []string
+	for op, value := range updates {
+		if !strings.HasPrefix(op, "$") {
+			continue
+		}
+		switch v := value.(type) {
+		case bson.M:
+			for k := range v {
+				paths = append(paths, k)
+			}
+		case bson.D:
+			for _, e := range v {
+				paths = append(paths, e.Key)
+			}
+		}
+	}
+	return paths
+}
+
+// equalityPaths returns the paths of the equality conditions of filter, which an upsert copies into the inserted document
+func equalityPaths(filter bson.D) []string {
+	var paths []string
+	for _, e := range filter {
+		switch {
+		case e.Key == query.AndOp:
+			if clauses, ok := e.Value.([]any); ok {
+				for _, clause := range clauses {
+					paths = append(paths, equalityPaths(query.Merge(clause))...)
+				}
+			}
+		case strings.HasPrefix(e.Key, "$"):
+		case isOperatorDoc(e.Value) && !hasEq(e.Value):
+		default:
+			paths = append(paths, e.Key)
+		}
+	}
+	return paths
+}
+
+func isOperatorDoc(value any) bool {
+	switch v := value.(type) {
+	case bson.D:
+		return len(v) > 0 && strings.HasPrefix(v[0].Key, "$")
+	case bson.M:
+		for k := range v {
+			return strings.HasPrefix(k, "$")
+		}
+	}
+	return false
+}
+
+func hasEq(value any) bool {
+	switch v := value.(type) {
+	case bson.D:
+		for _, e := range v {
+			if e.Key == query.EqOp {
+				return true
+			}
+		}
+	case bson.M:
+		_, ok := v[query.EqOp]
+		return ok
+	}
+	return false
+}
+
+// conflicts reports whether path is one of paths, or a parent or a child of one of them
+func conflicts(path string, paths []string) bool {
+	for _, p := range paths {
+		if p == path || strings.HasPrefix(p, path+".") || strings.HasPrefix(path, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
 func findUpdatedFields(fd *field.Filed, currentTime time.Time) (string, any) {
 	if fd.AutoUpdateTime != 0 {
 		return fd.MongoField, getTimeValue(fd.AutoUpdateTime, currentTime)
@@ -23,6 +23,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
 	"github.com/chenmingyong0423/go-mongox/v2/field"
 
 	"github.com/stretchr/testify/assert"
@@ -206,6 +207,20 @@ func TestBeforeInsert(t *testing.T) {
 				require.NotZero(t, u.UpdatedAt)
 			},
 		},
+		{
+			name:        "default values",
+			doc:         reflect.ValueOf(&defaultUser{Age: 24}),
+			currentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			fields:      field.ParseFields(&defaultUser{}),
+			validateFunc: func(t *testing.T, v any) {
+				u, ok := v.(*defaultUser)
+				require.True(t, ok)
+				require.Equal(t, "active", u.Status)
+				require.Equal(t, 24, u.Age)
+				require.Equal(t, "mongox", *u.Nickname)
+				require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), u.ActivatedAt)
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -318,6 +333,7 @@ func Test_beforeUpsert(t *testing.T) {
 	tests := []struct {
 		name        string
 		updates     any
+		filter      any
 		currentTime time.Time
 		fields      []*field.Filed
 		want        any
@@ -392,16 +408,89 @@ func Test_beforeUpsert(t *testing.T) {
 			}{}),
 			want: bson.M{"$set": bson.M{"name": "Mingyong Chen", "updated_at": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Unix()}, "$setOnInsert": bson.M{"created_at": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Unix()}},
 		},
+		{
+			name:        "a bson.M updates with default values",
+			updates:     bson.M{"$set": bson.M{"status": "banned"}, "$setOnInsert": bson.M{"age": 1}},
+			currentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			fields:      field.ParseFields(&defaultUser{}),
+			want:        bson.M{"$set": bson.M{"status": "banned"}, "$setOnInsert": bson.M{"age": 1, "nickname": "mongox", "activated_at": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		},
+		{
+			name:        "default values of paths updated by other operators",
+			updates:     bson.M{"$inc": bson.M{"age": 1}, "$unset": bson.D{{Key: "nickname", Value: ""}}, "$set": bson.M{"activated_at.time": 1}},
+			currentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			fields:      field.ParseFields(&defaultUser{}),
+			want:        bson.M{"$inc": bson.M{"age": 1}, "$unset": bson.D{{Key: "nickname", Value: ""}}, "$set": bson.M{"activated_at.time": 1}, "$setOnInsert": bson.M{"status": "active"}},
+		},
+		{
+			name:        "default values of children of updated paths",
+			updates:     bson.M{"$push": bson.M{"profile": "x"}},
+			currentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			fields: field.ParseFields(&struct {
+				City string `bson:"profile.city" mongox:"default:paris"`
+				Role string `bson:"role" mongox:"default:user"`
+			}{}),
+			want: bson.M{"$push": bson.M{"profile": "x"}, "$setOnInsert": bson.M{"role": "user"}},
+		},
+		{
+			name:        "default values of the equality conditions of the filter",
+			updates:     bson.M{"$set": bson.M{"name": "Mingyong Chen"}},
+			filter:      bson.D{{Key: "status", Value: "banned"}, {Key: "age", Value: bson.M{"$eq": 20}}, {Key: "nickname", Value: bson.D{{Key: "$ne", Value: "x"}}}, {Key: "$or", Value: bson.A{bson.M{"activated_at": nil}}}},
+			currentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			fields:      field.ParseFields(&defaultUser{}),
+			want:        bson.M{"$set": bson.M{"name": "Mingyong Chen"}, "$setOnInsert": bson.M{"nickname": "mongox", "activated_at": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		},
+		{
+			name:        "id of the filter",
+			updates:     bson.M{"$set": bson.M{"name": "Mingyong Chen"}},
+			filter:      query.And(query.Id("1"), query.Eq("age", 20), query.Gt("age", 1)),
+			currentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			fields: field.ParseFields(&struct {
+				ID  bson.ObjectID `bson:"_id,omitempty"`
+				Age int           `bson:"age" mongox:"default:18"`
+			}{}),
+			want: bson.M{"$set": bson.M{"name": "Mingyong Chen"}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := beforeUpsert(tt.updates, tt.currentTime, tt.fields)
+			err := beforeUpsert(tt.updates, tt.filter, tt.currentTime, tt.fields)
 			require.Equal(t, tt.wantErr, err)
 			require.Equal(t, tt.want, tt.updates)
 		})
 	}
 }
 
+func Test_beforeReplace(t *testing.T) {
+	currentTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := &updatedUser{Name: "Mingyong Chen", CreatedAt: createdAt, UpdatedAt: createdAt, UpdateSecondTime: 1}
+	require.NoError(t, beforeReplace(reflect.ValueOf(u).Elem(), currentTime, field.ParseFields(u)))
+	require.Equal(t, &updatedUser{
+		Name:             "Mingyong Chen",
+		CreatedAt:        createdAt,
+		UpdatedAt:        currentTime,
+		CreateSecondTime: currentTime.Unix(),
+		UpdateSecondTime: currentTime.Unix(),
+		CreateMilliTime:  currentTime.UnixMilli(),
+		UpdateMilliTime:  currentTime.UnixMilli(),
+		CreateNanoTime:   currentTime.UnixNano(),
+		UpdateNanoTime:   currentTime.UnixNano(),
+	}, u)
+
+	d := &defaultUser{Age: 20}
+	require.NoError(t, beforeReplace(reflect.ValueOf(d).Elem(), currentTime, field.ParseFields(d)))
+	nickname := "mongox"
+	require.Equal(t, &defaultUser{Status: "active", Age: 20, Nickname: &nickname, ActivatedAt: currentTime}, d)
+}
+
+type defaultUser struct {
+	Status      string    `bson:"status" mongox:"default:active"`
+	Age         int       `bson:"age" mongox:"default:18"`
+	Nickname    *string   `bson:"nickname" mongox:"default:mongox"`
+	ActivatedAt time.Time `bson:"activated_at" mongox:"default:now"`
+}
+
 func Test_getTimeValue(t *testing.T) {
 	t.Run("invalid type", func(t *testing.T) {
 		require.Nil(t, getTimeValue(0, time.Time{}))
@@ -0,0 +1,57 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+	"github.com/chenmingyong0423/go-mongox/v2/validator"
+)
+
+// Execute validates the documents of the operation when the fields declare validate tags, and the values assigned
+// by its updates against the type and the validate tags of the target fields
+func Execute(_ context.Context, opCtx *operation.OpContext, opType operation.OpType, _ ...any) error {
+	if opCtx == nil {
+		return nil
+	}
+	switch opType {
+	case operation.OpTypeBeforeInsert:
+		valueOf := opCtx.ReflectValue
+		if !valueOf.IsValid() || !validator.HasRules(opCtx.Fields) {
+			return nil
+		}
+		switch valueOf.Kind() {
+		case reflect.Slice:
+			return validator.ValidateMany(valueOf, opCtx.Fields)
+		case reflect.Ptr:
+			if valueOf.IsNil() {
+				return nil
+			}
+			return validator.Validate(valueOf, opCtx.Fields)
+		}
+	case operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert:
+		// a replacement document is validated as a whole
+		if valueOf := reflect.ValueOf(opCtx.Updates); valueOf.Kind() == reflect.Ptr && !valueOf.IsNil() && valueOf.Elem().Kind() == reflect.Struct {
+			if !validator.HasRules(opCtx.Fields) {
+				return nil
+			}
+			return validator.Validate(valueOf, opCtx.Fields)
+		}
+		return validator.ValidateUpdates(opCtx.Updates, opCtx.Fields)
+	}
+	return nil
+}
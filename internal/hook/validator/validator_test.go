@@ -0,0 +1,115 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+	"github.com/chenmingyong0423/go-mongox/v2/validator"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type user struct {
+	Name string `bson:"name" validate:"required"`
+}
+
+func TestExecute(t *testing.T) {
+	fields := field.ParseFields(&user{})
+
+	testCases := []struct {
+		name   string
+		opCtx  *operation.OpContext
+		opType operation.OpType
+
+		wantErr bool
+	}{
+		{
+			name:   "nil opCtx",
+			opType: operation.OpTypeBeforeInsert,
+		},
+		{
+			name:   "no rules",
+			opCtx:  operation.NewOpContext(nil, operation.WithReflectValue(reflect.ValueOf(&struct{ Name string }{})), operation.WithFields(field.ParseFields(&struct{ Name string }{}))),
+			opType: operation.OpTypeBeforeInsert,
+		},
+		{
+			name:    "invalid document",
+			opCtx:   operation.NewOpContext(nil, operation.WithReflectValue(reflect.ValueOf(&user{})), operation.WithFields(fields)),
+			opType:  operation.OpTypeBeforeInsert,
+			wantErr: true,
+		},
+		{
+			name:   "nil document",
+			opCtx:  operation.NewOpContext(nil, operation.WithReflectValue(reflect.ValueOf((*user)(nil))), operation.WithFields(fields)),
+			opType: operation.OpTypeBeforeInsert,
+		},
+		{
+			name:    "invalid documents",
+			opCtx:   operation.NewOpContext(nil, operation.WithReflectValue(reflect.ValueOf([]*user{{Name: "chen"}, {}})), operation.WithFields(fields)),
+			opType:  operation.OpTypeBeforeInsert,
+			wantErr: true,
+		},
+		{
+			name:    "invalid updates",
+			opCtx:   operation.NewOpContext(nil, operation.WithUpdates(bson.M{"$set": bson.M{"name": ""}}), operation.WithFields(fields)),
+			opType:  operation.OpTypeBeforeUpdate,
+			wantErr: true,
+		},
+		{
+			name:    "updates of fields without rules",
+			opCtx:   operation.NewOpContext(nil, operation.WithUpdates(bson.M{"$set": bson.M{"Name": 1}}), operation.WithFields(field.ParseFields(&struct{ Name string }{}))),
+			opType:  operation.OpTypeBeforeUpdate,
+			wantErr: true,
+		},
+		{
+			name:   "replacement without rules",
+			opCtx:  operation.NewOpContext(nil, operation.WithUpdates(&struct{ Name string }{}), operation.WithFields(field.ParseFields(&struct{ Name string }{}))),
+			opType: operation.OpTypeBeforeUpdate,
+		},
+		{
+			name:    "invalid replacement",
+			opCtx:   operation.NewOpContext(nil, operation.WithUpdates(&user{}), operation.WithFields(fields)),
+			opType:  operation.OpTypeBeforeUpsert,
+			wantErr: true,
+		},
+		{
+			name:   "valid updates",
+			opCtx:  operation.NewOpContext(nil, operation.WithUpdates(bson.M{"$set": bson.M{"name": "chen"}}), operation.WithFields(fields)),
+			opType: operation.OpTypeBeforeUpsert,
+		},
+		{
+			name:   "other operation",
+			opCtx:  operation.NewOpContext(nil, operation.WithFilter(bson.M{"name": ""}), operation.WithFields(fields)),
+			opType: operation.OpTypeBeforeFind,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Execute(context.Background(), tc.opCtx, tc.opType)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *validator.ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driveropt
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+)
+
+// Options holds the driver options set by the fluent methods of the builders.
+// The options supported by an operation are appended to its listers, the others are ignored.
+type Options struct {
+	Hint         any
+	Comment      any
+	Let          any
+	BatchSize    *int32
+	AllowDiskUse *bool
+	// MaxTime bounds the context of the operation, the driver has no per-operation maxTimeMS anymore
+	MaxTime time.Duration
+
+	readPreference *readpref.ReadPref
+	readConcern    *readconcern.ReadConcern
+	writeConcern   *writeconcern.WriteConcern
+}
+
+func (o *Options) SetReadPreference(rp *readpref.ReadPref) {
+	o.readPreference = rp
+}
+
+func (o *Options) SetReadConcern(rc *readconcern.ReadConcern) {
+	o.readConcern = rc
+}
+
+func (o *Options) SetWriteConcern(wc *writeconcern.WriteConcern) {
+	o.writeConcern = wc
+}
+
+// Context returns ctx bounded by MaxTime, the returned cancel must be called once the operation is done
+func (o *Options) Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.MaxTime <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, o.MaxTime)
+}
+
+// Collection returns a clone of coll with the read preference, read concern and write concern, coll if none is set
+func (o *Options) Collection(coll *mongo.Collection) *mongo.Collection {
+	if o.readPreference == nil && o.readConcern == nil && o.writeConcern == nil {
+		return coll
+	}
+	opts := options.Collection()
+	if o.readPreference != nil {
+		opts.SetReadPreference(o.readPreference)
+	}
+	if o.readConcern != nil {
+		opts.SetReadConcern(o.readConcern)
+	}
+	if o.writeConcern != nil {
+		opts.SetWriteConcern(o.writeConcern)
+	}
+	return coll.Clone(opts)
+}
+
+func (o *Options) Find(opts []options.Lister[options.FindOptions]) []options.Lister[options.FindOptions] {
+	if o.Hint == nil && o.Comment == nil && o.Let == nil && o.BatchSize == nil && o.AllowDiskUse == nil {
+		return opts
+	}
+	b := options.Find()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	if o.Let != nil {
+		b.SetLet(o.Let)
+	}
+	if o.BatchSize != nil {
+		b.SetBatchSize(*o.BatchSize)
+	}
+	if o.AllowDiskUse != nil {
+		b.SetAllowDiskUse(*o.AllowDiskUse)
+	}
+	return append(opts, b)
+}
+
+func (o *Options) FindOne(opts []options.Lister[options.FindOneOptions]) []options.Lister[options.FindOneOptions] {
+	if o.Hint == nil && o.Comment == nil {
+		return opts
+	}
+	b := options.FindOne()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	return append(opts, b)
+}
+
+func (o *Options) Count(opts []options.Lister[options.CountOptions]) []options.Lister[options.CountOptions] {
+	if o.Hint == nil && o.Comment == nil {
+		return opts
+	}
+	b := options.Count()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	return append(opts, b)
+}
+
+func (o *Options) Distinct(opts []options.Lister[options.DistinctOptions]) []options.Lister[options.DistinctOptions] {
+	if o.Hint == nil && o.Comment == nil {
+		return opts
+	}
+	b := options.Distinct()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	return append(opts, b)
+}
+
+func (o *Options) FindOneAndUpdate(opts []options.Lister[options.FindOneAndUpdateOptions]) []options.Lister[options.FindOneAndUpdateOptions] {
+	if o.Hint == nil && o.Comment == nil && o.Let == nil {
+		return opts
+	}
+	b := options.FindOneAndUpdate()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	if o.Let != nil {
+		b.SetLet(o.Let)
+	}
+	return append(opts, b)
+}
+
+func (o *Options) UpdateOne(opts []options.Lister[options.UpdateOneOptions]) []options.Lister[options.UpdateOneOptions] {
+	if o.Hint == nil && o.Comment == nil && o.Let == nil {
+		return opts
+	}
+	b := options.UpdateOne()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	if o.Let != nil {
+		b.SetLet(o.Let)
+	}
+	return append(opts, b)
+}
+
+func (o *Options) ReplaceOne(opts []options.Lister[options.ReplaceOptions]) []options.Lister[options.ReplaceOptions] {
+	if o.Hint == nil && o.Comment == nil && o.Let == nil {
+		return opts
+	}
+	b := options.Replace()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	if o.Let != nil {
+		b.SetLet(o.Let)
+	}
+	return append(opts, b)
+}
+
+func (o *Options) UpdateMany(opts []options.Lister[options.UpdateManyOptions]) []options.Lister[options.UpdateManyOptions] {
+	if o.Hint == nil && o.Comment == nil && o.Let == nil {
+		return opts
+	}
+	b := options.UpdateMany()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	if o.Let != nil {
+		b.SetLet(o.Let)
+	}
+	return append(opts, b)
+}
+
+func (o *Options) DeleteOne(opts []options.Lister[options.DeleteOneOptions]) []options.Lister[options.DeleteOneOptions] {
+	if o.Hint == nil && o.Comment == nil && o.Let == nil {
+		return opts
+	}
+	b := options.DeleteOne()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	if o.Let != nil {
+		b.SetLet(o.Let)
+	}
+	return append(opts, b)
+}
+
+func (o *Options) DeleteMany(opts []options.Lister[options.DeleteManyOptions]) []options.Lister[options.DeleteManyOptions] {
+	if o.Hint == nil && o.Comment == nil && o.Let == nil {
+		return opts
+	}
+	b := options.DeleteMany()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	if o.Let != nil {
+		b.SetLet(o.Let)
+	}
+	return append(opts, b)
+}
+
+func (o *Options) Aggregate(opts []options.Lister[options.AggregateOptions]) []options.Lister[options.AggregateOptions] {
+	if o.Hint == nil && o.Comment == nil && o.Let == nil && o.BatchSize == nil && o.AllowDiskUse == nil {
+		return opts
+	}
+	b := options.Aggregate()
+	if o.Hint != nil {
+		b.SetHint(o.Hint)
+	}
+	if o.Comment != nil {
+		b.SetComment(o.Comment)
+	}
+	if o.Let != nil {
+		b.SetLet(o.Let)
+	}
+	if o.BatchSize != nil {
+		b.SetBatchSize(*o.BatchSize)
+	}
+	if o.AllowDiskUse != nil {
+		b.SetAllowDiskUse(*o.AllowDiskUse)
+	}
+	return append(opts, b)
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driveropt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestOptions_Context(t *testing.T) {
+	var o Options
+	ctx, cancel := o.Context(context.Background())
+	cancel()
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+
+	o.MaxTime = time.Minute
+	ctx, cancel = o.Context(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+}
+
+func TestOptions_Collection(t *testing.T) {
+	var o Options
+	coll := &mongo.Collection{}
+	assert.Same(t, coll, o.Collection(coll))
+}
+
+func TestOptions_UpdateOne(t *testing.T) {
+	var o Options
+	assert.Empty(t, o.UpdateOne(nil))
+
+	o.Hint = "name_1"
+	o.Let = map[string]any{"x": 1}
+	o.BatchSize = new(int32)
+	opts := o.UpdateOne(nil)
+	require.Len(t, opts, 1)
+
+	var updateOptions options.UpdateOneOptions
+	for _, set := range opts[0].List() {
+		require.NoError(t, set(&updateOptions))
+	}
+	assert.Equal(t, "name_1", updateOptions.Hint)
+	assert.Equal(t, map[string]any{"x": 1}, updateOptions.Let)
+	assert.Nil(t, updateOptions.Comment)
+}
+
+func TestOptions_ReplaceOne(t *testing.T) {
+	var o Options
+	assert.Empty(t, o.ReplaceOne(nil))
+
+	o.Comment = "replace"
+	opts := o.ReplaceOne(nil)
+	require.Len(t, opts, 1)
+
+	var replaceOptions options.ReplaceOptions
+	for _, set := range opts[0].List() {
+		require.NoError(t, set(&replaceOptions))
+	}
+	assert.Equal(t, "replace", replaceOptions.Comment)
+	assert.Nil(t, replaceOptions.Hint)
+}
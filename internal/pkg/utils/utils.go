@@ -15,14 +15,140 @@
 package utils
 
 import (
+	"encoding/binary"
 	"fmt"
+	"math"
 	"reflect"
+	"strings"
+	"time"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
 
 	"go.mongodb.org/mongo-driver/v2/mongo"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	valueMarshalerType = reflect.TypeOf((*bson.ValueMarshaler)(nil)).Elem()
+	bsonPkgPath        = reflect.TypeOf(bson.D{}).PkgPath()
+)
+
+// IsDocument reports whether the values of t are encoded as embedded documents whose fields can be addressed by dot paths
+func IsDocument(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == timeType || t.PkgPath() == bsonPkgPath {
+		return false
+	}
+	return !t.Implements(valueMarshalerType) && !reflect.PointerTo(t).Implements(valueMarshalerType)
+}
+
+// IDValue returns the _id field of v, a struct or a pointer to a struct described by fields, looking into inlined structs
+func IDValue(v reflect.Value, fields []*field.Filed) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for idx, fd := range fields {
+		if idx >= v.NumField() {
+			break
+		}
+		if fd.InlinedFields != nil {
+			if value, ok := IDValue(v.Field(idx), fd.InlinedFields); ok {
+				return value, true
+			}
+			continue
+		}
+		if fd.MongoField == "_id" {
+			return v.Field(idx), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// SetZeroID assigns id to the _id field of v when it is zero, e.g. with the id generated by the driver on insert
+func SetZeroID(v reflect.Value, fields []*field.Filed, id any) {
+	value, ok := IDValue(v, fields)
+	if !ok || !value.IsZero() || !value.CanSet() || id == nil {
+		return
+	}
+	if idValue := reflect.ValueOf(id); idValue.Type().AssignableTo(value.Type()) {
+		value.Set(idValue)
+	}
+}
+
+// BsonKey returns a map key identifying v by its bson type and encoding, e.g. to index documents by _id.
+// The integral numbers have the same key whatever their type, as the server matches them by value:
+// an int id encoded as int32 identifies the document whose _id is stored as int64.
+func BsonKey(v any) (string, error) {
+	rv, ok := v.(bson.RawValue)
+	if !ok {
+		typ, data, err := bson.MarshalValue(v)
+		if err != nil {
+			return "", err
+		}
+		rv = bson.RawValue{Type: typ, Value: data}
+	}
+	if n, ok := integral(rv); ok {
+		key := make([]byte, 9)
+		key[0] = byte(bson.TypeInt64)
+		binary.LittleEndian.PutUint64(key[1:], uint64(n))
+		return string(key), nil
+	}
+	return string(append([]byte{byte(rv.Type)}, rv.Value...)), nil
+}
+
+// integral returns the value of the int32, int64 and integral double values
+func integral(rv bson.RawValue) (int64, bool) {
+	switch rv.Type {
+	case bson.TypeInt32:
+		n, ok := rv.Int32OK()
+		return int64(n), ok
+	case bson.TypeInt64:
+		return rv.Int64OK()
+	case bson.TypeDouble:
+		f, ok := rv.DoubleOK()
+		if !ok || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
+
+// LookupKeys returns the BsonKey of the value of the (dotted) key of doc, or of each of its elements for arrays.
+// It returns nothing if doc doesn't have the key.
+func LookupKeys(doc any, key string) ([]string, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	value, err := bson.Raw(raw).LookupErr(strings.Split(key, ".")...)
+	if err != nil {
+		return nil, nil
+	}
+	values := []bson.RawValue{value}
+	if array, ok := value.ArrayOK(); ok {
+		if values, err = array.Values(); err != nil {
+			return nil, err
+		}
+	}
+	keys := make([]string, 0, len(values))
+	for _, v := range values {
+		k, err := BsonKey(v)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
 func ToPtr[T any](v T) *T {
 	return &v
 }
@@ -103,3 +229,23 @@ func IsNumeric(value any) bool {
 		return false
 	}
 }
+
+// CopyUpdates returns a copy of updates and of its operator documents, e.g. $set,
+// so that the hooks adding fields to the updates of an operation don't modify the updates of the builder
+func CopyUpdates(updates bson.M) bson.M {
+	m := make(bson.M, len(updates))
+	for op, value := range updates {
+		switch values := value.(type) {
+		case bson.M:
+			copied := make(bson.M, len(values))
+			for k, v := range values {
+				copied[k] = v
+			}
+			value = copied
+		case bson.D:
+			value = append(bson.D(nil), values...)
+		}
+		m[op] = value
+	}
+	return m
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestBsonKey(t *testing.T) {
+	key := func(v any) string {
+		k, err := BsonKey(v)
+		require.NoError(t, err)
+		return k
+	}
+	_, data, err := bson.MarshalValue(int64(7))
+	require.NoError(t, err)
+	raw := bson.RawValue{Type: bson.TypeInt64, Value: data}
+
+	assert.Equal(t, key(int64(7)), key(7))
+	assert.Equal(t, key(int64(7)), key(int32(7)))
+	assert.Equal(t, key(int64(7)), key(7.0))
+	assert.Equal(t, key(int64(7)), key(raw))
+	assert.NotEqual(t, key(int64(7)), key(7.5))
+	assert.NotEqual(t, key(int64(7)), key("7"))
+	assert.NotEqual(t, key(int64(7)), key(int64(8)))
+	assert.Equal(t, key("a"), key("a"))
+}
+
+func TestLookupKeys(t *testing.T) {
+	key := func(v any) string {
+		k, err := BsonKey(v)
+		require.NoError(t, err)
+		return k
+	}
+	id := bson.NewObjectID()
+
+	keys, err := LookupKeys(bson.D{{Key: "_id", Value: id}}, "_id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{key(id)}, keys)
+
+	keys, err = LookupKeys(bson.D{{Key: "post", Value: bson.D{{Key: "ids", Value: bson.A{"a", "b"}}}}}, "post.ids")
+	require.NoError(t, err)
+	assert.Equal(t, []string{key("a"), key("b")}, keys)
+
+	keys, err = LookupKeys(bson.D{{Key: "name", Value: "a"}}, "_id")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestCopyUpdates(t *testing.T) {
+	updates := bson.M{
+		"$set":   bson.M{"name": "chenmingyong"},
+		"$unset": bson.D{{Key: "age", Value: ""}},
+		"$inc":   1,
+	}
+	copied := CopyUpdates(updates)
+	assert.Equal(t, updates, copied)
+
+	copied["$set"].(bson.M)["updated_at"] = 1
+	copied["$unset"].(bson.D)[0].Key = "email"
+	copied["$setOnInsert"] = bson.M{}
+	assert.Equal(t, bson.M{
+		"$set":   bson.M{"name": "chenmingyong"},
+		"$unset": bson.D{{Key: "age", Value: ""}},
+		"$inc":   1,
+	}, updates)
+}
@@ -12,11 +12,15 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	query "github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	collation "github.com/chenmingyong0423/go-mongox/v2/collation"
 	deleter "github.com/chenmingyong0423/go-mongox/v2/deleter"
 	operation "github.com/chenmingyong0423/go-mongox/v2/operation"
 	mongo "go.mongodb.org/mongo-driver/v2/mongo"
 	options "go.mongodb.org/mongo-driver/v2/mongo/options"
+	writeconcern "go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -24,7 +28,6 @@ import (
 type MockIDeleter[T any] struct {
 	ctrl     *gomock.Controller
 	recorder *MockIDeleterMockRecorder[T]
-	isgomock struct{}
 }
 
 // MockIDeleterMockRecorder is the mock recorder for MockIDeleter.
@@ -44,6 +47,48 @@ func (m *MockIDeleter[T]) EXPECT() *MockIDeleterMockRecorder[T] {
 	return m.recorder
 }
 
+// AllowFullCollection mocks base method.
+func (m *MockIDeleter[T]) AllowFullCollection() deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowFullCollection")
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// AllowFullCollection indicates an expected call of AllowFullCollection.
+func (mr *MockIDeleterMockRecorder[T]) AllowFullCollection() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowFullCollection", reflect.TypeOf((*MockIDeleter[T])(nil).AllowFullCollection))
+}
+
+// Collation mocks base method.
+func (m *MockIDeleter[T]) Collation(collation *collation.Collation) deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Collation", collation)
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// Collation indicates an expected call of Collation.
+func (mr *MockIDeleterMockRecorder[T]) Collation(collation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Collation", reflect.TypeOf((*MockIDeleter[T])(nil).Collation), collation)
+}
+
+// Comment mocks base method.
+func (m *MockIDeleter[T]) Comment(comment any) deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Comment", comment)
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// Comment indicates an expected call of Comment.
+func (mr *MockIDeleterMockRecorder[T]) Comment(comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Comment", reflect.TypeOf((*MockIDeleter[T])(nil).Comment), comment)
+}
+
 // DeleteMany mocks base method.
 func (m *MockIDeleter[T]) DeleteMany(ctx context.Context, opts ...options.Lister[options.DeleteManyOptions]) (*mongo.DeleteResult, error) {
 	m.ctrl.T.Helper()
@@ -112,6 +157,48 @@ func (mr *MockIDeleterMockRecorder[T]) GetCollection() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollection", reflect.TypeOf((*MockIDeleter[T])(nil).GetCollection))
 }
 
+// Hint mocks base method.
+func (m *MockIDeleter[T]) Hint(hint any) deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hint", hint)
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// Hint indicates an expected call of Hint.
+func (mr *MockIDeleterMockRecorder[T]) Hint(hint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hint", reflect.TypeOf((*MockIDeleter[T])(nil).Hint), hint)
+}
+
+// Let mocks base method.
+func (m *MockIDeleter[T]) Let(let any) deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Let", let)
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// Let indicates an expected call of Let.
+func (mr *MockIDeleterMockRecorder[T]) Let(let any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Let", reflect.TypeOf((*MockIDeleter[T])(nil).Let), let)
+}
+
+// MaxTime mocks base method.
+func (m *MockIDeleter[T]) MaxTime(maxTime time.Duration) deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxTime", maxTime)
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// MaxTime indicates an expected call of MaxTime.
+func (mr *MockIDeleterMockRecorder[T]) MaxTime(maxTime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxTime", reflect.TypeOf((*MockIDeleter[T])(nil).MaxTime), maxTime)
+}
+
 // ModelHook mocks base method.
 func (m *MockIDeleter[T]) ModelHook(modelHook any) deleter.IDeleter[T] {
 	m.ctrl.T.Helper()
@@ -126,6 +213,42 @@ func (mr *MockIDeleterMockRecorder[T]) ModelHook(modelHook any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModelHook", reflect.TypeOf((*MockIDeleter[T])(nil).ModelHook), modelHook)
 }
 
+// Not mocks base method.
+func (m *MockIDeleter[T]) Not(conds ...any) deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Not", varargs...)
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// Not indicates an expected call of Not.
+func (mr *MockIDeleterMockRecorder[T]) Not(conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Not", reflect.TypeOf((*MockIDeleter[T])(nil).Not), conds...)
+}
+
+// OrWhere mocks base method.
+func (m *MockIDeleter[T]) OrWhere(conds ...any) deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "OrWhere", varargs...)
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// OrWhere indicates an expected call of OrWhere.
+func (mr *MockIDeleterMockRecorder[T]) OrWhere(conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrWhere", reflect.TypeOf((*MockIDeleter[T])(nil).OrWhere), conds...)
+}
+
 // PostActionHandler mocks base method.
 func (m *MockIDeleter[T]) PostActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *deleter.OpContext, opType operation.OpType) error {
 	m.ctrl.T.Helper()
@@ -189,3 +312,67 @@ func (mr *MockIDeleterMockRecorder[T]) RegisterBeforeHooks(hooks ...any) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterBeforeHooks", reflect.TypeOf((*MockIDeleter[T])(nil).RegisterBeforeHooks), hooks...)
 }
+
+// Scopes mocks base method.
+func (m *MockIDeleter[T]) Scopes(scopes ...query.Scope) deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range scopes {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scopes", varargs...)
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// Scopes indicates an expected call of Scopes.
+func (mr *MockIDeleterMockRecorder[T]) Scopes(scopes ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scopes", reflect.TypeOf((*MockIDeleter[T])(nil).Scopes), scopes...)
+}
+
+// Unscoped mocks base method.
+func (m *MockIDeleter[T]) Unscoped() deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unscoped")
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// Unscoped indicates an expected call of Unscoped.
+func (mr *MockIDeleterMockRecorder[T]) Unscoped() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unscoped", reflect.TypeOf((*MockIDeleter[T])(nil).Unscoped))
+}
+
+// Where mocks base method.
+func (m *MockIDeleter[T]) Where(conds ...any) deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Where", varargs...)
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// Where indicates an expected call of Where.
+func (mr *MockIDeleterMockRecorder[T]) Where(conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Where", reflect.TypeOf((*MockIDeleter[T])(nil).Where), conds...)
+}
+
+// WriteConcern mocks base method.
+func (m *MockIDeleter[T]) WriteConcern(wc *writeconcern.WriteConcern) deleter.IDeleter[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteConcern", wc)
+	ret0, _ := ret[0].(deleter.IDeleter[T])
+	return ret0
+}
+
+// WriteConcern indicates an expected call of WriteConcern.
+func (mr *MockIDeleterMockRecorder[T]) WriteConcern(wc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteConcern", reflect.TypeOf((*MockIDeleter[T])(nil).WriteConcern), wc)
+}
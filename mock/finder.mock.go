@@ -12,11 +12,18 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	projection "github.com/chenmingyong0423/go-mongox/v2/builder/projection"
+	query "github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	collation "github.com/chenmingyong0423/go-mongox/v2/collation"
 	finder "github.com/chenmingyong0423/go-mongox/v2/finder"
 	operation "github.com/chenmingyong0423/go-mongox/v2/operation"
 	mongo "go.mongodb.org/mongo-driver/v2/mongo"
 	options "go.mongodb.org/mongo-driver/v2/mongo/options"
+	readconcern "go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	readpref "go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	writeconcern "go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -24,7 +31,6 @@ import (
 type MockIFinder[T any] struct {
 	ctrl     *gomock.Controller
 	recorder *MockIFinderMockRecorder[T]
-	isgomock struct{}
 }
 
 // MockIFinderMockRecorder is the mock recorder for MockIFinder.
@@ -44,6 +50,62 @@ func (m *MockIFinder[T]) EXPECT() *MockIFinderMockRecorder[T] {
 	return m.recorder
 }
 
+// AllowDiskUse mocks base method.
+func (m *MockIFinder[T]) AllowDiskUse(allowDiskUse bool) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowDiskUse", allowDiskUse)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// AllowDiskUse indicates an expected call of AllowDiskUse.
+func (mr *MockIFinderMockRecorder[T]) AllowDiskUse(allowDiskUse any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowDiskUse", reflect.TypeOf((*MockIFinder[T])(nil).AllowDiskUse), allowDiskUse)
+}
+
+// BatchSize mocks base method.
+func (m *MockIFinder[T]) BatchSize(batchSize int32) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchSize", batchSize)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// BatchSize indicates an expected call of BatchSize.
+func (mr *MockIFinderMockRecorder[T]) BatchSize(batchSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchSize", reflect.TypeOf((*MockIFinder[T])(nil).BatchSize), batchSize)
+}
+
+// Collation mocks base method.
+func (m *MockIFinder[T]) Collation(collation *collation.Collation) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Collation", collation)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Collation indicates an expected call of Collation.
+func (mr *MockIFinderMockRecorder[T]) Collation(collation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Collation", reflect.TypeOf((*MockIFinder[T])(nil).Collation), collation)
+}
+
+// Comment mocks base method.
+func (m *MockIFinder[T]) Comment(comment any) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Comment", comment)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Comment indicates an expected call of Comment.
+func (mr *MockIFinderMockRecorder[T]) Comment(comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Comment", reflect.TypeOf((*MockIFinder[T])(nil).Comment), comment)
+}
+
 // Count mocks base method.
 func (m *MockIFinder[T]) Count(ctx context.Context, opts ...options.Lister[options.CountOptions]) (int64, error) {
 	m.ctrl.T.Helper()
@@ -136,6 +198,27 @@ func (mr *MockIFinderMockRecorder[T]) Find(ctx any, opts ...any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockIFinder[T])(nil).Find), varargs...)
 }
 
+// FindByIDs mocks base method.
+func (m *MockIFinder[T]) FindByIDs(ctx context.Context, ids any, opts ...*finder.FindByIDsOptions) ([]*T, []any, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, ids}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindByIDs", varargs...)
+	ret0, _ := ret[0].([]*T)
+	ret1, _ := ret[1].([]any)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindByIDs indicates an expected call of FindByIDs.
+func (mr *MockIFinderMockRecorder[T]) FindByIDs(ctx, ids any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, ids}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDs", reflect.TypeOf((*MockIFinder[T])(nil).FindByIDs), varargs...)
+}
+
 // FindOne mocks base method.
 func (m *MockIFinder[T]) FindOne(ctx context.Context, opts ...options.Lister[options.FindOneOptions]) (*T, error) {
 	m.ctrl.T.Helper()
@@ -190,6 +273,34 @@ func (mr *MockIFinderMockRecorder[T]) GetCollection() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollection", reflect.TypeOf((*MockIFinder[T])(nil).GetCollection))
 }
 
+// Hint mocks base method.
+func (m *MockIFinder[T]) Hint(hint any) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hint", hint)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Hint indicates an expected call of Hint.
+func (mr *MockIFinderMockRecorder[T]) Hint(hint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hint", reflect.TypeOf((*MockIFinder[T])(nil).Hint), hint)
+}
+
+// Let mocks base method.
+func (m *MockIFinder[T]) Let(let any) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Let", let)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Let indicates an expected call of Let.
+func (mr *MockIFinderMockRecorder[T]) Let(let any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Let", reflect.TypeOf((*MockIFinder[T])(nil).Let), let)
+}
+
 // Limit mocks base method.
 func (m *MockIFinder[T]) Limit(limit int64) finder.IFinder[T] {
 	m.ctrl.T.Helper()
@@ -204,6 +315,20 @@ func (mr *MockIFinderMockRecorder[T]) Limit(limit any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Limit", reflect.TypeOf((*MockIFinder[T])(nil).Limit), limit)
 }
 
+// MaxTime mocks base method.
+func (m *MockIFinder[T]) MaxTime(maxTime time.Duration) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxTime", maxTime)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// MaxTime indicates an expected call of MaxTime.
+func (mr *MockIFinderMockRecorder[T]) MaxTime(maxTime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxTime", reflect.TypeOf((*MockIFinder[T])(nil).MaxTime), maxTime)
+}
+
 // ModelHook mocks base method.
 func (m *MockIFinder[T]) ModelHook(modelHook any) finder.IFinder[T] {
 	m.ctrl.T.Helper()
@@ -218,6 +343,42 @@ func (mr *MockIFinderMockRecorder[T]) ModelHook(modelHook any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModelHook", reflect.TypeOf((*MockIFinder[T])(nil).ModelHook), modelHook)
 }
 
+// Not mocks base method.
+func (m *MockIFinder[T]) Not(conds ...any) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Not", varargs...)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Not indicates an expected call of Not.
+func (mr *MockIFinderMockRecorder[T]) Not(conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Not", reflect.TypeOf((*MockIFinder[T])(nil).Not), conds...)
+}
+
+// OrWhere mocks base method.
+func (m *MockIFinder[T]) OrWhere(conds ...any) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "OrWhere", varargs...)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// OrWhere indicates an expected call of OrWhere.
+func (mr *MockIFinderMockRecorder[T]) OrWhere(conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrWhere", reflect.TypeOf((*MockIFinder[T])(nil).OrWhere), conds...)
+}
+
 // PostActionHandler mocks base method.
 func (m *MockIFinder[T]) PostActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *finder.OpContext[T], opTypes ...operation.OpType) error {
 	m.ctrl.T.Helper()
@@ -256,6 +417,80 @@ func (mr *MockIFinderMockRecorder[T]) PreActionHandler(ctx, globalOpContext, opC
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreActionHandler", reflect.TypeOf((*MockIFinder[T])(nil).PreActionHandler), varargs...)
 }
 
+// Preload mocks base method.
+func (m *MockIFinder[T]) Preload(paths ...string) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range paths {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Preload", varargs...)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Preload indicates an expected call of Preload.
+func (mr *MockIFinderMockRecorder[T]) Preload(paths ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preload", reflect.TypeOf((*MockIFinder[T])(nil).Preload), paths...)
+}
+
+// Project mocks base method.
+func (m *MockIFinder[T]) Project(projection any) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Project", projection)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Project indicates an expected call of Project.
+func (mr *MockIFinderMockRecorder[T]) Project(projection any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Project", reflect.TypeOf((*MockIFinder[T])(nil).Project), projection)
+}
+
+// Projection mocks base method.
+func (m *MockIFinder[T]) Projection(builder *projection.Builder) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Projection", builder)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Projection indicates an expected call of Projection.
+func (mr *MockIFinderMockRecorder[T]) Projection(builder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Projection", reflect.TypeOf((*MockIFinder[T])(nil).Projection), builder)
+}
+
+// ReadConcern mocks base method.
+func (m *MockIFinder[T]) ReadConcern(rc *readconcern.ReadConcern) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadConcern", rc)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// ReadConcern indicates an expected call of ReadConcern.
+func (mr *MockIFinderMockRecorder[T]) ReadConcern(rc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadConcern", reflect.TypeOf((*MockIFinder[T])(nil).ReadConcern), rc)
+}
+
+// ReadPreference mocks base method.
+func (m *MockIFinder[T]) ReadPreference(rp *readpref.ReadPref) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadPreference", rp)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// ReadPreference indicates an expected call of ReadPreference.
+func (mr *MockIFinderMockRecorder[T]) ReadPreference(rp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPreference", reflect.TypeOf((*MockIFinder[T])(nil).ReadPreference), rp)
+}
+
 // RegisterAfterHooks mocks base method.
 func (m *MockIFinder[T]) RegisterAfterHooks(hooks ...finder.AfterHookFn[T]) finder.IFinder[T] {
 	m.ctrl.T.Helper()
@@ -292,6 +527,24 @@ func (mr *MockIFinderMockRecorder[T]) RegisterBeforeHooks(hooks ...any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterBeforeHooks", reflect.TypeOf((*MockIFinder[T])(nil).RegisterBeforeHooks), hooks...)
 }
 
+// Scopes mocks base method.
+func (m *MockIFinder[T]) Scopes(scopes ...query.Scope) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range scopes {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scopes", varargs...)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Scopes indicates an expected call of Scopes.
+func (mr *MockIFinderMockRecorder[T]) Scopes(scopes ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scopes", reflect.TypeOf((*MockIFinder[T])(nil).Scopes), scopes...)
+}
+
 // Skip mocks base method.
 func (m *MockIFinder[T]) Skip(skip int64) finder.IFinder[T] {
 	m.ctrl.T.Helper()
@@ -320,6 +573,20 @@ func (mr *MockIFinderMockRecorder[T]) Sort(sort any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sort", reflect.TypeOf((*MockIFinder[T])(nil).Sort), sort)
 }
 
+// Unscoped mocks base method.
+func (m *MockIFinder[T]) Unscoped() finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unscoped")
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Unscoped indicates an expected call of Unscoped.
+func (mr *MockIFinderMockRecorder[T]) Unscoped() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unscoped", reflect.TypeOf((*MockIFinder[T])(nil).Unscoped))
+}
+
 // Updates mocks base method.
 func (m *MockIFinder[T]) Updates(update any) finder.IFinder[T] {
 	m.ctrl.T.Helper()
@@ -333,3 +600,54 @@ func (mr *MockIFinderMockRecorder[T]) Updates(update any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Updates", reflect.TypeOf((*MockIFinder[T])(nil).Updates), update)
 }
+
+// Where mocks base method.
+func (m *MockIFinder[T]) Where(conds ...any) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Where", varargs...)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// Where indicates an expected call of Where.
+func (mr *MockIFinderMockRecorder[T]) Where(conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Where", reflect.TypeOf((*MockIFinder[T])(nil).Where), conds...)
+}
+
+// WhereExample mocks base method.
+func (m *MockIFinder[T]) WhereExample(example *T, includeZero ...string) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{example}
+	for _, a := range includeZero {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WhereExample", varargs...)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// WhereExample indicates an expected call of WhereExample.
+func (mr *MockIFinderMockRecorder[T]) WhereExample(example any, includeZero ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{example}, includeZero...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WhereExample", reflect.TypeOf((*MockIFinder[T])(nil).WhereExample), varargs...)
+}
+
+// WriteConcern mocks base method.
+func (m *MockIFinder[T]) WriteConcern(wc *writeconcern.WriteConcern) finder.IFinder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteConcern", wc)
+	ret0, _ := ret[0].(finder.IFinder[T])
+	return ret0
+}
+
+// WriteConcern indicates an expected call of WriteConcern.
+func (mr *MockIFinderMockRecorder[T]) WriteConcern(wc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteConcern", reflect.TypeOf((*MockIFinder[T])(nil).WriteConcern), wc)
+}
@@ -0,0 +1,260 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=repository.go -destination=../mock/repository.mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	creator "github.com/chenmingyong0423/go-mongox/v2/creator"
+	deleter "github.com/chenmingyong0423/go-mongox/v2/deleter"
+	finder "github.com/chenmingyong0423/go-mongox/v2/finder"
+	repository "github.com/chenmingyong0423/go-mongox/v2/repository"
+	updater "github.com/chenmingyong0423/go-mongox/v2/updater"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIRepository is a mock of IRepository interface.
+type MockIRepository[T any, ID any] struct {
+	ctrl     *gomock.Controller
+	recorder *MockIRepositoryMockRecorder[T, ID]
+}
+
+// MockIRepositoryMockRecorder is the mock recorder for MockIRepository.
+type MockIRepositoryMockRecorder[T any, ID any] struct {
+	mock *MockIRepository[T, ID]
+}
+
+// NewMockIRepository creates a new mock instance.
+func NewMockIRepository[T any, ID any](ctrl *gomock.Controller) *MockIRepository[T, ID] {
+	mock := &MockIRepository[T, ID]{ctrl: ctrl}
+	mock.recorder = &MockIRepositoryMockRecorder[T, ID]{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIRepository[T, ID]) EXPECT() *MockIRepositoryMockRecorder[T, ID] {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockIRepository[T, ID]) Count(ctx context.Context, filter any) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockIRepositoryMockRecorder[T, ID]) Count(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockIRepository[T, ID])(nil).Count), ctx, filter)
+}
+
+// Create mocks base method.
+func (m *MockIRepository[T, ID]) Create(ctx context.Context, docs ...*T) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range docs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIRepositoryMockRecorder[T, ID]) Create(ctx any, docs ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, docs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIRepository[T, ID])(nil).Create), varargs...)
+}
+
+// Delete mocks base method.
+func (m *MockIRepository[T, ID]) Delete(ctx context.Context, id ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIRepositoryMockRecorder[T, ID]) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIRepository[T, ID])(nil).Delete), ctx, id)
+}
+
+// Exists mocks base method.
+func (m *MockIRepository[T, ID]) Exists(ctx context.Context, filter any) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", ctx, filter)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockIRepositoryMockRecorder[T, ID]) Exists(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockIRepository[T, ID])(nil).Exists), ctx, filter)
+}
+
+// GetByID mocks base method.
+func (m *MockIRepository[T, ID]) GetByID(ctx context.Context, id ID) (*T, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*T)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockIRepositoryMockRecorder[T, ID]) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockIRepository[T, ID])(nil).GetByID), ctx, id)
+}
+
+// GetByIDs mocks base method.
+func (m *MockIRepository[T, ID]) GetByIDs(ctx context.Context, ids []ID) ([]*T, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*T)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockIRepositoryMockRecorder[T, ID]) GetByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockIRepository[T, ID])(nil).GetByIDs), ctx, ids)
+}
+
+// List mocks base method.
+func (m *MockIRepository[T, ID]) List(ctx context.Context, filter, sort any, page repository.Page) ([]*T, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter, sort, page)
+	ret0, _ := ret[0].([]*T)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIRepositoryMockRecorder[T, ID]) List(ctx, filter, sort, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIRepository[T, ID])(nil).List), ctx, filter, sort, page)
+}
+
+// Patch mocks base method.
+func (m *MockIRepository[T, ID]) Patch(ctx context.Context, id ID, patch any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, id, patch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockIRepositoryMockRecorder[T, ID]) Patch(ctx, id, patch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockIRepository[T, ID])(nil).Patch), ctx, id, patch)
+}
+
+// Save mocks base method.
+func (m *MockIRepository[T, ID]) Save(ctx context.Context, doc *T) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, doc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockIRepositoryMockRecorder[T, ID]) Save(ctx, doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockIRepository[T, ID])(nil).Save), ctx, doc)
+}
+
+// MockCollection is a mock of Collection interface.
+type MockCollection[T any] struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollectionMockRecorder[T]
+}
+
+// MockCollectionMockRecorder is the mock recorder for MockCollection.
+type MockCollectionMockRecorder[T any] struct {
+	mock *MockCollection[T]
+}
+
+// NewMockCollection creates a new mock instance.
+func NewMockCollection[T any](ctrl *gomock.Controller) *MockCollection[T] {
+	mock := &MockCollection[T]{ctrl: ctrl}
+	mock.recorder = &MockCollectionMockRecorder[T]{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollection[T]) EXPECT() *MockCollectionMockRecorder[T] {
+	return m.recorder
+}
+
+// Creator mocks base method.
+func (m *MockCollection[T]) Creator() *creator.Creator[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Creator")
+	ret0, _ := ret[0].(*creator.Creator[T])
+	return ret0
+}
+
+// Creator indicates an expected call of Creator.
+func (mr *MockCollectionMockRecorder[T]) Creator() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Creator", reflect.TypeOf((*MockCollection[T])(nil).Creator))
+}
+
+// Deleter mocks base method.
+func (m *MockCollection[T]) Deleter() *deleter.Deleter[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deleter")
+	ret0, _ := ret[0].(*deleter.Deleter[T])
+	return ret0
+}
+
+// Deleter indicates an expected call of Deleter.
+func (mr *MockCollectionMockRecorder[T]) Deleter() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deleter", reflect.TypeOf((*MockCollection[T])(nil).Deleter))
+}
+
+// Finder mocks base method.
+func (m *MockCollection[T]) Finder() *finder.Finder[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finder")
+	ret0, _ := ret[0].(*finder.Finder[T])
+	return ret0
+}
+
+// Finder indicates an expected call of Finder.
+func (mr *MockCollectionMockRecorder[T]) Finder() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finder", reflect.TypeOf((*MockCollection[T])(nil).Finder))
+}
+
+// Updater mocks base method.
+func (m *MockCollection[T]) Updater() *updater.Updater[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Updater")
+	ret0, _ := ret[0].(*updater.Updater[T])
+	return ret0
+}
+
+// Updater indicates an expected call of Updater.
+func (mr *MockCollectionMockRecorder[T]) Updater() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Updater", reflect.TypeOf((*MockCollection[T])(nil).Updater))
+}
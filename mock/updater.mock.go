@@ -12,11 +12,16 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	query "github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	update "github.com/chenmingyong0423/go-mongox/v2/builder/update"
+	collation "github.com/chenmingyong0423/go-mongox/v2/collation"
 	operation "github.com/chenmingyong0423/go-mongox/v2/operation"
 	updater "github.com/chenmingyong0423/go-mongox/v2/updater"
 	mongo "go.mongodb.org/mongo-driver/v2/mongo"
 	options "go.mongodb.org/mongo-driver/v2/mongo/options"
+	writeconcern "go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -24,7 +29,6 @@ import (
 type MockIUpdater[T any] struct {
 	ctrl     *gomock.Controller
 	recorder *MockIUpdaterMockRecorder[T]
-	isgomock struct{}
 }
 
 // MockIUpdaterMockRecorder is the mock recorder for MockIUpdater.
@@ -44,6 +48,48 @@ func (m *MockIUpdater[T]) EXPECT() *MockIUpdaterMockRecorder[T] {
 	return m.recorder
 }
 
+// AllowFullCollection mocks base method.
+func (m *MockIUpdater[T]) AllowFullCollection() updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowFullCollection")
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// AllowFullCollection indicates an expected call of AllowFullCollection.
+func (mr *MockIUpdaterMockRecorder[T]) AllowFullCollection() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowFullCollection", reflect.TypeOf((*MockIUpdater[T])(nil).AllowFullCollection))
+}
+
+// Collation mocks base method.
+func (m *MockIUpdater[T]) Collation(collation *collation.Collation) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Collation", collation)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// Collation indicates an expected call of Collation.
+func (mr *MockIUpdaterMockRecorder[T]) Collation(collation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Collation", reflect.TypeOf((*MockIUpdater[T])(nil).Collation), collation)
+}
+
+// Comment mocks base method.
+func (m *MockIUpdater[T]) Comment(comment any) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Comment", comment)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// Comment indicates an expected call of Comment.
+func (mr *MockIUpdaterMockRecorder[T]) Comment(comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Comment", reflect.TypeOf((*MockIUpdater[T])(nil).Comment), comment)
+}
+
 // Filter mocks base method.
 func (m *MockIUpdater[T]) Filter(filter any) updater.IUpdater[T] {
 	m.ctrl.T.Helper()
@@ -72,6 +118,48 @@ func (mr *MockIUpdaterMockRecorder[T]) GetCollection() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollection", reflect.TypeOf((*MockIUpdater[T])(nil).GetCollection))
 }
 
+// Hint mocks base method.
+func (m *MockIUpdater[T]) Hint(hint any) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hint", hint)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// Hint indicates an expected call of Hint.
+func (mr *MockIUpdaterMockRecorder[T]) Hint(hint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hint", reflect.TypeOf((*MockIUpdater[T])(nil).Hint), hint)
+}
+
+// Let mocks base method.
+func (m *MockIUpdater[T]) Let(let any) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Let", let)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// Let indicates an expected call of Let.
+func (mr *MockIUpdaterMockRecorder[T]) Let(let any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Let", reflect.TypeOf((*MockIUpdater[T])(nil).Let), let)
+}
+
+// MaxTime mocks base method.
+func (m *MockIUpdater[T]) MaxTime(maxTime time.Duration) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxTime", maxTime)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// MaxTime indicates an expected call of MaxTime.
+func (mr *MockIUpdaterMockRecorder[T]) MaxTime(maxTime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxTime", reflect.TypeOf((*MockIUpdater[T])(nil).MaxTime), maxTime)
+}
+
 // ModelHook mocks base method.
 func (m *MockIUpdater[T]) ModelHook(modelHook any) updater.IUpdater[T] {
 	m.ctrl.T.Helper()
@@ -86,6 +174,42 @@ func (mr *MockIUpdaterMockRecorder[T]) ModelHook(modelHook any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModelHook", reflect.TypeOf((*MockIUpdater[T])(nil).ModelHook), modelHook)
 }
 
+// Not mocks base method.
+func (m *MockIUpdater[T]) Not(conds ...any) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Not", varargs...)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// Not indicates an expected call of Not.
+func (mr *MockIUpdaterMockRecorder[T]) Not(conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Not", reflect.TypeOf((*MockIUpdater[T])(nil).Not), conds...)
+}
+
+// OrWhere mocks base method.
+func (m *MockIUpdater[T]) OrWhere(conds ...any) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "OrWhere", varargs...)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// OrWhere indicates an expected call of OrWhere.
+func (mr *MockIUpdaterMockRecorder[T]) OrWhere(conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrWhere", reflect.TypeOf((*MockIUpdater[T])(nil).OrWhere), conds...)
+}
+
 // PostActionHandler mocks base method.
 func (m *MockIUpdater[T]) PostActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *updater.OpContext, opType operation.OpType) error {
 	m.ctrl.T.Helper()
@@ -150,6 +274,26 @@ func (mr *MockIUpdaterMockRecorder[T]) RegisterBeforeHooks(hooks ...any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterBeforeHooks", reflect.TypeOf((*MockIUpdater[T])(nil).RegisterBeforeHooks), hooks...)
 }
 
+// ReplaceOne mocks base method.
+func (m *MockIUpdater[T]) ReplaceOne(ctx context.Context, opts ...options.Lister[options.ReplaceOptions]) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReplaceOne", varargs...)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceOne indicates an expected call of ReplaceOne.
+func (mr *MockIUpdaterMockRecorder[T]) ReplaceOne(ctx any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceOne", reflect.TypeOf((*MockIUpdater[T])(nil).ReplaceOne), varargs...)
+}
+
 // Replacement mocks base method.
 func (m *MockIUpdater[T]) Replacement(replacement any) updater.IUpdater[T] {
 	m.ctrl.T.Helper()
@@ -164,6 +308,57 @@ func (mr *MockIUpdaterMockRecorder[T]) Replacement(replacement any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replacement", reflect.TypeOf((*MockIUpdater[T])(nil).Replacement), replacement)
 }
 
+// Scopes mocks base method.
+func (m *MockIUpdater[T]) Scopes(scopes ...query.Scope) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range scopes {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scopes", varargs...)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// Scopes indicates an expected call of Scopes.
+func (mr *MockIUpdaterMockRecorder[T]) Scopes(scopes ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scopes", reflect.TypeOf((*MockIUpdater[T])(nil).Scopes), scopes...)
+}
+
+// SetStruct mocks base method.
+func (m *MockIUpdater[T]) SetStruct(v any, opts ...*update.StructOptions) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{v}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetStruct", varargs...)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// SetStruct indicates an expected call of SetStruct.
+func (mr *MockIUpdaterMockRecorder[T]) SetStruct(v any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{v}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStruct", reflect.TypeOf((*MockIUpdater[T])(nil).SetStruct), varargs...)
+}
+
+// Unscoped mocks base method.
+func (m *MockIUpdater[T]) Unscoped() updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unscoped")
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// Unscoped indicates an expected call of Unscoped.
+func (mr *MockIUpdaterMockRecorder[T]) Unscoped() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unscoped", reflect.TypeOf((*MockIUpdater[T])(nil).Unscoped))
+}
+
 // UpdateMany mocks base method.
 func (m *MockIUpdater[T]) UpdateMany(ctx context.Context, opts ...options.Lister[options.UpdateManyOptions]) (*mongo.UpdateResult, error) {
 	m.ctrl.T.Helper()
@@ -237,3 +432,35 @@ func (mr *MockIUpdaterMockRecorder[T]) Upsert(ctx any, opts ...any) *gomock.Call
 	varargs := append([]any{ctx}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockIUpdater[T])(nil).Upsert), varargs...)
 }
+
+// Where mocks base method.
+func (m *MockIUpdater[T]) Where(conds ...any) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range conds {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Where", varargs...)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// Where indicates an expected call of Where.
+func (mr *MockIUpdaterMockRecorder[T]) Where(conds ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Where", reflect.TypeOf((*MockIUpdater[T])(nil).Where), conds...)
+}
+
+// WriteConcern mocks base method.
+func (m *MockIUpdater[T]) WriteConcern(wc *writeconcern.WriteConcern) updater.IUpdater[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteConcern", wc)
+	ret0, _ := ret[0].(updater.IUpdater[T])
+	return ret0
+}
+
+// WriteConcern indicates an expected call of WriteConcern.
+func (mr *MockIUpdaterMockRecorder[T]) WriteConcern(wc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteConcern", reflect.TypeOf((*MockIUpdater[T])(nil).WriteConcern), wc)
+}
@@ -50,7 +50,7 @@ type OpContext struct {
 	Fields []*field.Filed
 
 	Doc any
-	// filter also can be used as query
+	// filter also can be used as query, the before callbacks of the finders, updaters and deleters can replace it
 	Filter       any
 	Updates      any
 	Pipeline     any
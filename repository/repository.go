@@ -0,0 +1,203 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/update"
+	"github.com/chenmingyong0423/go-mongox/v2/creator"
+	"github.com/chenmingyong0423/go-mongox/v2/deleter"
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/finder"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
+	"github.com/chenmingyong0423/go-mongox/v2/updater"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+//go:generate mockgen -source=repository.go -destination=../mock/repository.mock.go -package=mocks
+type IRepository[T any, ID any] interface {
+	GetByID(ctx context.Context, id ID) (*T, error)
+	GetByIDs(ctx context.Context, ids []ID) ([]*T, error)
+	List(ctx context.Context, filter any, sort any, page Page) ([]*T, error)
+	Exists(ctx context.Context, filter any) (bool, error)
+	Count(ctx context.Context, filter any) (int64, error)
+	Create(ctx context.Context, docs ...*T) error
+	Save(ctx context.Context, doc *T) error
+	Patch(ctx context.Context, id ID, patch any) error
+	Delete(ctx context.Context, id ID) error
+}
+
+// Collection creates the builders used by the repository, it is implemented by *mongox.Collection[T]
+type Collection[T any] interface {
+	Finder() *finder.Finder[T]
+	Creator() *creator.Creator[T]
+	Updater() *updater.Updater[T]
+	Deleter() *deleter.Deleter[T]
+}
+
+// Page selects a page of the results, the zero value selects all of them
+type Page struct {
+	// Number starts from 1
+	Number int64
+	Size   int64
+}
+
+var _ IRepository[any, any] = (*Repository[any, any])(nil)
+
+// Repository provides the common data access methods of the documents of type T whose _id is of type ID.
+// All the methods are built on the finders, creators, updaters and deleters of the collection,
+// so the registered hooks and plugins still run.
+type Repository[T any, ID any] struct {
+	collection Collection[T]
+	fields     []*field.Filed
+}
+
+func New[T any, ID any](collection Collection[T]) *Repository[T, ID] {
+	return &Repository[T, ID]{collection: collection, fields: field.ParseFields(new(T))}
+}
+
+// Collection returns the underlying collection, e.g. to build the queries the repository doesn't provide
+func (r *Repository[T, ID]) Collection() Collection[T] {
+	return r.collection
+}
+
+// GetByID returns the document whose _id is id, or mongo.ErrNoDocuments
+func (r *Repository[T, ID]) GetByID(ctx context.Context, id ID) (*T, error) {
+	return r.collection.Finder().Filter(query.Id(id)).FindOne(ctx)
+}
+
+// GetByIDs returns the documents whose _id is in ids, in the order of ids. The missing documents are skipped.
+func (r *Repository[T, ID]) GetByIDs(ctx context.Context, ids []ID) ([]*T, error) {
+	docs, _, err := r.collection.Finder().FindByIDs(ctx, ids)
+	return docs, err
+}
+
+// List returns the page of the documents matching filter, sorted by sort. filter and sort can be nil.
+func (r *Repository[T, ID]) List(ctx context.Context, filter any, sort any, page Page) ([]*T, error) {
+	finder := r.collection.Finder().Filter(orEmpty(filter))
+	if sort != nil {
+		finder = finder.Sort(sort)
+	}
+	if page.Size > 0 {
+		if page.Number > 1 {
+			finder = finder.Skip((page.Number - 1) * page.Size)
+		}
+		finder = finder.Limit(page.Size)
+	}
+	return finder.Find(ctx)
+}
+
+// Exists reports whether a document matches filter
+func (r *Repository[T, ID]) Exists(ctx context.Context, filter any) (bool, error) {
+	count, err := r.collection.Finder().Filter(orEmpty(filter)).Count(ctx, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Count returns the number of the documents matching filter
+func (r *Repository[T, ID]) Count(ctx context.Context, filter any) (int64, error) {
+	return r.collection.Finder().Filter(orEmpty(filter)).Count(ctx)
+}
+
+// Create inserts docs
+func (r *Repository[T, ID]) Create(ctx context.Context, docs ...*T) error {
+	switch len(docs) {
+	case 0:
+		return nil
+	case 1:
+		result, err := r.collection.Creator().InsertOne(ctx, docs[0])
+		if err != nil {
+			return err
+		}
+		utils.SetZeroID(reflect.ValueOf(docs[0]), r.fields, result.InsertedID)
+		return nil
+	default:
+		result, err := r.collection.Creator().InsertMany(ctx, docs)
+		if err != nil {
+			return err
+		}
+		for i, id := range result.InsertedIDs {
+			utils.SetZeroID(reflect.ValueOf(docs[i]), r.fields, id)
+		}
+		return nil
+	}
+}
+
+// Save inserts doc if its _id is zero, otherwise it replaces the document with the same _id,
+// which is inserted if it doesn't exist. The fields missing from doc are removed from the stored document.
+// The default scopes of the collection are skipped, like Collection.Save, so a document hidden by them is
+// replaced instead of being inserted again with the same _id.
+func (r *Repository[T, ID]) Save(ctx context.Context, doc *T) error {
+	id, ok := utils.IDValue(reflect.ValueOf(doc), r.fields)
+	if !ok || id.IsZero() {
+		return r.Create(ctx, doc)
+	}
+	_, err := r.collection.Updater().Unscoped().Filter(query.Id(id.Interface())).Replacement(doc).ReplaceOne(ctx, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Patch updates the document whose _id is id, or returns mongo.ErrNoDocuments.
+// patch is either an update document, e.g. bson.M{"$set": ...}, or a struct whose non-zero fields are set.
+func (r *Repository[T, ID]) Patch(ctx context.Context, id ID, patch any) error {
+	updater := r.collection.Updater().Filter(query.Id(id))
+	if isStruct(patch) {
+		updater = updater.SetStruct(patch, &update.StructOptions{OmitZero: true})
+	} else {
+		updater = updater.Updates(patch)
+	}
+	result, err := updater.UpdateOne(ctx)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Delete deletes the document whose _id is id, or returns mongo.ErrNoDocuments
+func (r *Repository[T, ID]) Delete(ctx context.Context, id ID) error {
+	result, err := r.collection.Deleter().Filter(query.Id(id)).DeleteOne(ctx)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func orEmpty(filter any) any {
+	if filter == nil {
+		return bson.D{}
+	}
+	return filter
+}
+
+func isStruct(v any) bool {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t != nil && t.Kind() == reflect.Struct
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chenmingyong0423/go-mongox/v2"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+type testUser struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	Name      string        `bson:"name"`
+	Age       int           `bson:"age"`
+	Nickname  *string       `bson:"nickname,omitempty"`
+	CreatedAt time.Time     `bson:"created_at"`
+	UpdatedAt time.Time     `bson:"updated_at"`
+}
+
+func getRepository(t *testing.T) *Repository[testUser, bson.ObjectID] {
+	client, err := mongo.Connect(options.Client().ApplyURI("mongodb://localhost:27017").SetAuth(options.Credential{
+		Username:   "test",
+		Password:   "test",
+		AuthSource: "db-test",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, client.Ping(context.Background(), readpref.Primary()))
+	collection := mongox.NewCollection[testUser](mongox.NewClient(client, &mongox.Config{}).NewDatabase("db-test"), "test_user")
+	return New[testUser, bson.ObjectID](collection)
+}
+
+func TestRepository_e2e(t *testing.T) {
+	repository := getRepository(t)
+	ctx := context.Background()
+	defer func() {
+		_, err := repository.Collection().(*mongox.Collection[testUser]).Collection().DeleteMany(ctx, query.NewBuilder().Build())
+		require.NoError(t, err)
+	}()
+
+	users := []*testUser{{Name: "a", Age: 18}, {Name: "b", Age: 24}, {Name: "c", Age: 30}}
+	require.NoError(t, repository.Create(ctx, users...))
+	for _, u := range users {
+		require.False(t, u.ID.IsZero())
+	}
+
+	// GetByID
+	found, err := repository.GetByID(ctx, users[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "b", found.Name)
+	_, err = repository.GetByID(ctx, bson.NewObjectID())
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	// GetByIDs keeps the order of the ids and skips the missing documents
+	found2, err := repository.GetByIDs(ctx, []bson.ObjectID{users[2].ID, bson.NewObjectID(), users[0].ID})
+	require.NoError(t, err)
+	require.Len(t, found2, 2)
+	assert.Equal(t, "c", found2[0].Name)
+	assert.Equal(t, "a", found2[1].Name)
+
+	// List
+	page, err := repository.List(ctx, query.Gte("age", 18), bson.D{{Key: "age", Value: -1}}, Page{Number: 2, Size: 2})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "a", page[0].Name)
+
+	// Exists and Count
+	exists, err := repository.Exists(ctx, query.Eq("name", "b"))
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = repository.Exists(ctx, query.Eq("name", "d"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+	count, err := repository.Count(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// Patch
+	require.NoError(t, repository.Patch(ctx, users[0].ID, &testUser{Age: 19}))
+	require.NoError(t, repository.Patch(ctx, users[0].ID, bson.M{"$set": bson.M{"name": "aa"}}))
+	found, err = repository.GetByID(ctx, users[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "aa", found.Name)
+	assert.Equal(t, 19, found.Age)
+	assert.ErrorIs(t, repository.Patch(ctx, bson.NewObjectID(), &testUser{Age: 19}), mongo.ErrNoDocuments)
+
+	// Save replaces an existing document and inserts the others
+	require.NoError(t, repository.Patch(ctx, users[0].ID, bson.M{"$set": bson.M{"nickname": "x", "legacy": true}}))
+	found, err = repository.GetByID(ctx, users[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.Nickname)
+	found.Name = "a"
+	found.Nickname = nil
+	require.NoError(t, repository.Save(ctx, found))
+	raw, err := repository.Collection().(*mongox.Collection[testUser]).Collection().FindOne(ctx, query.Id(found.ID)).Raw()
+	require.NoError(t, err)
+	assert.Equal(t, "a", raw.Lookup("name").StringValue())
+	// the cleared fields and the ones unknown to the struct are removed
+	_, err = raw.LookupErr("nickname")
+	assert.Error(t, err)
+	_, err = raw.LookupErr("legacy")
+	assert.Error(t, err)
+	newUser := &testUser{ID: bson.NewObjectID(), Name: "d"}
+	require.NoError(t, repository.Save(ctx, newUser))
+	count, err = repository.Count(ctx, query.In("name", "a", "d"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Save replaces the documents hidden by the default scopes
+	scoped := New[testUser, bson.ObjectID](getRepository(t).Collection().(*mongox.Collection[testUser]).UseDefaultScopes(func(b *query.Builder) *query.Builder {
+		return b.Ne("name", "a")
+	}))
+	found.Age = 20
+	require.NoError(t, scoped.Save(ctx, found))
+	raw, err = repository.Collection().(*mongox.Collection[testUser]).Collection().FindOne(ctx, query.Id(found.ID)).Raw()
+	require.NoError(t, err)
+	assert.Equal(t, int32(20), raw.Lookup("age").Int32())
+
+	// Delete
+	require.NoError(t, repository.Delete(ctx, newUser.ID))
+	assert.ErrorIs(t, repository.Delete(ctx, newUser.ID), mongo.ErrNoDocuments)
+}
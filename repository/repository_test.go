@@ -0,0 +1,83 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestRepository_New(t *testing.T) {
+	type user struct {
+		ID   bson.ObjectID `bson:"_id,omitempty"`
+		Name string        `bson:"name"`
+	}
+	collection := mongox.NewCollection[user](mongox.NewClient(&mongo.Client{}, &mongox.Config{}).NewDatabase("db-test"), "repository-test")
+
+	repository := New[user, bson.ObjectID](collection)
+	assert.NotNil(t, repository)
+	assert.Same(t, collection, repository.Collection())
+	assert.Len(t, repository.fields, 2)
+}
+
+func TestRepository_Save_Unscoped(t *testing.T) {
+	type user struct {
+		ID      bson.ObjectID `bson:"_id,omitempty"`
+		Deleted bool          `bson:"deleted"`
+	}
+	errStop := errors.New("stop")
+	var filter any
+	db := mongox.NewClient(&mongo.Client{}, &mongox.Config{}).NewDatabase("db-test")
+	db.RegisterPlugin("capture", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+		filter = opCtx.Filter
+		return errStop
+	}, operation.OpTypeBeforeUpsert)
+	collection := mongox.NewCollection[user](db, "repository-test").UseDefaultScopes(func(b *query.Builder) *query.Builder {
+		return b.Eq("deleted", false)
+	})
+
+	doc := &user{ID: bson.NewObjectID(), Deleted: true}
+	err := New[user, bson.ObjectID](collection).Save(context.Background(), doc)
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, query.Id(doc.ID), filter)
+}
+
+func Test_isStruct(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value any
+		want  bool
+	}{
+		{name: "nil", value: nil, want: false},
+		{name: "bson.M", value: bson.M{"$set": bson.M{"name": "chenmingyong"}}, want: false},
+		{name: "bson.D", value: bson.D{{Key: "$set", Value: bson.D{}}}, want: false},
+		{name: "struct", value: struct{ Name string }{}, want: true},
+		{name: "pointer to struct", value: &struct{ Name string }{}, want: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, isStruct(tc.value))
+		})
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type (
+	// ValidationLevel determines how strictly MongoDB applies the validation rules to existing documents
+	ValidationLevel string
+	// ValidationAction determines whether MongoDB rejects invalid documents or only logs a warning
+	ValidationAction string
+)
+
+const (
+	ValidationLevelOff      ValidationLevel = "off"
+	ValidationLevelStrict   ValidationLevel = "strict"
+	ValidationLevelModerate ValidationLevel = "moderate"
+
+	ValidationActionError ValidationAction = "error"
+	ValidationActionWarn  ValidationAction = "warn"
+)
+
+// bson types used by $jsonSchema
+const (
+	BsonTypeObject   = "object"
+	BsonTypeArray    = "array"
+	BsonTypeString   = "string"
+	BsonTypeBool     = "bool"
+	BsonTypeInt      = "int"
+	BsonTypeLong     = "long"
+	BsonTypeDouble   = "double"
+	BsonTypeDecimal  = "decimal"
+	BsonTypeDate     = "date"
+	BsonTypeObjectID = "objectId"
+	BsonTypeBinData  = "binData"
+	BsonTypeNull     = "null"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	objectIDType   = reflect.TypeOf(bson.ObjectID{})
+	decimal128Type = reflect.TypeOf(bson.Decimal128{})
+	dateTimeType   = reflect.TypeOf(bson.DateTime(0))
+	bytesType      = reflect.TypeOf([]byte(nil))
+)
+
+// FromType derives a $jsonSchema document from the bson tags of T.
+//
+//   - fields with `validate:"required"` are listed in required
+//   - `validate:"oneof=a b"` is translated to enum, min, max and len to the matching length or range keywords
+//   - inlined structs are merged into their parent, other structs become nested objects
+//   - pointers accept null
+//   - encrypted fields (`mongox:"encrypt"`) are binData
+//
+// The result can be used with query.JsonSchema or Database.ApplyValidator.
+func FromType[T any]() bson.D {
+	return objectSchema(reflect.TypeOf((*T)(nil)).Elem(), map[reflect.Type]bool{})
+}
+
+func objectSchema(t reflect.Type, visiting map[reflect.Type]bool) bson.D {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	schema := bson.D{{Key: "bsonType", Value: BsonTypeObject}}
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return schema
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := bson.D{}
+	required := make([]string, 0)
+	addProperties(t, field.ParseFields(reflect.New(t).Interface()), visiting, &properties, &required)
+	if len(properties) > 0 {
+		schema = append(schema, bson.E{Key: "properties", Value: properties})
+	}
+	if len(required) > 0 {
+		schema = append(schema, bson.E{Key: "required", Value: required})
+	}
+	return schema
+}
+
+func addProperties(t reflect.Type, fields []*field.Filed, visiting map[reflect.Type]bool, properties *bson.D, required *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for idx, fd := range fields {
+		structField := t.Field(idx)
+		if !structField.IsExported() {
+			continue
+		}
+		if fd.InlinedFields != nil {
+			addProperties(structField.Type, fd.InlinedFields, visiting, properties, required)
+			continue
+		}
+		if fd.MongoField == "-" {
+			continue
+		}
+		*properties = append(*properties, bson.E{Key: fd.MongoField, Value: fieldSchema(fd, visiting)})
+		if hasRule(fd.ValidateRules, "required") {
+			*required = append(*required, fd.MongoField)
+		}
+	}
+}
+
+func fieldSchema(fd *field.Filed, visiting map[reflect.Type]bool) bson.D {
+	if fd.Encrypt != 0 {
+		return encryptedSchema(fd.FieldType)
+	}
+	schema := typeSchema(fd.FieldType, visiting)
+	for _, rule := range fd.ValidateRules {
+		schema = append(schema, ruleSchema(fd.FieldType, rule)...)
+	}
+	return schema
+}
+
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) bson.D {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		nullable = true
+		t = t.Elem()
+	}
+
+	var schema bson.D
+	var bsonTypes []string
+	switch {
+	case t == timeType || t == dateTimeType:
+		bsonTypes = []string{BsonTypeDate}
+	case t == objectIDType:
+		bsonTypes = []string{BsonTypeObjectID}
+	case t == decimal128Type:
+		bsonTypes = []string{BsonTypeDecimal}
+	case t == bytesType:
+		bsonTypes = []string{BsonTypeBinData}
+	default:
+		switch t.Kind() {
+		case reflect.String:
+			bsonTypes = []string{BsonTypeString}
+		case reflect.Bool:
+			bsonTypes = []string{BsonTypeBool}
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+			bsonTypes = []string{BsonTypeInt}
+		case reflect.Int64:
+			bsonTypes = []string{BsonTypeLong}
+		case reflect.Int, reflect.Uint, reflect.Uint32, reflect.Uint64:
+			// the driver stores them as int or long depending on the value
+			bsonTypes = []string{BsonTypeInt, BsonTypeLong}
+		case reflect.Float32, reflect.Float64:
+			bsonTypes = []string{BsonTypeDouble}
+		case reflect.Slice, reflect.Array:
+			// nil slices are stored as null
+			nullable = nullable || t.Kind() == reflect.Slice
+			bsonTypes = []string{BsonTypeArray}
+			if items := typeSchema(t.Elem(), visiting); len(items) > 0 {
+				schema = append(schema, bson.E{Key: "items", Value: items})
+			}
+		case reflect.Map:
+			nullable = true
+			bsonTypes = []string{BsonTypeObject}
+		case reflect.Struct:
+			object := objectSchema(t, visiting)
+			bsonTypes = []string{BsonTypeObject}
+			schema = append(schema, object[1:]...)
+		default:
+			// interfaces and unsupported kinds accept any type
+			return bson.D{}
+		}
+	}
+	if nullable {
+		bsonTypes = append(bsonTypes, BsonTypeNull)
+	}
+	var bsonType any = bsonTypes
+	if len(bsonTypes) == 1 {
+		bsonType = bsonTypes[0]
+	}
+	return append(bson.D{{Key: "bsonType", Value: bsonType}}, schema...)
+}
+
+// encryptedSchema accepts the ciphertext of an encrypted field, the rules on the plaintext can't be checked by the server.
+// The null values aren't encrypted.
+func encryptedSchema(t reflect.Type) bson.D {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return bson.D{{Key: "bsonType", Value: []string{BsonTypeBinData, BsonTypeNull}}}
+	}
+	return bson.D{{Key: "bsonType", Value: BsonTypeBinData}}
+}
+
+func ruleSchema(t reflect.Type, rule field.ValidateRule) bson.D {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		nullable = true
+		t = t.Elem()
+	}
+	switch rule.Name {
+	case "oneof":
+		options := strings.Fields(rule.Param)
+		enum := make(bson.A, 0, len(options))
+		for _, option := range options {
+			value, ok := parseLiteral(t, option)
+			if !ok {
+				return nil
+			}
+			enum = append(enum, value)
+		}
+		if nullable {
+			enum = append(enum, nil)
+		}
+		return bson.D{{Key: "enum", Value: enum}}
+	case "email":
+		return bson.D{{Key: "pattern", Value: `^[^\s@]+@[^\s@]+\.[^\s@]+$`}}
+	case "min", "max", "len":
+		return boundSchema(t, rule)
+	}
+	return nil
+}
+
+func boundSchema(t reflect.Type, rule field.ValidateRule) bson.D {
+	var keywords []string
+	switch t.Kind() {
+	case reflect.String:
+		keywords = []string{"minLength", "maxLength"}
+	case reflect.Slice, reflect.Array:
+		keywords = []string{"minItems", "maxItems"}
+	case reflect.Map:
+		keywords = []string{"minProperties", "maxProperties"}
+	default:
+		if rule.Name == "len" {
+			return nil
+		}
+		value, ok := parseLiteral(t, rule.Param)
+		if !ok {
+			return nil
+		}
+		if rule.Name == "min" {
+			return bson.D{{Key: "minimum", Value: value}}
+		}
+		return bson.D{{Key: "maximum", Value: value}}
+	}
+	n, err := strconv.ParseInt(rule.Param, 10, 64)
+	if err != nil {
+		return nil
+	}
+	switch rule.Name {
+	case "min":
+		return bson.D{{Key: keywords[0], Value: n}}
+	case "max":
+		return bson.D{{Key: keywords[1], Value: n}}
+	default:
+		return bson.D{{Key: keywords[0], Value: n}, {Key: keywords[1], Value: n}}
+	}
+}
+
+func parseLiteral(t reflect.Type, literal string) (any, bool) {
+	switch t.Kind() {
+	case reflect.String:
+		return literal, true
+	case reflect.Bool:
+		b, err := strconv.ParseBool(literal)
+		return b, err == nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseInt(literal, 10, 64)
+		return i, err == nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(literal, 64)
+		return f, err == nil
+	default:
+		return nil, false
+	}
+}
+
+func hasRule(rules []field.ValidateRule, name string) bool {
+	for _, rule := range rules {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
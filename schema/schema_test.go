@@ -0,0 +1,120 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Model struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" mongox:"autoID"`
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+type Address struct {
+	City string `bson:"city" validate:"required"`
+}
+
+type Node struct {
+	Name     string  `bson:"name"`
+	Children []*Node `bson:"children"`
+}
+
+type User struct {
+	Model    `bson:",inline"`
+	Name     string            `bson:"name" validate:"required,min=2,max=8"`
+	Email    *string           `bson:"email" validate:"email"`
+	Age      int               `bson:"age" validate:"min=18"`
+	Level    int32             `bson:"level" validate:"oneof=1 2 3"`
+	Status   *string           `bson:"status" validate:"oneof=active banned"`
+	Score    float64           `bson:"score"`
+	Balance  int64             `bson:"balance"`
+	Enabled  bool              `bson:"enabled"`
+	Tags     []string          `bson:"tags" validate:"max=3"`
+	Address  Address           `bson:"address"`
+	Previous *Address          `bson:"previous"`
+	Labels   map[string]string `bson:"labels"`
+	Avatar   []byte            `bson:"avatar"`
+	Extra    any               `bson:"extra"`
+	Node     Node              `bson:"node"`
+	Ignored  string            `bson:"-"`
+	internal string
+}
+
+func TestFromType(t *testing.T) {
+	address := bson.D{
+		{Key: "bsonType", Value: "object"},
+		{Key: "properties", Value: bson.D{{Key: "city", Value: bson.D{{Key: "bsonType", Value: "string"}}}}},
+		{Key: "required", Value: []string{"city"}},
+	}
+	want := bson.D{
+		{Key: "bsonType", Value: "object"},
+		{Key: "properties", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "bsonType", Value: "objectId"}}},
+			{Key: "created_at", Value: bson.D{{Key: "bsonType", Value: "date"}}},
+			{Key: "name", Value: bson.D{{Key: "bsonType", Value: "string"}, {Key: "minLength", Value: int64(2)}, {Key: "maxLength", Value: int64(8)}}},
+			{Key: "email", Value: bson.D{{Key: "bsonType", Value: []string{"string", "null"}}, {Key: "pattern", Value: `^[^\s@]+@[^\s@]+\.[^\s@]+$`}}},
+			{Key: "age", Value: bson.D{{Key: "bsonType", Value: []string{"int", "long"}}, {Key: "minimum", Value: int64(18)}}},
+			{Key: "level", Value: bson.D{{Key: "bsonType", Value: "int"}, {Key: "enum", Value: bson.A{int64(1), int64(2), int64(3)}}}},
+			{Key: "status", Value: bson.D{{Key: "bsonType", Value: []string{"string", "null"}}, {Key: "enum", Value: bson.A{"active", "banned", nil}}}},
+			{Key: "score", Value: bson.D{{Key: "bsonType", Value: "double"}}},
+			{Key: "balance", Value: bson.D{{Key: "bsonType", Value: "long"}}},
+			{Key: "enabled", Value: bson.D{{Key: "bsonType", Value: "bool"}}},
+			{Key: "tags", Value: bson.D{{Key: "bsonType", Value: []string{"array", "null"}}, {Key: "items", Value: bson.D{{Key: "bsonType", Value: "string"}}}, {Key: "maxItems", Value: int64(3)}}},
+			{Key: "address", Value: address},
+			{Key: "previous", Value: append(bson.D{{Key: "bsonType", Value: []string{"object", "null"}}}, address[1:]...)},
+			{Key: "labels", Value: bson.D{{Key: "bsonType", Value: []string{"object", "null"}}}},
+			{Key: "avatar", Value: bson.D{{Key: "bsonType", Value: "binData"}}},
+			{Key: "extra", Value: bson.D{}},
+			{Key: "node", Value: bson.D{
+				{Key: "bsonType", Value: "object"},
+				{Key: "properties", Value: bson.D{
+					{Key: "name", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+					// recursive types stop at the first repetition
+					{Key: "children", Value: bson.D{{Key: "bsonType", Value: []string{"array", "null"}}, {Key: "items", Value: bson.D{{Key: "bsonType", Value: []string{"object", "null"}}}}}},
+				}},
+			}},
+		}},
+		{Key: "required", Value: []string{"name"}},
+	}
+
+	assert.Equal(t, want, FromType[User]())
+	assert.Equal(t, want, FromType[*User]())
+	assert.Equal(t, bson.D{{Key: "bsonType", Value: "object"}}, FromType[int]())
+}
+
+type Secret struct {
+	ID    bson.ObjectID `bson:"_id,omitempty"`
+	SSN   string        `bson:"ssn" mongox:"encrypt" validate:"required,len=11"`
+	Email *string       `bson:"email" mongox:"encrypt:deterministic" validate:"email"`
+	Age   int           `bson:"age" mongox:"encrypt"`
+}
+
+func TestFromType_Encrypted(t *testing.T) {
+	assert.Equal(t, bson.D{
+		{Key: "bsonType", Value: "object"},
+		{Key: "properties", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "bsonType", Value: "objectId"}}},
+			{Key: "ssn", Value: bson.D{{Key: "bsonType", Value: "binData"}}},
+			{Key: "email", Value: bson.D{{Key: "bsonType", Value: []string{"binData", "null"}}}},
+			{Key: "age", Value: bson.D{{Key: "bsonType", Value: "binData"}}},
+		}},
+		{Key: "required", Value: []string{"ssn"}},
+	}, FromType[Secret]())
+}
@@ -0,0 +1,308 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const idField = "_id"
+
+// DefaultCapacity is the capacity of the tracker shared by a collection in tracking mode
+const DefaultCapacity = 10000
+
+// Option configures a Tracker
+type Option func(*config)
+
+type config struct {
+	capacity int
+}
+
+// WithCapacity bounds the number of snapshots, the least recently tracked ones are dropped beyond capacity.
+// A zero capacity means no bound.
+func WithCapacity(capacity int) Option {
+	return func(c *config) {
+		c.capacity = capacity
+	}
+}
+
+// Tracker keeps a snapshot of the loaded documents, keyed by their _id, to compute the changes made to them.
+// It is safe for concurrent use, but the requests tracking the same documents concurrently replace each other's
+// snapshots: a tracker should be scoped to a unit of work, see NewContext.
+type Tracker[T any] struct {
+	fields []*field.Filed
+	config
+
+	mu sync.RWMutex
+	// snapshots index the elements of lru, whose values are the *snapshot[T], the most recently tracked first
+	snapshots map[string]*list.Element
+	lru       *list.List
+}
+
+type snapshot[T any] struct {
+	key string
+	doc *T
+}
+
+// New creates a Tracker for the documents of type T described by fields
+func New[T any](fields []*field.Filed, opts ...Option) *Tracker[T] {
+	t := &Tracker[T]{fields: fields, snapshots: make(map[string]*list.Element), lru: list.New()}
+	for _, opt := range opts {
+		opt(&t.config)
+	}
+	return t
+}
+
+type contextKey[T any] struct{}
+
+// NewContext returns a copy of ctx carrying t, which replaces the tracker of the collection of T
+// for the finders and Save called with the context, e.g. a tracker per request
+func NewContext[T any](ctx context.Context, t *Tracker[T]) context.Context {
+	return context.WithValue(ctx, contextKey[T]{}, t)
+}
+
+// FromContext returns the tracker of T carried by ctx, nil if there is none
+func FromContext[T any](ctx context.Context) *Tracker[T] {
+	t, _ := ctx.Value(contextKey[T]{}).(*Tracker[T])
+	return t
+}
+
+// Track takes a snapshot of docs, the documents without _id are ignored
+func (t *Tracker[T]) Track(docs ...*T) error {
+	for _, doc := range docs {
+		key, ok, err := t.key(doc)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		copied, err := clone(doc)
+		if err != nil {
+			return err
+		}
+		t.mu.Lock()
+		t.store(key, copied)
+		t.mu.Unlock()
+	}
+	return nil
+}
+
+func (t *Tracker[T]) store(key string, doc *T) {
+	if elem, ok := t.snapshots[key]; ok {
+		elem.Value.(*snapshot[T]).doc = doc
+		t.lru.MoveToFront(elem)
+		return
+	}
+	t.snapshots[key] = t.lru.PushFront(&snapshot[T]{key: key, doc: doc})
+	if t.capacity > 0 && t.lru.Len() > t.capacity {
+		oldest := t.lru.Back()
+		t.lru.Remove(oldest)
+		delete(t.snapshots, oldest.Value.(*snapshot[T]).key)
+	}
+}
+
+// Untrack drops the snapshots of docs
+func (t *Tracker[T]) Untrack(docs ...*T) {
+	for _, doc := range docs {
+		if key, ok, _ := t.key(doc); ok {
+			t.mu.Lock()
+			if elem, ok := t.snapshots[key]; ok {
+				t.lru.Remove(elem)
+				delete(t.snapshots, key)
+			}
+			t.mu.Unlock()
+		}
+	}
+}
+
+// Clear drops all the snapshots
+func (t *Tracker[T]) Clear() {
+	t.mu.Lock()
+	t.snapshots = make(map[string]*list.Element)
+	t.lru.Init()
+	t.mu.Unlock()
+}
+
+// Len returns the number of snapshots
+func (t *Tracker[T]) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.lru.Len()
+}
+
+// Tracked reports whether doc has a snapshot
+func (t *Tracker[T]) Tracked(doc *T) bool {
+	key, ok, _ := t.key(doc)
+	if !ok {
+		return false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, ok = t.snapshots[key]
+	return ok
+}
+
+// ID returns the _id of doc, ok is false if doc has no _id field or its _id is zero
+func (t *Tracker[T]) ID(doc *T) (id any, ok bool) {
+	value, ok := utils.IDValue(reflect.ValueOf(doc), t.fields)
+	if !ok || value.IsZero() {
+		return nil, false
+	}
+	return value.Interface(), true
+}
+
+// SetID assigns id to the _id field of doc when it is zero, e.g. with the id generated by the driver on insert
+func (t *Tracker[T]) SetID(doc *T, id any) {
+	utils.SetZeroID(reflect.ValueOf(doc), t.fields, id)
+}
+
+// Changes compares doc with its snapshot and returns the $set and $unset that apply the changes,
+// the result is empty if nothing changed. tracked is false if doc has no snapshot.
+//
+// Nested structs are compared field by field and assigned by dot paths, nil pointers are removed by $unset.
+// The _id, autoID, autoCreateTime and autoUpdateTime fields are not compared, the latter being set by the update hooks.
+func (t *Tracker[T]) Changes(doc *T) (updates bson.M, tracked bool, err error) {
+	key, ok, err := t.key(doc)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	t.mu.RLock()
+	elem, ok := t.snapshots[key]
+	var old *T
+	if ok {
+		old = elem.Value.(*snapshot[T]).doc
+	}
+	t.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	d := &differ{set: bson.M{}, unset: bson.M{}}
+	if err = d.diff(reflect.ValueOf(old).Elem(), reflect.ValueOf(doc).Elem(), t.fields, ""); err != nil {
+		return nil, true, err
+	}
+	updates = bson.M{}
+	if len(d.set) > 0 {
+		updates["$set"] = d.set
+	}
+	if len(d.unset) > 0 {
+		updates["$unset"] = d.unset
+	}
+	return updates, true, nil
+}
+
+func (t *Tracker[T]) key(doc *T) (string, bool, error) {
+	id, ok := t.ID(doc)
+	if !ok {
+		return "", false, nil
+	}
+	key, err := utils.BsonKey(id)
+	if err != nil {
+		return "", false, err
+	}
+	return key, true, nil
+}
+
+// clone deep copies doc through its bson encoding, which is all that is persisted
+func clone[T any](doc *T) (*T, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	copied := new(T)
+	if err = bson.Unmarshal(data, copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+type differ struct {
+	set   bson.M
+	unset bson.M
+}
+
+func (d *differ) diff(old, cur reflect.Value, fields []*field.Filed, prefix string) error {
+	for idx, fd := range fields {
+		if idx >= cur.NumField() || !cur.Type().Field(idx).IsExported() {
+			continue
+		}
+		oldValue, curValue := old.Field(idx), cur.Field(idx)
+		if fd.InlinedFields != nil {
+			if err := d.diff(oldValue, curValue, fd.InlinedFields, prefix); err != nil {
+				return err
+			}
+			continue
+		}
+		if fd.MongoField == "-" || fd.MongoField == idField || fd.AutoID || fd.AutoCreateTime != 0 || fd.AutoUpdateTime != 0 {
+			continue
+		}
+		path := fd.MongoField
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		if curValue.Kind() == reflect.Ptr {
+			switch {
+			case curValue.IsNil() && oldValue.IsNil():
+				continue
+			case curValue.IsNil():
+				d.unset[path] = ""
+				continue
+			case oldValue.IsNil():
+				// the embedded document is replaced as a whole since its fields can't be set on null
+				d.set[path] = curValue.Elem().Interface()
+				continue
+			}
+			oldValue, curValue = oldValue.Elem(), curValue.Elem()
+		}
+		if utils.IsDocument(curValue.Type()) {
+			if err := d.diff(oldValue, curValue, field.ParseFields(reflect.New(curValue.Type()).Interface()), path); err != nil {
+				return err
+			}
+			continue
+		}
+		equal, err := equalBSON(oldValue.Interface(), curValue.Interface())
+		if err != nil {
+			return err
+		}
+		if !equal {
+			d.set[path] = curValue.Interface()
+		}
+	}
+	return nil
+}
+
+// equalBSON reports whether a and b are stored as the same bson value
+func equalBSON(a, b any) (bool, error) {
+	typeA, dataA, err := bson.MarshalValue(a)
+	if err != nil {
+		return false, err
+	}
+	typeB, dataB, err := bson.MarshalValue(b)
+	if err != nil {
+		return false, err
+	}
+	return typeA == typeB && bytes.Equal(dataA, dataB), nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Base struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	CreatedAt time.Time     `bson:"created_at"`
+	UpdatedAt time.Time     `bson:"updated_at"`
+}
+
+type address struct {
+	City    string `bson:"city"`
+	ZipCode string `bson:"zip_code"`
+}
+
+type user struct {
+	Base     `bson:",inline"`
+	Name     string   `bson:"name"`
+	Age      int      `bson:"age"`
+	Nickname *string  `bson:"nickname"`
+	Tags     []string `bson:"tags"`
+	Address  address  `bson:"address"`
+	Company  *address `bson:"company"`
+}
+
+func newUser(id bson.ObjectID) *user {
+	nickname := "chen"
+	return &user{
+		Base:     Base{ID: id, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		Name:     "chenmingyong",
+		Age:      24,
+		Nickname: &nickname,
+		Tags:     []string{"go"},
+		Address:  address{City: "shenzhen", ZipCode: "518000"},
+	}
+}
+
+func TestTracker_Changes(t *testing.T) {
+	id := bson.NewObjectID()
+	other := "mingyong"
+
+	testCases := []struct {
+		name   string
+		modify func(u *user)
+
+		wantTracked bool
+		want        bson.M
+	}{
+		{
+			name:        "unchanged",
+			modify:      func(u *user) {},
+			wantTracked: true,
+			want:        bson.M{},
+		},
+		{
+			name: "ignored fields",
+			modify: func(u *user) {
+				u.CreatedAt = time.Now().Add(time.Hour)
+				u.UpdatedAt = time.Now().Add(time.Hour)
+			},
+			wantTracked: true,
+			want:        bson.M{},
+		},
+		{
+			name: "changed fields",
+			modify: func(u *user) {
+				u.Name = "Mingyong Chen"
+				u.Nickname = &other
+				u.Tags = append(u.Tags, "mongo")
+			},
+			wantTracked: true,
+			want: bson.M{"$set": bson.M{
+				"name":     "Mingyong Chen",
+				"nickname": "mingyong",
+				"tags":     []string{"go", "mongo"},
+			}},
+		},
+		{
+			name: "nested documents",
+			modify: func(u *user) {
+				u.Address.City = "guangzhou"
+				u.Company = &address{City: "shenzhen"}
+			},
+			wantTracked: true,
+			want: bson.M{"$set": bson.M{
+				"address.city": "guangzhou",
+				"company":      address{City: "shenzhen"},
+			}},
+		},
+		{
+			name: "nil pointer",
+			modify: func(u *user) {
+				u.Nickname = nil
+				u.Age = 0
+			},
+			wantTracked: true,
+			want: bson.M{
+				"$set":   bson.M{"age": 0},
+				"$unset": bson.M{"nickname": ""},
+			},
+		},
+		{
+			name: "untracked document",
+			modify: func(u *user) {
+				u.ID = bson.NewObjectID()
+			},
+		},
+		{
+			name: "document without id",
+			modify: func(u *user) {
+				u.ID = bson.ObjectID{}
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker := New[user](field.ParseFields(user{}))
+			loaded := newUser(id)
+			require.NoError(t, tracker.Track(loaded))
+
+			tc.modify(loaded)
+			got, tracked, err := tracker.Changes(loaded)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantTracked, tracked)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTracker_Track(t *testing.T) {
+	tracker := New[user](field.ParseFields(user{}))
+	u1, u2, noID := newUser(bson.NewObjectID()), newUser(bson.NewObjectID()), newUser(bson.ObjectID{})
+
+	require.NoError(t, tracker.Track(u1, u2, noID))
+	assert.True(t, tracker.Tracked(u1))
+	assert.True(t, tracker.Tracked(u2))
+	assert.False(t, tracker.Tracked(noID))
+
+	// the snapshot is not shared with the document
+	u1.Tags[0] = "mongo"
+	updates, _, err := tracker.Changes(u1)
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"$set": bson.M{"tags": []string{"mongo"}}}, updates)
+
+	tracker.Untrack(u1)
+	assert.False(t, tracker.Tracked(u1))
+	assert.True(t, tracker.Tracked(u2))
+
+	tracker.Clear()
+	assert.False(t, tracker.Tracked(u2))
+}
+
+func TestTracker_Capacity(t *testing.T) {
+	tracker := New[user](field.ParseFields(user{}), WithCapacity(2))
+	u1, u2, u3 := newUser(bson.NewObjectID()), newUser(bson.NewObjectID()), newUser(bson.NewObjectID())
+
+	require.NoError(t, tracker.Track(u1, u2))
+	// tracking u1 again makes u2 the least recently tracked
+	require.NoError(t, tracker.Track(u1, u3))
+	assert.Equal(t, 2, tracker.Len())
+	assert.True(t, tracker.Tracked(u1))
+	assert.False(t, tracker.Tracked(u2))
+	assert.True(t, tracker.Tracked(u3))
+
+	tracker.Untrack(u1)
+	assert.Equal(t, 1, tracker.Len())
+	tracker.Clear()
+	assert.Equal(t, 0, tracker.Len())
+}
+
+func TestTracker_Context(t *testing.T) {
+	assert.Nil(t, FromContext[user](context.Background()))
+
+	tracker := New[user](field.ParseFields(user{}))
+	ctx := NewContext(context.Background(), tracker)
+	assert.Same(t, tracker, FromContext[user](ctx))
+	// the trackers are bound to their type
+	assert.Nil(t, FromContext[any](ctx))
+}
+
+func TestTracker_ID(t *testing.T) {
+	tracker := New[user](field.ParseFields(user{}))
+	u := newUser(bson.ObjectID{})
+
+	_, ok := tracker.ID(u)
+	assert.False(t, ok)
+
+	id := bson.NewObjectID()
+	tracker.SetID(u, "not an object id")
+	_, ok = tracker.ID(u)
+	assert.False(t, ok)
+
+	tracker.SetID(u, id)
+	got, ok := tracker.ID(u)
+	assert.True(t, ok)
+	assert.Equal(t, id, got)
+
+	// a non-zero id is never overwritten
+	tracker.SetID(u, bson.NewObjectID())
+	assert.Equal(t, id, u.ID)
+}
@@ -18,18 +18,26 @@ import (
 	"context"
 	"time"
 
+	"github.com/chenmingyong0423/go-mongox/v2/collation"
+	"github.com/chenmingyong0423/go-mongox/v2/encryption"
 	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/guard"
 
 	"github.com/chenmingyong0423/go-mongox/v2/callback"
 
+	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/driveropt"
 	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
 
 	"github.com/chenmingyong0423/go-mongox/v2/bsonx"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/builder/update"
 
 	"github.com/chenmingyong0423/go-mongox/v2/operation"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 )
 
 //go:generate mockgen -source=updater.go -destination=../mock/updater.mock.go -package=mocks
@@ -37,12 +45,26 @@ type IUpdater[T any] interface {
 	UpdateOne(ctx context.Context, opts ...options.Lister[options.UpdateOneOptions]) (*mongo.UpdateResult, error)
 	UpdateMany(ctx context.Context, opts ...options.Lister[options.UpdateManyOptions]) (*mongo.UpdateResult, error)
 	Upsert(ctx context.Context, opts ...options.Lister[options.UpdateOneOptions]) (*mongo.UpdateResult, error)
+	ReplaceOne(ctx context.Context, opts ...options.Lister[options.ReplaceOptions]) (*mongo.UpdateResult, error)
 	Filter(filter any) IUpdater[T]
+	Where(conds ...any) IUpdater[T]
+	OrWhere(conds ...any) IUpdater[T]
+	Not(conds ...any) IUpdater[T]
+	Scopes(scopes ...query.Scope) IUpdater[T]
+	Unscoped() IUpdater[T]
+	AllowFullCollection() IUpdater[T]
 	ModelHook(modelHook any) IUpdater[T]
 	RegisterAfterHooks(hooks ...AfterHookFn) IUpdater[T]
 	RegisterBeforeHooks(hooks ...BeforeHookFn) IUpdater[T]
 	Replacement(replacement any) IUpdater[T]
 	Updates(updates any) IUpdater[T]
+	SetStruct(v any, opts ...*update.StructOptions) IUpdater[T]
+	Collation(collation *collation.Collation) IUpdater[T]
+	Hint(hint any) IUpdater[T]
+	MaxTime(maxTime time.Duration) IUpdater[T]
+	Comment(comment any) IUpdater[T]
+	Let(let any) IUpdater[T]
+	WriteConcern(wc *writeconcern.WriteConcern) IUpdater[T]
 	PostActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *OpContext, opType operation.OpType) error
 	PreActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *OpContext, opType operation.OpType) error
 	GetCollection() *mongo.Collection
@@ -57,47 +79,192 @@ var _ IUpdater[any] = (*Updater[any])(nil)
 type Updater[T any] struct {
 	collection *mongo.Collection
 	fields     []*field.Filed
-
-	filter      any
-	updates     any
-	replacement any
-	modelHook   any
+	cipher     *encryption.Cipher
+
+	filter        any
+	updates       any
+	replacement   any
+	modelHook     any
+	collation     *collation.Collation
+	defaultScopes []query.Scope
+	unscoped      bool
+	// allowFullCollection disables the ErrEmptyFilter guard
+	allowFullCollection bool
+	driverOpts          driveropt.Options
 
 	DBCallbacks *callback.Callback
 	BeforeHooks []BeforeHookFn
 	AfterHooks  []AfterHookFn
 }
 
+// Clone returns a copy of u. The fluent methods already return copies and the terminal methods don't modify u,
+// so an updater can be declared once, e.g. at package level, and shared by goroutines.
+func (u *Updater[T]) Clone() *Updater[T] {
+	c := *u
+	c.BeforeHooks = append([]BeforeHookFn(nil), u.BeforeHooks...)
+	c.AfterHooks = append([]AfterHookFn(nil), u.AfterHooks...)
+	return &c
+}
+
 // Filter is used to set the filter of the query
 func (u *Updater[T]) Filter(filter any) IUpdater[T] {
-	u.filter = filter
-	return u
+	c := u.Clone()
+	c.filter = filter
+	return c
+}
+
+// Where is used to add conditions to the filter, they are merged with the previous ones by query.Merge.
+// A condition can be a bson.D, a bson.M, a builder such as query.NewBuilder() or an example *T, see query.FromExample
+func (u *Updater[T]) Where(conds ...any) IUpdater[T] {
+	c := u.Clone()
+	c.filter = query.Merge(append([]any{c.filter}, conds...)...)
+	return c
+}
+
+// OrWhere is used to set the filter to the previous filter or the conjunction of conds, see Where
+func (u *Updater[T]) OrWhere(conds ...any) IUpdater[T] {
+	c := u.Clone()
+	c.filter = query.MergeOr(c.filter, query.Merge(conds...))
+	return c
+}
+
+// Not is used to add the negation of the conjunction of conds to the filter, with $nor, see Where
+func (u *Updater[T]) Not(conds ...any) IUpdater[T] {
+	c := u.Clone()
+	if cond := query.Merge(conds...); len(cond) != 0 {
+		c.filter = query.Merge(c.filter, query.Nor(cond))
+	}
+	return c
+}
+
+// Scopes is used to add the conditions of scopes to the filter, see Where
+func (u *Updater[T]) Scopes(scopes ...query.Scope) IUpdater[T] {
+	c := u.Clone()
+	c.filter = query.Merge(c.filter, query.ApplyScopes(scopes...))
+	return c
+}
+
+// DefaultScopes is used to set the scopes added to the filter of the update methods unless Unscoped is called
+func (u *Updater[T]) DefaultScopes(scopes ...query.Scope) *Updater[T] {
+	c := u.Clone()
+	c.defaultScopes = scopes
+	return c
+}
+
+// Unscoped is used to skip the default scopes
+func (u *Updater[T]) Unscoped() IUpdater[T] {
+	c := u.Clone()
+	c.unscoped = true
+	return c
 }
 
 // Updates is used to set the updates of the update
 func (u *Updater[T]) Updates(updates any) IUpdater[T] {
-	u.updates = updates
-	return u
+	c := u.Clone()
+	c.updates = updates
+	return c
+}
+
+// SetStruct is used to set the updates to the $set (and $unset) built from the fields of v, see update.FromStruct
+func (u *Updater[T]) SetStruct(v any, opts ...*update.StructOptions) IUpdater[T] {
+	c := u.Clone()
+	var opt *update.StructOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	c.updates = update.FromStruct(v, opt)
+	return c
+}
+
+// AllowFullCollection is used to allow the update methods to be called with an empty filter, which matches all the documents.
+// Otherwise they return guard.ErrEmptyFilter, the default scopes don't count as a filter
+func (u *Updater[T]) AllowFullCollection() IUpdater[T] {
+	c := u.Clone()
+	c.allowFullCollection = true
+	return c
+}
+
+// GuardEmptyFilter is used to enable or disable the guard returning guard.ErrEmptyFilter, enabled by default
+func (u *Updater[T]) GuardEmptyFilter(enabled bool) *Updater[T] {
+	c := u.Clone()
+	c.allowFullCollection = !enabled
+	return c
+}
+
+// Collation is used to set the collation of the update methods
+func (u *Updater[T]) Collation(collation *collation.Collation) IUpdater[T] {
+	c := u.Clone()
+	c.collation = collation
+	return c
+}
+
+// Hint is used to set the index of the update methods, an index name or a specification such as sort.Asc("name")
+func (u *Updater[T]) Hint(hint any) IUpdater[T] {
+	c := u.Clone()
+	if b, ok := hint.(interface{ Build() bson.D }); ok {
+		hint = b.Build()
+	}
+	c.driverOpts.Hint = hint
+	return c
+}
+
+// MaxTime is used to bound the duration of the update methods by a context timeout
+func (u *Updater[T]) MaxTime(maxTime time.Duration) IUpdater[T] {
+	c := u.Clone()
+	c.driverOpts.MaxTime = maxTime
+	return c
+}
+
+// Comment is used to set the comment of the update methods
+func (u *Updater[T]) Comment(comment any) IUpdater[T] {
+	c := u.Clone()
+	c.driverOpts.Comment = comment
+	return c
+}
+
+// Let is used to set the variables of the update methods, which can be used as $$var in the filter with $expr
+func (u *Updater[T]) Let(let any) IUpdater[T] {
+	c := u.Clone()
+	c.driverOpts.Let = let
+	return c
+}
+
+// WriteConcern is used to set the write concern of the update methods
+func (u *Updater[T]) WriteConcern(wc *writeconcern.WriteConcern) IUpdater[T] {
+	c := u.Clone()
+	c.driverOpts.SetWriteConcern(wc)
+	return c
 }
 
 func (u *Updater[T]) Replacement(replacement any) IUpdater[T] {
-	u.replacement = replacement
-	return u
+	c := u.Clone()
+	c.replacement = replacement
+	return c
+}
+
+// Cipher is used to encrypt the values that $set and $setOnInsert assign to the fields tagged with `mongox:"encrypt"`
+func (u *Updater[T]) Cipher(cipher *encryption.Cipher) *Updater[T] {
+	c := u.Clone()
+	c.cipher = cipher
+	return c
 }
 
 func (u *Updater[T]) ModelHook(modelHook any) IUpdater[T] {
-	u.modelHook = modelHook
-	return u
+	c := u.Clone()
+	c.modelHook = modelHook
+	return c
 }
 
 func (u *Updater[T]) RegisterBeforeHooks(hooks ...BeforeHookFn) IUpdater[T] {
-	u.BeforeHooks = append(u.BeforeHooks, hooks...)
-	return u
+	c := u.Clone()
+	c.BeforeHooks = append(c.BeforeHooks, hooks...)
+	return c
 }
 
 func (u *Updater[T]) RegisterAfterHooks(hooks ...AfterHookFn) IUpdater[T] {
-	u.AfterHooks = append(u.AfterHooks, hooks...)
-	return u
+	c := u.Clone()
+	c.AfterHooks = append(c.AfterHooks, hooks...)
+	return c
 }
 
 func (u *Updater[T]) PreActionHandler(ctx context.Context, globalOpContext *operation.OpContext, opContext *OpContext, opType operation.OpType) error {
@@ -105,6 +272,8 @@ func (u *Updater[T]) PreActionHandler(ctx context.Context, globalOpContext *oper
 	if err != nil {
 		return err
 	}
+	// the filter replaced by a callback is the one used by the hooks and the update
+	opContext.Filter = globalOpContext.Filter
 	for _, beforeHook := range u.BeforeHooks {
 		err = beforeHook(ctx, opContext)
 		if err != nil {
@@ -129,22 +298,38 @@ func (u *Updater[T]) PostActionHandler(ctx context.Context, globalOpContext *ope
 }
 
 func (u *Updater[T]) UpdateOne(ctx context.Context, opts ...options.Lister[options.UpdateOneOptions]) (*mongo.UpdateResult, error) {
-
+	if !u.allowFullCollection && guard.IsEmptyFilter(u.filter) {
+		return nil, guard.ErrEmptyFilter
+	}
 	currentTime := time.Now()
+	filter := u.scopedFilter()
+	if u.collation != nil {
+		opts = append(opts, options.UpdateOne().SetCollation(u.collation.Options()))
+	}
+	opts = u.driverOpts.UpdateOne(opts)
+	ctx, cancel := u.driverOpts.Context(ctx)
+	defer cancel()
 
-	updates := bsonx.ToBsonM(u.updates)
-	if len(updates) != 0 {
-		u.updates = updates
+	updates := u.updates
+	if m := bsonx.ToBsonM(u.updates); len(m) != 0 {
+		updates = utils.CopyUpdates(m)
 	}
 
-	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(u.filter), operation.WithUpdates(u.updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook), operation.WithFields(u.fields), operation.WithStartTime(currentTime))
-	opContext := NewOpContext(u.collection, u.filter, u.updates, WithMongoOptions(opts), WithModelHook(u.modelHook), WithFields(u.fields), WithStartTime(currentTime))
+	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(filter), operation.WithUpdates(updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook), operation.WithFields(u.fields), operation.WithStartTime(currentTime))
+	opContext := NewOpContext(u.collection, filter, updates, WithMongoOptions(opts), WithModelHook(u.modelHook), WithFields(u.fields), WithStartTime(currentTime))
 	err := u.PreActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeUpdate)
 	if err != nil {
 		return nil, err
 	}
+	filter = opContext.Filter
+
+	if u.cipher != nil {
+		if updates, err = u.cipher.EncryptUpdates(ctx, updates, u.fields); err != nil {
+			return nil, err
+		}
+	}
 
-	result, err := u.collection.UpdateOne(ctx, u.filter, u.updates, opts...)
+	result, err := u.driverOpts.Collection(u.collection).UpdateOne(ctx, filter, updates, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -159,22 +344,39 @@ func (u *Updater[T]) UpdateOne(ctx context.Context, opts ...options.Lister[optio
 }
 
 func (u *Updater[T]) UpdateMany(ctx context.Context, opts ...options.Lister[options.UpdateManyOptions]) (*mongo.UpdateResult, error) {
+	if !u.allowFullCollection && guard.IsEmptyFilter(u.filter) {
+		return nil, guard.ErrEmptyFilter
+	}
 	currentTime := time.Now()
+	filter := u.scopedFilter()
+	if u.collation != nil {
+		opts = append(opts, options.UpdateMany().SetCollation(u.collation.Options()))
+	}
+	opts = u.driverOpts.UpdateMany(opts)
+	ctx, cancel := u.driverOpts.Context(ctx)
+	defer cancel()
 
-	updates := bsonx.ToBsonM(u.updates)
-	if len(updates) != 0 {
-		u.updates = updates
+	updates := u.updates
+	if m := bsonx.ToBsonM(u.updates); len(m) != 0 {
+		updates = utils.CopyUpdates(m)
 	}
 
-	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(u.filter), operation.WithUpdates(u.updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook), operation.WithFields(u.fields), operation.WithStartTime(currentTime))
-	opContext := NewOpContext(u.collection, u.filter, u.updates, WithMongoOptions(opts), WithModelHook(u.modelHook), WithFields(u.fields), WithStartTime(currentTime))
+	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(filter), operation.WithUpdates(updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook), operation.WithFields(u.fields), operation.WithStartTime(currentTime))
+	opContext := NewOpContext(u.collection, filter, updates, WithMongoOptions(opts), WithModelHook(u.modelHook), WithFields(u.fields), WithStartTime(currentTime))
 
 	err := u.PreActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeUpdate)
 	if err != nil {
 		return nil, err
 	}
+	filter = opContext.Filter
+
+	if u.cipher != nil {
+		if updates, err = u.cipher.EncryptUpdates(ctx, updates, u.fields); err != nil {
+			return nil, err
+		}
+	}
 
-	result, err := u.collection.UpdateMany(ctx, u.filter, u.updates, opts...)
+	result, err := u.driverOpts.Collection(u.collection).UpdateMany(ctx, filter, updates, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -189,32 +391,40 @@ func (u *Updater[T]) UpdateMany(ctx context.Context, opts ...options.Lister[opti
 }
 
 func (u *Updater[T]) Upsert(ctx context.Context, opts ...options.Lister[options.UpdateOneOptions]) (*mongo.UpdateResult, error) {
+	if !u.allowFullCollection && guard.IsEmptyFilter(u.filter) {
+		return nil, guard.ErrEmptyFilter
+	}
 	currentTime := time.Now()
+	filter := u.scopedFilter()
 
-	if len(opts) == 0 {
-		opts = append(opts, options.UpdateOne().SetUpsert(true))
-	} else {
-		if uob, ok := opts[0].(*options.UpdateOneOptionsBuilder); ok {
-			uob.Opts = append(uob.Opts, func(o *options.UpdateOneOptions) error {
-				o.Upsert = utils.ToPtr(true)
-				return nil
-			})
-		}
+	opts = append(opts, options.UpdateOne().SetUpsert(true))
+	if u.collation != nil {
+		opts = append(opts, options.UpdateOne().SetCollation(u.collation.Options()))
 	}
+	opts = u.driverOpts.UpdateOne(opts)
+	ctx, cancel := u.driverOpts.Context(ctx)
+	defer cancel()
 
-	updates := bsonx.ToBsonM(u.updates)
-	if len(updates) != 0 {
-		u.updates = updates
+	updates := u.updates
+	if m := bsonx.ToBsonM(u.updates); len(m) != 0 {
+		updates = utils.CopyUpdates(m)
 	}
 
-	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(u.filter), operation.WithUpdates(u.updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook), operation.WithStartTime(currentTime), operation.WithFields(u.fields))
-	opContext := NewOpContext(u.collection, u.filter, u.updates, WithMongoOptions(opts), WithModelHook(u.modelHook), WithStartTime(currentTime), WithFields(u.fields))
+	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(filter), operation.WithUpdates(updates), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook), operation.WithStartTime(currentTime), operation.WithFields(u.fields))
+	opContext := NewOpContext(u.collection, filter, updates, WithMongoOptions(opts), WithModelHook(u.modelHook), WithStartTime(currentTime), WithFields(u.fields))
 	err := u.PreActionHandler(ctx, globalOpContext, opContext, operation.OpTypeBeforeUpsert)
 	if err != nil {
 		return nil, err
 	}
+	filter = opContext.Filter
 
-	result, err := u.collection.UpdateOne(ctx, u.filter, u.updates, opts...)
+	if u.cipher != nil {
+		if updates, err = u.cipher.EncryptUpdates(ctx, updates, u.fields); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := u.driverOpts.Collection(u.collection).UpdateOne(ctx, filter, updates, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -227,6 +437,76 @@ func (u *Updater[T]) Upsert(ctx context.Context, opts ...options.Lister[options.
 	}
 	return result, nil
 }
+
+// ReplaceOne replaces the document matched by the filter with the replacement, which is inserted when nothing matches
+// if the options enable upsert. The hooks of the upserts run in that case, the ones of the updates otherwise,
+// a replacement struct gets the values of its autoID, autoCreateTime and default fields when they are zero,
+// and the current time in its autoUpdateTime fields.
+func (u *Updater[T]) ReplaceOne(ctx context.Context, opts ...options.Lister[options.ReplaceOptions]) (*mongo.UpdateResult, error) {
+	if !u.allowFullCollection && guard.IsEmptyFilter(u.filter) {
+		return nil, guard.ErrEmptyFilter
+	}
+	currentTime := time.Now()
+	filter := u.scopedFilter()
+	if u.collation != nil {
+		opts = append(opts, options.Replace().SetCollation(u.collation.Options()))
+	}
+	opts = u.driverOpts.ReplaceOne(opts)
+	ctx, cancel := u.driverOpts.Context(ctx)
+	defer cancel()
+
+	beforeOpType, afterOpType := operation.OpTypeBeforeUpdate, operation.OpTypeAfterUpdate
+	if isUpsert(opts) {
+		beforeOpType, afterOpType = operation.OpTypeBeforeUpsert, operation.OpTypeAfterUpsert
+	}
+	replacement := u.replacement
+
+	globalOpContext := operation.NewOpContext(u.collection, operation.WithDoc(new(T)), operation.WithFilter(filter), operation.WithUpdates(replacement), operation.WithMongoOptions(opts), operation.WithModelHook(u.modelHook), operation.WithStartTime(currentTime), operation.WithFields(u.fields))
+	opContext := NewOpContext(u.collection, filter, nil, WithReplacement(replacement), WithMongoOptions(opts), WithModelHook(u.modelHook), WithStartTime(currentTime), WithFields(u.fields))
+	err := u.PreActionHandler(ctx, globalOpContext, opContext, beforeOpType)
+	if err != nil {
+		return nil, err
+	}
+	filter = opContext.Filter
+
+	if u.cipher != nil {
+		if replacement, err = u.cipher.EncryptDocument(ctx, replacement, u.fields); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := u.driverOpts.Collection(u.collection).ReplaceOne(ctx, filter, replacement, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	globalOpContext.Result = result
+	opContext.Result = result
+	err = u.PostActionHandler(ctx, globalOpContext, opContext, afterOpType)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func isUpsert(opts []options.Lister[options.ReplaceOptions]) bool {
+	var replaceOptions options.ReplaceOptions
+	for _, opt := range opts {
+		for _, set := range opt.List() {
+			_ = set(&replaceOptions)
+		}
+	}
+	return replaceOptions.Upsert != nil && *replaceOptions.Upsert
+}
+
+// scopedFilter returns the filter with the conditions of the default scopes
+func (u *Updater[T]) scopedFilter() any {
+	if u.unscoped || len(u.defaultScopes) == 0 {
+		return u.filter
+	}
+	return query.Merge(u.filter, query.ApplyScopes(u.defaultScopes...))
+}
+
 func (u *Updater[T]) GetCollection() *mongo.Collection {
 	return u.collection
 }
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/guard"
 
 	"github.com/chenmingyong0423/go-mongox/v2/internal/pkg/utils"
 
@@ -136,7 +137,7 @@ func TestUpdater_e2e_UpdateOne(t *testing.T) {
 			updates: bson.D{},
 			want:    nil,
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.Error(t, err)
+				return assert.ErrorIs(t, err, guard.ErrEmptyFilter)
 			},
 		},
 		{
@@ -156,7 +157,7 @@ func TestUpdater_e2e_UpdateOne(t *testing.T) {
 			before:  func(ctx context.Context, t *testing.T) {},
 			after:   func(ctx context.Context, t *testing.T) {},
 			ctx:     context.Background(),
-			filter:  bson.D{},
+			filter:  query.Id("1"),
 			updates: nil,
 			want:    nil,
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
@@ -168,7 +169,7 @@ func TestUpdater_e2e_UpdateOne(t *testing.T) {
 			before:  func(ctx context.Context, t *testing.T) {},
 			after:   func(ctx context.Context, t *testing.T) {},
 			ctx:     context.Background(),
-			filter:  bson.D{},
+			filter:  query.Id("1"),
 			updates: 6,
 			want:    nil,
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
@@ -180,7 +181,7 @@ func TestUpdater_e2e_UpdateOne(t *testing.T) {
 			before:  func(ctx context.Context, t *testing.T) {},
 			after:   func(ctx context.Context, t *testing.T) {},
 			ctx:     context.Background(),
-			filter:  bson.D{},
+			filter:  query.Id("1"),
 			updates: User{Id: "1", Name: "Mingyong Chen", Age: 18},
 			want:    nil,
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
@@ -192,7 +193,7 @@ func TestUpdater_e2e_UpdateOne(t *testing.T) {
 			before:  func(ctx context.Context, t *testing.T) {},
 			after:   func(ctx context.Context, t *testing.T) {},
 			ctx:     context.Background(),
-			filter:  bson.D{},
+			filter:  query.Id("1"),
 			updates: map[string]any{"Id": "1", "Name": "Mingyong Chen", "Age": 18},
 			want:    nil,
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
@@ -1010,7 +1011,9 @@ func TestUpdater_e2e_Upsert(t *testing.T) {
 			filter:  nil,
 			updates: bson.D{},
 			want:    nil,
-			wantErr: require.Error,
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, guard.ErrEmptyFilter)
+			},
 		},
 		{
 			name:    "invalid filter",
@@ -1027,7 +1030,7 @@ func TestUpdater_e2e_Upsert(t *testing.T) {
 			before:  func(ctx context.Context, t *testing.T) {},
 			after:   func(ctx context.Context, t *testing.T) {},
 			ctx:     context.Background(),
-			filter:  bson.D{},
+			filter:  query.Id("1"),
 			updates: nil,
 			want:    nil,
 			wantErr: require.Error,
@@ -1037,7 +1040,7 @@ func TestUpdater_e2e_Upsert(t *testing.T) {
 			before:  func(ctx context.Context, t *testing.T) {},
 			after:   func(ctx context.Context, t *testing.T) {},
 			ctx:     context.Background(),
-			filter:  bson.D{},
+			filter:  query.Id("1"),
 			updates: 6,
 			want:    nil,
 			wantErr: require.Error,
@@ -1400,3 +1403,32 @@ func TestUpdater_e2e_Upsert(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdater_e2e_SetStruct(t *testing.T) {
+	collection := getCollection(t)
+	updater := xupdater.NewUpdater[TestUser](collection, callback.InitializeCallbacks(), field.ParseFields(TestUser{}))
+
+	ctx := context.Background()
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	insertOneResult, err := collection.InsertOne(ctx, TestUser{Name: "Mingyong Chen", Age: 18, CreatedAt: createdAt, UpdatedAt: createdAt})
+	require.NoError(t, err)
+	defer func() {
+		deleteResult, err := collection.DeleteOne(ctx, query.Id(insertOneResult.InsertedID))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleteResult.DeletedCount)
+	}()
+
+	result, err := updater.Filter(query.Id(insertOneResult.InsertedID)).
+		SetStruct(&TestUser{ID: bson.NewObjectID(), Name: "chenmingyong"}, &update.StructOptions{OmitZero: true}).
+		UpdateOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.ModifiedCount)
+
+	user := &TestUser{}
+	require.NoError(t, collection.FindOne(ctx, query.Id(insertOneResult.InsertedID)).Decode(user))
+	assert.Equal(t, insertOneResult.InsertedID, user.ID)
+	assert.Equal(t, "chenmingyong", user.Name)
+	assert.Equal(t, int64(18), user.Age)
+	assert.Equal(t, createdAt.UnixMilli(), user.CreatedAt.UnixMilli())
+	assert.True(t, user.UpdatedAt.After(createdAt))
+}
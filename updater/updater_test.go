@@ -16,12 +16,23 @@ package updater_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/chenmingyong0423/go-mongox/v2/builder/query"
+	"github.com/chenmingyong0423/go-mongox/v2/callback"
+	"github.com/chenmingyong0423/go-mongox/v2/collation"
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+	"github.com/chenmingyong0423/go-mongox/v2/guard"
 	mocks "github.com/chenmingyong0423/go-mongox/v2/mock"
+	"github.com/chenmingyong0423/go-mongox/v2/operation"
 	"github.com/chenmingyong0423/go-mongox/v2/updater"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.uber.org/mock/gomock"
 )
 
@@ -229,3 +240,130 @@ func TestUpdater_Upsert(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdater_Collation(t *testing.T) {
+	errStop := errors.New("stop")
+	var updateOptions options.UpdateOneOptions
+	u := updater.NewUpdater[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).Filter(query.Id(1)).Collation(collation.CaseInsensitive("en")).RegisterBeforeHooks(func(ctx context.Context, opContext *updater.OpContext, opts ...any) error {
+		for _, lister := range opContext.MongoOptions.([]options.Lister[options.UpdateOneOptions]) {
+			for _, set := range lister.List() {
+				assert.NoError(t, set(&updateOptions))
+			}
+		}
+		return errStop
+	})
+
+	_, err := u.Upsert(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, &options.Collation{Locale: "en", Strength: 2}, updateOptions.Collation)
+	assert.True(t, *updateOptions.Upsert)
+}
+
+func TestUpdater_Where(t *testing.T) {
+	errStop := errors.New("stop")
+	var filter any
+	u := updater.NewUpdater[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).Filter(query.Eq("status", 1)).Where(query.Eq("status", 2)).Not(bson.M{"locked": true}).
+		RegisterBeforeHooks(func(ctx context.Context, opContext *updater.OpContext, opts ...any) error {
+			filter = opContext.Filter
+			return errStop
+		})
+
+	_, err := u.UpdateMany(context.Background())
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, query.And(query.Eq("status", 1), query.Eq("status", 2), query.Nor(bson.D{{Key: "locked", Value: true}})), filter)
+}
+
+func TestUpdater_EmptyFilter(t *testing.T) {
+	errStop := errors.New("stop")
+	testCases := []struct {
+		name    string
+		updater updater.IUpdater[any]
+		wantErr error
+	}{
+		{
+			name:    "nil filter",
+			updater: updater.NewUpdater[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil),
+			wantErr: guard.ErrEmptyFilter,
+		},
+		{
+			name:    "empty filter",
+			updater: updater.NewUpdater[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).Filter(bson.D{}),
+			wantErr: guard.ErrEmptyFilter,
+		},
+		{
+			name:    "allow full collection",
+			updater: updater.NewUpdater[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).AllowFullCollection(),
+			wantErr: errStop,
+		},
+		{
+			name:    "filter",
+			updater: updater.NewUpdater[any](&mongo.Collection{}, callback.InitializeCallbacks(), nil).Filter(query.Eq("status", 1)),
+			wantErr: errStop,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := tc.updater.Updates(bson.M{"$set": bson.M{"status": 2}}).RegisterBeforeHooks(func(ctx context.Context, opContext *updater.OpContext, opts ...any) error {
+				return errStop
+			})
+			_, err := u.UpdateOne(context.Background())
+			assert.ErrorIs(t, err, tc.wantErr)
+			_, err = u.UpdateMany(context.Background())
+			assert.ErrorIs(t, err, tc.wantErr)
+			_, err = u.Upsert(context.Background())
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestUpdater_ReplaceOne(t *testing.T) {
+	type user struct {
+		ID        string    `bson:"_id"`
+		Name      string    `bson:"name"`
+		Role      string    `bson:"role" mongox:"default:user"`
+		CreatedAt time.Time `bson:"created_at"`
+		UpdatedAt time.Time `bson:"updated_at"`
+	}
+	errStop := errors.New("stop")
+	testCases := []struct {
+		name       string
+		opts       []options.Lister[options.ReplaceOptions]
+		wantOpType operation.OpType
+	}{
+		{name: "replace", wantOpType: operation.OpTypeBeforeUpdate},
+		{name: "upsert", opts: []options.Lister[options.ReplaceOptions]{options.Replace().SetUpsert(true)}, wantOpType: operation.OpTypeBeforeUpsert},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var opTypes []operation.OpType
+			callbacks := callback.InitializeCallbacks()
+			for _, opType := range []operation.OpType{operation.OpTypeBeforeUpdate, operation.OpTypeBeforeUpsert} {
+				opType := opType
+				callbacks.Register(opType, "record", func(ctx context.Context, opCtx *operation.OpContext, opts ...any) error {
+					opTypes = append(opTypes, opType)
+					return nil
+				})
+			}
+			updatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			replacement := &user{ID: "1", Name: "chenmingyong", UpdatedAt: updatedAt}
+			var got *updater.OpContext
+			_, err := updater.NewUpdater[user](&mongo.Collection{}, callbacks, field.ParseFields(user{})).Filter(query.Id("1")).Replacement(replacement).
+				RegisterBeforeHooks(func(ctx context.Context, opContext *updater.OpContext, opts ...any) error {
+					got = opContext
+					return errStop
+				}).ReplaceOne(context.Background(), tc.opts...)
+			require.ErrorIs(t, err, errStop)
+
+			assert.Equal(t, []operation.OpType{tc.wantOpType}, opTypes)
+			assert.Equal(t, query.Id("1"), got.Filter)
+			assert.Same(t, replacement, got.Replacement)
+			// the replacement is completed by the field hooks
+			assert.Equal(t, "user", replacement.Role)
+			assert.False(t, replacement.CreatedAt.IsZero())
+			assert.True(t, replacement.UpdatedAt.After(updatedAt))
+		})
+	}
+
+	_, err := updater.NewUpdater[user](&mongo.Collection{}, callback.InitializeCallbacks(), nil).Replacement(&user{}).ReplaceOne(context.Background())
+	assert.ErrorIs(t, err, guard.ErrEmptyFilter)
+}
@@ -0,0 +1,376 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Supported rules of the validate tag
+const (
+	RuleOmitEmpty = "omitempty"
+	RuleRequired  = "required"
+	RuleMin       = "min"
+	RuleMax       = "max"
+	RuleLen       = "len"
+	RuleEmail     = "email"
+	RuleOneOf     = "oneof"
+	// RuleType is reported when a value in the update document does not match the type of the field
+	RuleType = "type"
+)
+
+var (
+	emailRegexp = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// Violation describes a value that does not satisfy a rule
+type Violation struct {
+	// Field is the mongo field name, prefixed with the index of the document for InsertMany, e.g. "[1].name"
+	Field string
+	Rule  string
+	Param string
+	Value any
+}
+
+func (v *Violation) Error() string {
+	if v.Param != "" {
+		return fmt.Sprintf("%s: %s=%s", v.Field, v.Rule, v.Param)
+	}
+	return fmt.Sprintf("%s: %s", v.Field, v.Rule)
+}
+
+// ValidationError lists all the violations found in a document or an update
+type ValidationError struct {
+	Violations []*Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Error())
+	}
+	return "mongox: validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Validate validates doc, which must be a struct or a pointer to a struct, against the validate tags of fields.
+// It returns a *ValidationError when at least one rule is violated.
+func Validate(doc any, fields []*field.Filed) error {
+	v, ok := doc.(reflect.Value)
+	if !ok {
+		v = reflect.ValueOf(doc)
+	}
+	violations := validateStruct(v, fields, "")
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// ValidateMany validates every document of docs, which must be a slice of structs or pointers to structs.
+// The violations of all documents are reported in a single *ValidationError.
+func ValidateMany(docs any, fields []*field.Filed) error {
+	v, ok := docs.(reflect.Value)
+	if !ok {
+		v = reflect.ValueOf(docs)
+	}
+	if v.Kind() != reflect.Slice {
+		return nil
+	}
+	var violations []*Violation
+	for i := 0; i < v.Len(); i++ {
+		violations = append(violations, validateStruct(v.Index(i), fields, fmt.Sprintf("[%d].", i))...)
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// ValidateUpdates validates the values assigned by $set and $setOnInsert against the type and the validate tags
+// of the target fields. The dotted keys are resolved through the nested structs and the elements of the slices,
+// e.g. address.city or addresses.0.city. The keys without field are not checked.
+func ValidateUpdates(updates any, fields []*field.Filed) error {
+	m, ok := updates.(bson.M)
+	if !ok || m == nil || len(fields) == 0 {
+		return nil
+	}
+	var violations []*Violation
+	for _, op := range []string{"$set", "$setOnInsert"} {
+		switch values := m[op].(type) {
+		case bson.M:
+			for key, value := range values {
+				violations = append(violations, validateUpdateValue(fields, key, value)...)
+			}
+		case bson.D:
+			for _, e := range values {
+				violations = append(violations, validateUpdateValue(fields, e.Key, e.Value)...)
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// HasRules reports whether any of the fields has a validate tag
+func HasRules(fields []*field.Filed) bool {
+	for _, fd := range fields {
+		if len(fd.ValidateRules) > 0 || HasRules(fd.InlinedFields) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateStruct(v reflect.Value, fields []*field.Filed, prefix string) []*Violation {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var violations []*Violation
+	for idx, fd := range fields {
+		if idx >= v.NumField() {
+			break
+		}
+		value := v.Field(idx)
+		if fd.InlinedFields != nil {
+			violations = append(violations, validateStruct(value, fd.InlinedFields, prefix)...)
+			continue
+		}
+		violations = append(violations, validateValue(value, fd, prefix)...)
+	}
+	return violations
+}
+
+func validateUpdateValue(fields []*field.Filed, key string, value any) []*Violation {
+	fd := lookupPath(fields, strings.Split(key, "."))
+	if fd == nil {
+		return nil
+	}
+	if !isAssignable(value, fd.FieldType) {
+		return []*Violation{{Field: key, Rule: RuleType, Param: fd.FieldType.String(), Value: value}}
+	}
+	if value == nil || len(fd.ValidateRules) == 0 {
+		if value == nil && hasRule(fd.ValidateRules, RuleRequired) {
+			return []*Violation{{Field: key, Rule: RuleRequired}}
+		}
+		return nil
+	}
+	return validateValue(reflect.ValueOf(value), fd, key[:len(key)-len(fd.MongoField)])
+}
+
+// lookupPath returns the field targeted by path, the dotted key split on the dots, nil if there is none.
+// The element of a slice, e.g. tags.0 or tags.$, is returned as a field of the element type without rules.
+func lookupPath(fields []*field.Filed, path []string) *field.Filed {
+	fd, ok := flattenFields(fields)[path[0]]
+	if !ok || len(path) == 1 {
+		return fd
+	}
+	path = path[1:]
+	t := indirectType(fd.FieldType)
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		if len(path) == 1 {
+			return &field.Filed{Name: fd.Name, MongoField: path[0], FieldType: t.Elem()}
+		}
+		path = path[1:]
+		t = indirectType(t.Elem())
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return nil
+	}
+	return lookupPath(field.ParseFields(reflect.New(t).Interface()), path)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func validateValue(value reflect.Value, fd *field.Filed, prefix string) []*Violation {
+	var violations []*Violation
+	indirect := value
+	for indirect.Kind() == reflect.Ptr || indirect.Kind() == reflect.Interface {
+		if indirect.IsNil() {
+			break
+		}
+		indirect = indirect.Elem()
+	}
+	empty := !value.IsValid() || value.IsZero()
+	for _, rule := range fd.ValidateRules {
+		if rule.Name == RuleOmitEmpty {
+			if empty {
+				return nil
+			}
+			continue
+		}
+		if rule.Name == RuleRequired {
+			if empty {
+				return append(violations, newViolation(prefix, fd, rule, value))
+			}
+			continue
+		}
+		if !indirect.IsValid() || (indirect.Kind() == reflect.Ptr && indirect.IsNil()) {
+			continue
+		}
+		if !checkRule(indirect, rule) {
+			violations = append(violations, newViolation(prefix, fd, rule, value))
+		}
+	}
+	return violations
+}
+
+func checkRule(v reflect.Value, rule field.ValidateRule) bool {
+	switch rule.Name {
+	case RuleMin, RuleMax, RuleLen:
+		param, err := strconv.ParseFloat(rule.Param, 64)
+		if err != nil {
+			return false
+		}
+		n, ok := measure(v)
+		if !ok {
+			return false
+		}
+		switch rule.Name {
+		case RuleMin:
+			return n >= param
+		case RuleMax:
+			return n <= param
+		default:
+			return n == param
+		}
+	case RuleEmail:
+		return v.Kind() == reflect.String && emailRegexp.MatchString(v.String())
+	case RuleOneOf:
+		s := fmt.Sprint(v.Interface())
+		for _, option := range strings.Fields(rule.Param) {
+			if s == option {
+				return true
+			}
+		}
+		return false
+	}
+	// unknown rules are ignored
+	return true
+}
+
+// measure returns the number which min, max and len compare: the value of numbers and the length of the others
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return float64(len([]rune(v.String()))), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+func isAssignable(value any, fieldType reflect.Type) bool {
+	if fieldType == nil {
+		return true
+	}
+	if value == nil {
+		switch fieldType.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			return true
+		default:
+			return false
+		}
+	}
+	valueType := reflect.TypeOf(value)
+	if fieldType.Kind() == reflect.Ptr && valueType.Kind() != reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if valueType.AssignableTo(fieldType) || fieldType.Kind() == reflect.Interface {
+		return true
+	}
+	if isNumber(valueType.Kind()) && isNumber(fieldType.Kind()) {
+		return true
+	}
+	switch value.(type) {
+	case bson.M, bson.D, map[string]any:
+		// embedded documents are checked by the server
+		return fieldType.Kind() == reflect.Struct || fieldType.Kind() == reflect.Map
+	case bson.A, []any:
+		return fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array
+	}
+	return valueType.Kind() == fieldType.Kind() && valueType.ConvertibleTo(fieldType)
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func hasRule(rules []field.ValidateRule, name string) bool {
+	for _, rule := range rules {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func flattenFields(fields []*field.Filed) map[string]*field.Filed {
+	result := make(map[string]*field.Filed, len(fields))
+	for _, fd := range fields {
+		if fd.InlinedFields != nil {
+			for k, v := range flattenFields(fd.InlinedFields) {
+				result[k] = v
+			}
+			continue
+		}
+		result[fd.MongoField] = fd
+	}
+	return result
+}
+
+func newViolation(prefix string, fd *field.Filed, rule field.ValidateRule, value reflect.Value) *Violation {
+	violation := &Violation{Field: prefix + fd.MongoField, Rule: rule.Name, Param: rule.Param}
+	if value.IsValid() && value.CanInterface() {
+		violation.Value = value.Interface()
+	}
+	return violation
+}
@@ -0,0 +1,203 @@
+// Copyright 2025 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenmingyong0423/go-mongox/v2/field"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Base struct {
+	Tenant string `bson:"tenant" validate:"required"`
+}
+
+type user struct {
+	Base     `bson:",inline"`
+	Name     string   `bson:"name" validate:"required,min=2,max=8"`
+	Email    string   `bson:"email" validate:"omitempty,email"`
+	Age      int      `bson:"age" validate:"min=18,max=120"`
+	Status   string   `bson:"status" validate:"oneof=active banned"`
+	Tags     []string `bson:"tags" validate:"max=2"`
+	Code     *string  `bson:"code" validate:"len=4"`
+	Nickname string   `bson:"nickname"`
+}
+
+func validUser() *user {
+	code := "1234"
+	return &user{Base: Base{Tenant: "mongox"}, Name: "chen", Email: "chen@mongox.com", Age: 24, Status: "active", Tags: []string{"a"}, Code: &code}
+}
+
+func violationsOf(t *testing.T, err error) []*Violation {
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr), "expected a *ValidationError, got %v", err)
+	return validationErr.Violations
+}
+
+func TestValidate(t *testing.T) {
+	fields := field.ParseFields(&user{})
+
+	testCases := []struct {
+		name   string
+		doc    func() *user
+		wanted []*Violation
+	}{
+		{
+			name: "valid",
+			doc:  validUser,
+		},
+		{
+			name: "omitempty and nil pointer",
+			doc: func() *user {
+				u := validUser()
+				u.Email = ""
+				u.Code = nil
+				return u
+			},
+		},
+		{
+			name: "all violations are reported",
+			doc: func() *user {
+				code := "12"
+				return &user{Name: "c", Email: "chen", Age: 17, Status: "deleted", Tags: []string{"a", "b", "c"}, Code: &code}
+			},
+			wanted: []*Violation{
+				{Field: "tenant", Rule: RuleRequired, Value: ""},
+				{Field: "name", Rule: RuleMin, Param: "2", Value: "c"},
+				{Field: "email", Rule: RuleEmail, Value: "chen"},
+				{Field: "age", Rule: RuleMin, Param: "18", Value: 17},
+				{Field: "status", Rule: RuleOneOf, Param: "active banned", Value: "deleted"},
+				{Field: "tags", Rule: RuleMax, Param: "2", Value: []string{"a", "b", "c"}},
+				{Field: "code", Rule: RuleLen, Param: "4", Value: func() *string { s := "12"; return &s }()},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.doc(), fields)
+			if tc.wanted == nil {
+				require.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tc.wanted, violationsOf(t, err))
+		})
+	}
+}
+
+func TestValidateMany(t *testing.T) {
+	fields := field.ParseFields(&user{})
+	invalid := validUser()
+	invalid.Name = ""
+
+	require.NoError(t, ValidateMany([]*user{validUser(), validUser()}, fields))
+	err := ValidateMany([]*user{validUser(), invalid}, fields)
+	assert.Equal(t, []*Violation{{Field: "[1].name", Rule: RuleRequired, Value: ""}}, violationsOf(t, err))
+	assert.EqualError(t, err, "mongox: validation failed: [1].name: required")
+}
+
+func TestValidateUpdates(t *testing.T) {
+	fields := field.ParseFields(&user{})
+
+	testCases := []struct {
+		name    string
+		updates any
+		wanted  []*Violation
+	}{
+		{
+			name:    "not bson.M",
+			updates: bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: ""}}}},
+		},
+		{
+			name: "valid",
+			updates: bson.M{
+				"$set":         bson.M{"name": "chen", "age": int64(20), "code": "abcd", "address.city": 1, "unknown": 1},
+				"$setOnInsert": bson.D{{Key: "tenant", Value: "mongox"}},
+				"$inc":         bson.M{"age": "ignored"},
+			},
+		},
+		{
+			name: "rule violations",
+			updates: bson.M{
+				"$set": bson.M{"name": "", "age": 200},
+			},
+			wanted: []*Violation{
+				{Field: "age", Rule: RuleMax, Param: "120", Value: 200},
+				{Field: "name", Rule: RuleRequired, Value: ""},
+			},
+		},
+		{
+			name: "type violations",
+			updates: bson.M{
+				"$setOnInsert": bson.D{{Key: "nickname", Value: 1}, {Key: "age", Value: "18"}, {Key: "tags", Value: nil}, {Key: "tenant", Value: nil}},
+			},
+			wanted: []*Violation{
+				{Field: "nickname", Rule: RuleType, Param: "string", Value: 1},
+				{Field: "age", Rule: RuleType, Param: "int", Value: "18"},
+				{Field: "tenant", Rule: RuleType, Param: "string"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateUpdates(tc.updates, fields)
+			if tc.wanted == nil {
+				require.NoError(t, err)
+				return
+			}
+			assert.ElementsMatch(t, tc.wanted, violationsOf(t, err))
+		})
+	}
+}
+
+func TestValidateUpdates_DottedKeys(t *testing.T) {
+	type address struct {
+		City string `bson:"city" validate:"required"`
+		Zip  int    `bson:"zip"`
+	}
+	type customer struct {
+		Address   *address  `bson:"address"`
+		Addresses []address `bson:"addresses"`
+		Tags      []string  `bson:"tags"`
+	}
+	fields := field.ParseFields(&customer{})
+
+	err := ValidateUpdates(bson.M{"$set": bson.M{"address.city": "paris", "addresses.0.zip": 75000, "tags.1": "a", "address.unknown": 1}}, fields)
+	require.NoError(t, err)
+
+	err = ValidateUpdates(bson.M{
+		"$set":         bson.M{"address.city": "", "addresses.$.zip": "75000"},
+		"$setOnInsert": bson.D{{Key: "tags.0", Value: 1}},
+	}, fields)
+	assert.ElementsMatch(t, []*Violation{
+		{Field: "address.city", Rule: RuleRequired, Value: ""},
+		{Field: "addresses.$.zip", Rule: RuleType, Param: "int", Value: "75000"},
+		{Field: "tags.0", Rule: RuleType, Param: "string", Value: 1},
+	}, violationsOf(t, err))
+}
+
+func TestHasRules(t *testing.T) {
+	assert.True(t, HasRules(field.ParseFields(&user{})))
+	assert.True(t, HasRules(field.ParseFields(&struct {
+		Base `bson:",inline"`
+	}{})))
+	assert.False(t, HasRules(field.ParseFields(&struct {
+		Name string `bson:"name"`
+	}{})))
+}